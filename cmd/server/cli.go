@@ -122,7 +122,8 @@ func handleUserCommand() {
 	}
 }
 
-// runUserCommand executes the user-related subcommands (add, remove, list).
+// runUserCommand executes the user-related subcommands (add, remove,
+// identity, list).
 func runUserCommand(args []string, baseURL string, out io.Writer) error {
 	if len(args) < 3 {
 		printUserHelp(out)
@@ -138,7 +139,12 @@ func runUserCommand(args []string, baseURL string, out io.Writer) error {
 		}
 		username := args[3]
 		password := args[4]
-		reqBody, _ := json.Marshal(api.AddUserRequest{Username: username, Password: password})
+		addReq := api.AddUserRequest{Username: username, Password: password}
+		if len(args) >= 7 {
+			addReq.Name = args[5]
+			addReq.Email = args[6]
+		}
+		reqBody, _ := json.Marshal(addReq)
 		resp, err := http.Post(baseURL+"/users", "application/json", bytes.NewBuffer(reqBody))
 		return processResponse(resp, err, out)
 	case "remove":
@@ -155,6 +161,21 @@ func runUserCommand(args []string, baseURL string, out io.Writer) error {
 		req, _ := http.NewRequest("DELETE", baseURL+"/users/"+encodedID, nil)
 		resp, err := http.DefaultClient.Do(req)
 		return processResponse(resp, err, out)
+	case "identity":
+		if len(args) < 6 {
+			printUserHelp(out)
+			return nil
+		}
+		id := args[3]
+		if id == "" || id == "." || id == ".." || strings.Contains(id, "/") {
+			return fmt.Errorf("invalid user ID: %q\nUse 'gitwapp user list' to see user IDs", id)
+		}
+		name, email := args[4], args[5]
+		reqBody, _ := json.Marshal(api.UpdateUserRequest{Name: &name, Email: &email})
+		req, _ := http.NewRequest("PATCH", baseURL+"/users/"+url.PathEscape(id), bytes.NewBuffer(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := http.DefaultClient.Do(req)
+		return processResponse(resp, err, out)
 	case "passwd":
 		fmt.Fprintln(out, "passwd command not implemented yet")
 		return nil
@@ -178,7 +199,11 @@ func runUserCommand(args []string, baseURL string, out io.Writer) error {
 			fmt.Fprintln(out, "Use 'gitwapp user add <username> <password>' to create a user.")
 		} else {
 			for _, u := range users {
-				fmt.Fprintf(out, "%s\t%s\n", u.ID, u.Username)
+				if u.Name != "" || u.Email != "" {
+					fmt.Fprintf(out, "%s\t%s\t%s <%s>\n", u.ID, u.Username, u.Name, u.Email)
+				} else {
+					fmt.Fprintf(out, "%s\t%s\n", u.ID, u.Username)
+				}
 			}
 		}
 		return nil
@@ -230,8 +255,11 @@ func printUserHelp(out io.Writer) {
 	fmt.Fprintln(out, "Usage: gitwapp user <command> [args]")
 	fmt.Fprintln(out, "")
 	fmt.Fprintln(out, "Commands:")
-	fmt.Fprintln(out, "  add <username> <password>   Create a new user")
+	fmt.Fprintln(out, "  add <username> <password> [<name> <email>]")
+	fmt.Fprintln(out, "                              Create a new user, optionally with a commit identity")
 	fmt.Fprintln(out, "  remove <id>                 Delete a user")
+	fmt.Fprintln(out, "  identity <id> <name> <email>")
+	fmt.Fprintln(out, "                              Set the name and email used for a user's commits")
 	fmt.Fprintln(out, "  list                        List all users")
 	fmt.Fprintln(out, "  passwd <username>           Update user password (not implemented)")
 	fmt.Fprintln(out, "  help                        Show this help message")
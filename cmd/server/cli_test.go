@@ -8,6 +8,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/Gemini8532/gitwapp/internal/api"
 	"github.com/Gemini8532/gitwapp/pkg/models"
 )
 
@@ -90,6 +91,30 @@ func TestRunUserCommand_Add(t *testing.T) {
 	}
 }
 
+func TestRunUserCommand_Identity(t *testing.T) {
+	var got api.UpdateUserRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/users/u1" {
+			t.Errorf("Expected path /users/u1, got %s", r.URL.Path)
+		}
+		if r.Method != "PATCH" {
+			t.Errorf("Expected method PATCH, got %s", r.Method)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	var out bytes.Buffer
+	args := []string{"gitwapp", "user", "identity", "u1", "Ada Lovelace", "ada@example.com"}
+	if err := runUserCommand(args, ts.URL, &out); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got.Name == nil || *got.Name != "Ada Lovelace" || got.Email == nil || *got.Email != "ada@example.com" {
+		t.Errorf("Unexpected request: %+v", got)
+	}
+}
+
 func TestRunRepoCommand_Error(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
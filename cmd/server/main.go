@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"syscall"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/Gemini8532/gitwapp/internal/api"
 	"github.com/Gemini8532/gitwapp/internal/config"
+	"github.com/Gemini8532/gitwapp/internal/git"
 )
 
 // defaultPort is the default port for the server to listen on.
@@ -72,6 +74,8 @@ func initLogger() *slog.Logger {
 func runServer() {
 	serveCmd := flag.NewFlagSet("serve", flag.ExitOnError)
 	port := serveCmd.String("port", defaultPort, "Port to listen on")
+	sshKeys := serveCmd.String("ssh-keys", "", "SSH private key files to try, separated by \""+string(os.PathListSeparator)+"\" (default ~/.ssh/id_*)")
+	knownHosts := serveCmd.String("known-hosts", "", "known_hosts file for SSH host verification (default ~/.ssh/known_hosts)")
 
 	serveCmd.Parse(os.Args[2:])
 
@@ -79,6 +83,18 @@ func runServer() {
 	if envPort := os.Getenv("APP_PORT"); envPort != "" {
 		*port = envPort
 	}
+	if envKeys := os.Getenv("GITWAPP_SSH_KEYS"); envKeys != "" {
+		*sshKeys = envKeys
+	}
+	if envKnownHosts := os.Getenv("GITWAPP_KNOWN_HOSTS"); envKnownHosts != "" {
+		*knownHosts = envKnownHosts
+	}
+
+	sshConfig := git.SSHConfig{KnownHostsPath: *knownHosts}
+	if *sshKeys != "" {
+		sshConfig.KeyPaths = filepath.SplitList(*sshKeys)
+	}
+	git.ConfigureSSH(sshConfig)
 
 	store, err := config.NewStore()
 	if err != nil {
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// confirmationTTL is how long a confirmation token for a destructive
+// operation remains valid.
+const confirmationTTL = 2 * time.Minute
+
+// ConfirmationResponse is returned with status 428 when a destructive
+// operation is requested without a confirmation token. Repeating the
+// request with the token in its confirm field performs the operation.
+type ConfirmationResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Action    string    `json:"action"`
+	Files     []string  `json:"files"` // Files that will lose changes
+}
+
+// confirmation is an issued, not yet redeemed confirmation token.
+type confirmation struct {
+	key     string
+	expires time.Time
+}
+
+// confirmations holds the pending confirmation tokens of the server. A token
+// is bound to the repository, the operation with its parameters, and the
+// state of the changes it discards, so it cannot confirm anything else.
+type confirmations struct {
+	mu      sync.Mutex
+	pending map[string]confirmation
+}
+
+// issue creates a token for the given key.
+func (c *confirmations) issue(key string) (string, time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if c.pending == nil {
+		c.pending = make(map[string]confirmation)
+	}
+	for token, p := range c.pending {
+		if now.After(p.expires) {
+			delete(c.pending, token)
+		}
+	}
+
+	token := uuid.New().String()
+	expires := now.Add(confirmationTTL)
+	c.pending[token] = confirmation{key: key, expires: expires}
+	return token, expires
+}
+
+// redeem consumes a token, reporting whether it was issued for key and has
+// not expired.
+func (c *confirmations) redeem(token string, key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	p, ok := c.pending[token]
+	if !ok {
+		return false
+	}
+	delete(c.pending, token)
+	return p.key == key && time.Now().Before(p.expires)
+}
+
+// confirmationKey derives the key a token is bound to from its parts.
+func confirmationKey(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// confirmed reports whether a destructive operation may proceed. Without a
+// token it responds with status 428 and a new token for the operation;
+// with a token that does not match, e.g. because the changes have been
+// modified since, it responds with status 412.
+func (s *Server) confirmed(w http.ResponseWriter, r *http.Request, token string, action string, key string, files []string) bool {
+	ctx := r.Context()
+
+	if token == "" {
+		token, expires := s.confirms.issue(key)
+		slog.InfoContext(ctx, "Confirmation required", "action", action, "files", len(files))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusPreconditionRequired)
+		json.NewEncoder(w).Encode(ConfirmationResponse{Token: token, ExpiresAt: expires, Action: action, Files: files})
+		return false
+	}
+
+	if !s.confirms.redeem(token, key) {
+		slog.WarnContext(ctx, "Confirmation rejected - invalid or expired token", "action", action)
+		http.Error(w, "Invalid or expired confirmation token", http.StatusPreconditionFailed)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/Gemini8532/gitwapp/internal/git"
+	"github.com/gorilla/mux"
+)
+
+// handleGetBlame handles requests to blame a file at a revision. The file
+// query parameter is required; ref defaults to HEAD.
+func (s *Server) handleGetBlame(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+	ref := r.URL.Query().Get("ref")
+	file := r.URL.Query().Get("file")
+
+	if file == "" {
+		http.Error(w, "File parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	slog.InfoContext(ctx, "Getting blame", "id", id, "ref", ref, "file", file)
+
+	repo, err := s.getRepoByID(id)
+	if err != nil {
+		slog.WarnContext(ctx, "Get blame failed - repository not found", "id", id)
+		http.Error(w, "Repository not found", http.StatusNotFound)
+		return
+	}
+
+	blame, err := git.GetBlame(repo.Path, ref, file)
+	if err != nil {
+		slog.ErrorContext(ctx, "Get blame failed", "id", id, "ref", ref, "file", file, "path", repo.Path, "error", err)
+		http.Error(w, "Failed to get blame: "+err.Error(), gitErrorStatus(err))
+		return
+	}
+
+	slog.InfoContext(ctx, "Blame retrieved successfully", "id", id, "file", file, "hunks", len(blame.Hunks))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(blame)
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/Gemini8532/gitwapp/internal/git"
+	"github.com/Gemini8532/gitwapp/pkg/models"
+)
+
+func TestHandleGetBlame(t *testing.T) {
+	server, configDir := setupTestServer(t)
+	defer os.RemoveAll(configDir)
+
+	tmpDir, repoPath := setupRepoForTest(t)
+	defer os.RemoveAll(tmpDir)
+
+	repo := models.Repository{ID: "1", Name: "Test", Path: repoPath}
+	server.store.SaveRepositories([]models.Repository{repo})
+
+	writeAndCommit(t, repoPath, "file.txt", "a\nb\n", "Add file")
+	writeAndCommit(t, repoPath, "file.txt", "a\nb\nc\n", "Append c")
+
+	get := func(query string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/api/repos/1/blame"+query, nil)
+		addAuth(t, req)
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := get("?file=file.txt&ref=HEAD")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %v %s", rr.Code, rr.Body.String())
+	}
+	var blame git.Blame
+	if err := json.NewDecoder(rr.Body).Decode(&blame); err != nil {
+		t.Fatal(err)
+	}
+	if len(blame.Hunks) != 2 || blame.Hunks[0].Summary != "Add file" || blame.Hunks[1].Summary != "Append c" || blame.Hunks[1].StartLine != 3 {
+		t.Errorf("Unexpected blame: %+v", blame.Hunks)
+	}
+
+	if rr := get(""); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without file, got %v", rr.Code)
+	}
+	if rr := get("?file=missing.txt"); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a missing file, got %v", rr.Code)
+	}
+}
@@ -0,0 +1,184 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/Gemini8532/gitwapp/internal/git"
+	"github.com/gorilla/mux"
+)
+
+// CreateBranchRequest represents the request body for creating a branch.
+type CreateBranchRequest struct {
+	Name       string `json:"name"`
+	StartPoint string `json:"start_point"` // Optional, defaults to HEAD
+	Checkout   bool   `json:"checkout"`
+}
+
+// CheckoutBranchRequest represents the request body for checking out a branch.
+type CheckoutBranchRequest struct {
+	Name string `json:"name"`
+}
+
+// RenameBranchRequest represents the request body for renaming a branch.
+type RenameBranchRequest struct {
+	Name    string `json:"name"`
+	NewName string `json:"new_name"`
+}
+
+// handleListBranches handles requests to list the local branches of a repository.
+func (s *Server) handleListBranches(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	slog.InfoContext(ctx, "Listing branches", "id", id)
+
+	repo, err := s.getRepoByID(id)
+	if err != nil {
+		slog.WarnContext(ctx, "List branches failed - repository not found", "id", id)
+		http.Error(w, "Repository not found", http.StatusNotFound)
+		return
+	}
+
+	branches, err := git.ListBranches(repo.Path)
+	if err != nil {
+		slog.ErrorContext(ctx, "List branches failed", "id", id, "path", repo.Path, "error", err)
+		http.Error(w, "Failed to list branches: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	slog.InfoContext(ctx, "Branches listed successfully", "id", id, "count", len(branches))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(branches)
+}
+
+// handleCreateBranch handles requests to create a new branch.
+func (s *Server) handleCreateBranch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req CreateBranchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.ErrorContext(ctx, "Failed to decode create branch request", "id", id, "error", err)
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	slog.InfoContext(ctx, "Creating branch", "id", id, "name", req.Name, "start_point", req.StartPoint, "checkout", req.Checkout)
+
+	repo, err := s.getRepoByID(id)
+	if err != nil {
+		slog.WarnContext(ctx, "Create branch failed - repository not found", "id", id)
+		http.Error(w, "Repository not found", http.StatusNotFound)
+		return
+	}
+
+	if err := git.CreateBranch(repo.Path, req.Name, req.StartPoint, req.Checkout); err != nil {
+		slog.ErrorContext(ctx, "Create branch failed", "id", id, "name", req.Name, "path", repo.Path, "error", err)
+		http.Error(w, "Failed to create branch: "+err.Error(), gitErrorStatus(err))
+		return
+	}
+
+	slog.InfoContext(ctx, "Branch created successfully", "id", id, "name", req.Name)
+	w.WriteHeader(http.StatusCreated)
+}
+
+// handleCheckoutBranch handles requests to switch the working tree to another branch.
+func (s *Server) handleCheckoutBranch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req CheckoutBranchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.ErrorContext(ctx, "Failed to decode checkout request", "id", id, "error", err)
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	slog.InfoContext(ctx, "Checking out branch", "id", id, "name", req.Name)
+
+	repo, err := s.getRepoByID(id)
+	if err != nil {
+		slog.WarnContext(ctx, "Checkout failed - repository not found", "id", id)
+		http.Error(w, "Repository not found", http.StatusNotFound)
+		return
+	}
+
+	if err := git.CheckoutBranch(repo.Path, req.Name); err != nil {
+		slog.ErrorContext(ctx, "Checkout failed", "id", id, "name", req.Name, "path", repo.Path, "error", err)
+		http.Error(w, "Failed to checkout branch: "+err.Error(), gitErrorStatus(err))
+		return
+	}
+
+	slog.InfoContext(ctx, "Branch checked out successfully", "id", id, "name", req.Name)
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleRenameBranch handles requests to rename a branch.
+func (s *Server) handleRenameBranch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req RenameBranchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.ErrorContext(ctx, "Failed to decode rename branch request", "id", id, "error", err)
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	slog.InfoContext(ctx, "Renaming branch", "id", id, "name", req.Name, "new_name", req.NewName)
+
+	repo, err := s.getRepoByID(id)
+	if err != nil {
+		slog.WarnContext(ctx, "Rename branch failed - repository not found", "id", id)
+		http.Error(w, "Repository not found", http.StatusNotFound)
+		return
+	}
+
+	if err := git.RenameBranch(repo.Path, req.Name, req.NewName); err != nil {
+		slog.ErrorContext(ctx, "Rename branch failed", "id", id, "name", req.Name, "new_name", req.NewName, "path", repo.Path, "error", err)
+		http.Error(w, "Failed to rename branch: "+err.Error(), gitErrorStatus(err))
+		return
+	}
+
+	slog.InfoContext(ctx, "Branch renamed successfully", "id", id, "name", req.Name, "new_name", req.NewName)
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleDeleteBranch handles requests to delete a branch. Pass force=true to
+// delete a branch that is not fully merged.
+func (s *Server) handleDeleteBranch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+	name := vars["name"]
+	force := r.URL.Query().Get("force") == "true"
+
+	slog.InfoContext(ctx, "Deleting branch", "id", id, "name", name, "force", force)
+
+	repo, err := s.getRepoByID(id)
+	if err != nil {
+		slog.WarnContext(ctx, "Delete branch failed - repository not found", "id", id)
+		http.Error(w, "Repository not found", http.StatusNotFound)
+		return
+	}
+
+	if err := git.DeleteBranch(repo.Path, name, force); err != nil {
+		slog.ErrorContext(ctx, "Delete branch failed", "id", id, "name", name, "path", repo.Path, "error", err)
+		http.Error(w, "Failed to delete branch: "+err.Error(), gitErrorStatus(err))
+		return
+	}
+
+	slog.InfoContext(ctx, "Branch deleted successfully", "id", id, "name", name)
+	w.WriteHeader(http.StatusNoContent)
+}
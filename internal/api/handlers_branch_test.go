@@ -0,0 +1,80 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/Gemini8532/gitwapp/internal/git"
+	"github.com/Gemini8532/gitwapp/pkg/models"
+)
+
+func TestHandleBranches(t *testing.T) {
+	server, configDir := setupTestServer(t)
+	defer os.RemoveAll(configDir)
+
+	tmpDir, repoPath := setupRepoForTest(t)
+	defer os.RemoveAll(tmpDir)
+
+	repo := models.Repository{ID: "1", Name: "Test", Path: repoPath}
+	server.store.SaveRepositories([]models.Repository{repo})
+
+	// Create and checkout
+	body, _ := json.Marshal(CreateBranchRequest{Name: "feature/login", Checkout: true})
+	req, _ := http.NewRequest("POST", "/api/repos/1/branches", bytes.NewBuffer(body))
+	addAuth(t, req)
+	rr := httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Create branch failed: %v %s", rr.Code, rr.Body.String())
+	}
+
+	// Duplicate
+	req, _ = http.NewRequest("POST", "/api/repos/1/branches", bytes.NewBuffer(body))
+	addAuth(t, req)
+	rr = httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusConflict {
+		t.Errorf("Expected 409 for duplicate branch, got %v", rr.Code)
+	}
+
+	// List
+	req, _ = http.NewRequest("GET", "/api/repos/1/branches", nil)
+	addAuth(t, req)
+	rr = httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("List branches failed: %v %s", rr.Code, rr.Body.String())
+	}
+	var branches []git.Branch
+	json.NewDecoder(rr.Body).Decode(&branches)
+	if len(branches) != 2 {
+		t.Fatalf("Expected 2 branches, got %+v", branches)
+	}
+	for _, b := range branches {
+		if b.IsHead != (b.Name == "feature/login") {
+			t.Errorf("Unexpected HEAD flag on %+v", b)
+		}
+	}
+
+	// Checkout back and delete (name contains a slash)
+	body, _ = json.Marshal(CheckoutBranchRequest{Name: "master"})
+	req, _ = http.NewRequest("POST", "/api/repos/1/branches/checkout", bytes.NewBuffer(body))
+	addAuth(t, req)
+	rr = httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Checkout failed: %v %s", rr.Code, rr.Body.String())
+	}
+
+	req, _ = http.NewRequest("DELETE", "/api/repos/1/branches/feature/login", nil)
+	addAuth(t, req)
+	rr = httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNoContent {
+		t.Errorf("Delete branch failed: %v %s", rr.Code, rr.Body.String())
+	}
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Gemini8532/gitwapp/internal/git"
+	"github.com/Gemini8532/gitwapp/pkg/models"
+)
+
+func TestHandleCommitIdentity(t *testing.T) {
+	server, configDir := setupTestServer(t)
+	defer os.RemoveAll(configDir)
+
+	tmpDir, repoPath := setupRepoForTest(t)
+	defer os.RemoveAll(tmpDir)
+
+	server.store.SaveRepositories([]models.Repository{{ID: "1", Name: "Test", Path: repoPath}})
+	server.store.SaveUsers([]models.User{
+		{ID: "user1", Username: "testuser", Name: "Test User", Email: "test.user@example.com"},
+	})
+
+	commit := func(req CommitRequest) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(req)
+		r, _ := http.NewRequest("POST", "/api/repos/1/commit", bytes.NewBuffer(body))
+		addAuth(t, r)
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, r)
+		return rr
+	}
+	lastCommit := func(format string) string {
+		return strings.TrimSpace(gitCmd(t, repoPath, "log", "-1", "--format="+format))
+	}
+
+	os.WriteFile(filepath.Join(repoPath, "a.txt"), []byte("a\n"), 0644)
+	gitCmd(t, repoPath, "add", "a.txt")
+
+	// The logged-in user authors and commits
+	rr := commit(CommitRequest{Message: "Add a"})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Commit failed: %d %s", rr.Code, rr.Body.String())
+	}
+	var resp CommitResponse
+	json.NewDecoder(rr.Body).Decode(&resp)
+	if resp.Hash != lastCommit("%H") {
+		t.Errorf("Expected hash of the new commit, got %q", resp.Hash)
+	}
+	if got := lastCommit("%an <%ae>|%cn <%ce>"); got != "Test User <test.user@example.com>|Test User <test.user@example.com>" {
+		t.Errorf("Unexpected identities: %s", got)
+	}
+
+	// Nothing is staged
+	if rr := commit(CommitRequest{Message: "Again"}); rr.Code != http.StatusConflict {
+		t.Errorf("Expected 409 with nothing to commit, got %d %s", rr.Code, rr.Body.String())
+	}
+
+	// An explicit author with co-authors, committed by the logged-in user
+	rr = commit(CommitRequest{
+		Message:    "Pair on it",
+		Author:     &git.Identity{Name: "Ada", Email: "ada@example.com"},
+		AllowEmpty: true,
+		CoAuthors:  []git.Identity{{Name: "Bob", Email: "bob@example.com"}},
+	})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Commit failed: %d %s", rr.Code, rr.Body.String())
+	}
+	if got := lastCommit("%an|%cn"); got != "Ada|Test User" {
+		t.Errorf("Unexpected identities: %s", got)
+	}
+	if got := lastCommit("%B"); got != "Pair on it\n\nCo-authored-by: Bob <bob@example.com>" {
+		t.Errorf("Unexpected message: %q", got)
+	}
+
+	// Amending without a message or author keeps both
+	os.WriteFile(filepath.Join(repoPath, "b.txt"), []byte("b\n"), 0644)
+	gitCmd(t, repoPath, "add", "b.txt")
+	if rr := commit(CommitRequest{Amend: true}); rr.Code != http.StatusOK {
+		t.Fatalf("Amend failed: %d %s", rr.Code, rr.Body.String())
+	}
+	if got := lastCommit("%an|%cn|%s"); got != "Ada|Test User|Pair on it" {
+		t.Errorf("Unexpected amended commit: %s", got)
+	}
+	if got := strings.TrimSpace(gitCmd(t, repoPath, "rev-list", "--count", "HEAD")); got != "3" {
+		t.Errorf("Expected amend to replace the last commit, got %s commits", got)
+	}
+
+	// A user without an email commits with the git configuration, as before
+	// users had identities
+	server.store.SaveUsers([]models.User{{ID: "user1", Username: "testuser", Name: "Test User"}})
+	gitCmd(t, repoPath, "config", "user.name", "Config User")
+	gitCmd(t, repoPath, "config", "user.email", "config@example.com")
+	if rr := commit(CommitRequest{Message: "Config identity", AllowEmpty: true}); rr.Code != http.StatusOK {
+		t.Fatalf("Commit failed: %d %s", rr.Code, rr.Body.String())
+	}
+	if got := lastCommit("%an <%ae>|%cn <%ce>"); got != "Config User <config@example.com>|Config User <config@example.com>" {
+		t.Errorf("Expected the git configuration identity, got %s", got)
+	}
+
+	// So does a logged-in user missing from the user store
+	server.store.SaveUsers([]models.User{})
+	if rr := commit(CommitRequest{Message: "Unknown user", AllowEmpty: true}); rr.Code != http.StatusOK {
+		t.Fatalf("Commit failed: %d %s", rr.Code, rr.Body.String())
+	}
+	if got := lastCommit("%an <%ae>"); got != "Config User <config@example.com>" {
+		t.Errorf("Expected the git configuration identity, got %s", got)
+	}
+
+	// An explicit author is used as is, and also commits when the user has
+	// no identity
+	rr = commit(CommitRequest{Message: "Explicit", Author: &git.Identity{Name: "Ada", Email: "ada@example.com"}, AllowEmpty: true})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Commit failed: %d %s", rr.Code, rr.Body.String())
+	}
+	if got := lastCommit("%an <%ae>|%cn <%ce>"); got != "Ada <ada@example.com>|Ada <ada@example.com>" {
+		t.Errorf("Unexpected identities: %s", got)
+	}
+
+	if rr := commit(CommitRequest{Message: "No email", Author: &git.Identity{Name: "Ada"}, AllowEmpty: true}); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an author without an email, got %d", rr.Code)
+	}
+	if rr := commit(CommitRequest{}); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without a message, got %d", rr.Code)
+	}
+	if rr := commit(CommitRequest{Message: "Bad", Author: &git.Identity{Name: "Eve <eve>"}, AllowEmpty: true}); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid author, got %d", rr.Code)
+	}
+}
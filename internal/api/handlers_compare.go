@@ -0,0 +1,47 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/Gemini8532/gitwapp/internal/git"
+	"github.com/gorilla/mux"
+)
+
+// handleCompare handles requests to compare two refs, e.g. a feature branch
+// against main. The base query parameter is required; head defaults to HEAD.
+// Unrelated histories are reported with status 409.
+func (s *Server) handleCompare(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+	base := r.URL.Query().Get("base")
+	head := r.URL.Query().Get("head")
+
+	if base == "" {
+		http.Error(w, "Base parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	slog.InfoContext(ctx, "Comparing refs", "id", id, "base", base, "head", head)
+
+	repo, err := s.getRepoByID(id)
+	if err != nil {
+		slog.WarnContext(ctx, "Compare failed - repository not found", "id", id)
+		http.Error(w, "Repository not found", http.StatusNotFound)
+		return
+	}
+
+	cmp, err := git.Compare(repo.Path, base, head)
+	if err != nil {
+		slog.ErrorContext(ctx, "Compare failed", "id", id, "base", base, "head", head, "path", repo.Path, "error", err)
+		http.Error(w, "Failed to compare: "+err.Error(), gitErrorStatus(err))
+		return
+	}
+
+	slog.InfoContext(ctx, "Refs compared successfully", "id", id, "ahead", cmp.Ahead, "behind", cmp.Behind, "files", len(cmp.Files))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(cmp)
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Gemini8532/gitwapp/internal/git"
+	"github.com/Gemini8532/gitwapp/pkg/models"
+)
+
+func TestHandleCompare(t *testing.T) {
+	server, configDir := setupTestServer(t)
+	defer os.RemoveAll(configDir)
+
+	tmpDir, repoPath := setupRepoForTest(t)
+	defer os.RemoveAll(tmpDir)
+
+	repo := models.Repository{ID: "1", Name: "Test", Path: repoPath}
+	server.store.SaveRepositories([]models.Repository{repo})
+
+	main := strings.TrimSpace(gitCmd(t, repoPath, "rev-parse", "--abbrev-ref", "HEAD"))
+	gitCmd(t, repoPath, "checkout", "-b", "feature")
+	writeAndCommit(t, repoPath, "feature.txt", "feature\n", "Add feature")
+
+	get := func(query string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/api/repos/1/compare"+query, nil)
+		addAuth(t, req)
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := get("?base=" + main + "&head=feature")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %v %s", rr.Code, rr.Body.String())
+	}
+	var cmp git.Comparison
+	if err := json.NewDecoder(rr.Body).Decode(&cmp); err != nil {
+		t.Fatal(err)
+	}
+	if cmp.Ahead != 1 || len(cmp.Commits) != 1 || cmp.Commits[0].Summary != "Add feature" {
+		t.Errorf("Expected the feature commit, got %+v", cmp.Commits)
+	}
+	if len(cmp.Files) != 1 || cmp.Files[0].Path != "feature.txt" || cmp.Files[0].Patch == "" {
+		t.Errorf("Expected feature.txt with a patch, got %+v", cmp.Files)
+	}
+
+	if rr := get("?head=feature"); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without base, got %v", rr.Code)
+	}
+	if rr := get("?base=no-such-branch"); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown base, got %v", rr.Code)
+	}
+}
@@ -0,0 +1,204 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/Gemini8532/gitwapp/internal/git"
+	"github.com/gorilla/mux"
+)
+
+// ConflictsResponse lists the conflicted files of the operation in progress.
+type ConflictsResponse struct {
+	State     string         `json:"state"`
+	Conflicts []git.Conflict `json:"conflicts"`
+}
+
+// ConflictFileRequest represents the request body for operations on a single
+// conflicted file.
+type ConflictFileRequest struct {
+	Path    string `json:"path"`
+	Content string `json:"content"` // Resolved content, for PUT /conflicts/resolution
+	Side    string `json:"side"`    // "ours" or "theirs", for POST /conflicts/take
+}
+
+// handleListConflicts handles requests to list the conflicted files of a repository.
+func (s *Server) handleListConflicts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	slog.InfoContext(ctx, "Listing conflicts", "id", id)
+
+	repo, err := s.getRepoByID(id)
+	if err != nil {
+		slog.WarnContext(ctx, "List conflicts failed - repository not found", "id", id)
+		http.Error(w, "Repository not found", http.StatusNotFound)
+		return
+	}
+
+	status, err := git.GetStatus(repo.Path)
+	if err != nil {
+		slog.ErrorContext(ctx, "List conflicts failed - unable to get git status", "id", id, "path", repo.Path, "error", err)
+		http.Error(w, "Failed to get git status: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	conflicts, err := git.Conflicts(repo.Path)
+	if err != nil {
+		slog.ErrorContext(ctx, "List conflicts failed", "id", id, "path", repo.Path, "error", err)
+		http.Error(w, "Failed to list conflicts: "+err.Error(), gitErrorStatus(err))
+		return
+	}
+
+	slog.InfoContext(ctx, "Conflicts listed successfully", "id", id, "state", status.State, "count", len(conflicts))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ConflictsResponse{State: status.State, Conflicts: conflicts})
+}
+
+// handleGetConflictVersions handles requests for the base, ours and theirs
+// versions of a conflicted file.
+func (s *Server) handleGetConflictVersions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+	file := r.URL.Query().Get("path")
+
+	if file == "" {
+		http.Error(w, "Path parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	slog.InfoContext(ctx, "Getting conflict versions", "id", id, "file", file)
+
+	repo, err := s.getRepoByID(id)
+	if err != nil {
+		slog.WarnContext(ctx, "Get conflict versions failed - repository not found", "id", id)
+		http.Error(w, "Repository not found", http.StatusNotFound)
+		return
+	}
+
+	versions, err := git.GetConflictVersions(repo.Path, file)
+	if err != nil {
+		slog.ErrorContext(ctx, "Get conflict versions failed", "id", id, "file", file, "error", err)
+		http.Error(w, "Failed to get conflict versions: "+err.Error(), gitErrorStatus(err))
+		return
+	}
+
+	slog.InfoContext(ctx, "Conflict versions retrieved successfully", "id", id, "file", file)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(versions)
+}
+
+// handleWriteResolution handles requests to write the resolved content of a
+// conflicted file to the working tree.
+func (s *Server) handleWriteResolution(w http.ResponseWriter, r *http.Request) {
+	s.handleConflictFile(w, r, "write resolution", func(path string, req ConflictFileRequest) error {
+		return git.WriteResolution(path, req.Path, []byte(req.Content))
+	})
+}
+
+// handleMarkResolved handles requests to stage a conflicted file, marking it resolved.
+func (s *Server) handleMarkResolved(w http.ResponseWriter, r *http.Request) {
+	s.handleConflictFile(w, r, "mark resolved", func(path string, req ConflictFileRequest) error {
+		return git.MarkResolved(path, req.Path)
+	})
+}
+
+// handleTakeSide handles requests to resolve a conflicted file with the
+// whole of one side.
+func (s *Server) handleTakeSide(w http.ResponseWriter, r *http.Request) {
+	s.handleConflictFile(w, r, "take side", func(path string, req ConflictFileRequest) error {
+		return git.TakeSide(path, req.Path, req.Side)
+	})
+}
+
+// handleConflictFile decodes a ConflictFileRequest and applies op to the
+// repository, writing the response.
+func (s *Server) handleConflictFile(w http.ResponseWriter, r *http.Request, action string, op func(path string, req ConflictFileRequest) error) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req ConflictFileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.ErrorContext(ctx, "Failed to decode conflict request", "id", id, "action", action, "error", err)
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	slog.InfoContext(ctx, "Resolving conflict", "id", id, "action", action, "file", req.Path, "side", req.Side)
+
+	if req.Path == "" {
+		slog.WarnContext(ctx, "Resolve conflict failed - path is required", "id", id, "action", action)
+		http.Error(w, "Path is required", http.StatusBadRequest)
+		return
+	}
+
+	repo, err := s.getRepoByID(id)
+	if err != nil {
+		slog.WarnContext(ctx, "Resolve conflict failed - repository not found", "id", id, "action", action)
+		http.Error(w, "Repository not found", http.StatusNotFound)
+		return
+	}
+
+	if err := op(repo.Path, req); err != nil {
+		slog.ErrorContext(ctx, "Resolve conflict failed", "id", id, "action", action, "file", req.Path, "error", err)
+		http.Error(w, "Failed to "+action+": "+err.Error(), gitErrorStatus(err))
+		return
+	}
+
+	slog.InfoContext(ctx, "Conflict updated successfully", "id", id, "action", action, "file", req.Path)
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleAbortOperation handles requests to abort the merge or other
+// operation in progress.
+func (s *Server) handleAbortOperation(w http.ResponseWriter, r *http.Request) {
+	s.handleOperation(w, r, "abort", git.AbortOperation)
+}
+
+// handleContinueOperation handles requests to conclude the merge or other
+// operation in progress once its conflicts are resolved.
+func (s *Server) handleContinueOperation(w http.ResponseWriter, r *http.Request) {
+	s.handleOperation(w, r, "continue", git.ContinueOperation)
+}
+
+// handleOperation applies op to the repository and responds with the
+// refreshed status.
+func (s *Server) handleOperation(w http.ResponseWriter, r *http.Request, action string, op func(path string) error) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	slog.InfoContext(ctx, "Updating operation in progress", "id", id, "action", action)
+
+	repo, err := s.getRepoByID(id)
+	if err != nil {
+		slog.WarnContext(ctx, "Update operation failed - repository not found", "id", id, "action", action)
+		http.Error(w, "Repository not found", http.StatusNotFound)
+		return
+	}
+
+	if err := op(repo.Path); err != nil {
+		slog.ErrorContext(ctx, "Update operation failed", "id", id, "action", action, "path", repo.Path, "error", err)
+		http.Error(w, "Failed to "+action+": "+err.Error(), gitErrorStatus(err))
+		return
+	}
+
+	status, err := git.GetStatus(repo.Path)
+	if err != nil {
+		slog.ErrorContext(ctx, "Update operation failed - unable to get git status", "id", id, "action", action, "path", repo.Path, "error", err)
+		http.Error(w, "Failed to get git status: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	slog.InfoContext(ctx, "Operation updated successfully", "id", id, "action", action, "path", repo.Path)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/Gemini8532/gitwapp/internal/git"
+	"github.com/Gemini8532/gitwapp/pkg/models"
+)
+
+func TestHandleConflicts(t *testing.T) {
+	server, configDir := setupTestServer(t)
+	defer os.RemoveAll(configDir)
+
+	tmpDir, repoPath := setupRepoForTest(t)
+	defer os.RemoveAll(tmpDir)
+	gitCmd(t, repoPath, "config", "user.name", "Test")
+	gitCmd(t, repoPath, "config", "user.email", "test@example.com")
+
+	repo := models.Repository{ID: "1", Name: "Test", Path: repoPath}
+	server.store.SaveRepositories([]models.Repository{repo})
+
+	gitCmd(t, repoPath, "checkout", "-b", "feature")
+	writeAndCommit(t, repoPath, "README.md", "theirs\n", "Theirs")
+	gitCmd(t, repoPath, "checkout", "master")
+	writeAndCommit(t, repoPath, "README.md", "ours\n", "Ours")
+	if _, err := git.Merge(repoPath, git.MergeOptions{Ref: "feature"}); err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest("GET", "/api/repos/1/conflicts", nil)
+	addAuth(t, req)
+	rr := httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	var list ConflictsResponse
+	json.NewDecoder(rr.Body).Decode(&list)
+	if list.State != git.StateMerging || len(list.Conflicts) != 1 || list.Conflicts[0].Path != "README.md" {
+		t.Fatalf("Unexpected conflicts: %+v", list)
+	}
+
+	req, _ = http.NewRequest("GET", "/api/repos/1/conflicts/versions?path=README.md", nil)
+	addAuth(t, req)
+	rr = httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	var versions git.ConflictVersions
+	json.NewDecoder(rr.Body).Decode(&versions)
+	if versions.Ours == nil || *versions.Ours != "ours\n" || versions.Theirs == nil || *versions.Theirs != "theirs\n" {
+		t.Errorf("Unexpected versions: %+v", versions)
+	}
+
+	// Continuing with unresolved conflicts is refused
+	req, _ = http.NewRequest("POST", "/api/repos/1/continue", nil)
+	addAuth(t, req)
+	rr = httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusConflict {
+		t.Errorf("Expected 409 continuing with conflicts, got %v", rr.Code)
+	}
+
+	body, _ := json.Marshal(ConflictFileRequest{Path: "README.md", Side: git.SideOurs})
+	req, _ = http.NewRequest("POST", "/api/repos/1/conflicts/take", bytes.NewBuffer(body))
+	addAuth(t, req)
+	rr = httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Take side failed: %v %s", rr.Code, rr.Body.String())
+	}
+
+	req, _ = http.NewRequest("POST", "/api/repos/1/continue", nil)
+	addAuth(t, req)
+	rr = httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Continue failed: %v %s", rr.Code, rr.Body.String())
+	}
+	var status git.Status
+	json.NewDecoder(rr.Body).Decode(&status)
+	if status.State != "" || !status.Clean {
+		t.Errorf("Expected clean status after continue, got %+v", status)
+	}
+}
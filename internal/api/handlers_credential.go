@@ -0,0 +1,313 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Gemini8532/gitwapp/internal/git"
+	"github.com/Gemini8532/gitwapp/pkg/models"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"golang.org/x/crypto/ssh"
+)
+
+// AddCredentialRequest represents the request body for adding a credential to the vault.
+type AddCredentialRequest struct {
+	Name       string `json:"name"`
+	Type       string `json:"type"` // "ssh" or "https"
+	Username   string `json:"username"`
+	Secret     string `json:"secret"`     // HTTPS token or PEM-encoded SSH private key
+	Passphrase string `json:"passphrase"` // Optional, for encrypted SSH keys
+}
+
+// CredentialResponse describes a vault credential without its secrets.
+type CredentialResponse struct {
+	ID            string    `json:"id"`
+	Name          string    `json:"name"`
+	Type          string    `json:"type"`
+	Username      string    `json:"username,omitempty"`
+	HasPassphrase bool      `json:"has_passphrase"`
+	Fingerprint   string    `json:"fingerprint,omitempty"` // SHA256 fingerprint of an SSH public key
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// BindCredentialRequest represents the request body for binding a vault
+// credential to a repository.
+type BindCredentialRequest struct {
+	CredentialID string `json:"credential_id"`
+}
+
+// handleListCredentials handles requests to list the credentials in the vault.
+// Secrets are never included in the response.
+func (s *Server) handleListCredentials(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	slog.InfoContext(ctx, "Listing credentials")
+	creds, err := s.store.LoadCredentials()
+	if err != nil {
+		slog.ErrorContext(ctx, "List credentials failed - unable to load credentials", "error", err)
+		http.Error(w, "Failed to load credentials", http.StatusInternalServerError)
+		return
+	}
+
+	resp := []CredentialResponse{}
+	for _, c := range creds {
+		resp = append(resp, newCredentialResponse(c))
+	}
+
+	slog.InfoContext(ctx, "Credentials listed successfully", "count", len(resp))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleAddCredential handles requests to add a credential to the vault.
+func (s *Server) handleAddCredential(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req AddCredentialRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.ErrorContext(ctx, "Failed to decode add credential request", "error", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	slog.InfoContext(ctx, "Adding credential", "name", req.Name, "type", req.Type, "username", req.Username)
+
+	if req.Name == "" || req.Secret == "" {
+		slog.WarnContext(ctx, "Add credential failed - name and secret are required")
+		http.Error(w, "Name and secret are required", http.StatusBadRequest)
+		return
+	}
+
+	switch req.Type {
+	case models.CredentialHTTPS:
+	case models.CredentialSSH:
+		if _, err := parseSSHKey(req.Secret, req.Passphrase); err != nil {
+			slog.WarnContext(ctx, "Add credential failed - invalid SSH key", "name", req.Name, "error", err)
+			http.Error(w, "Invalid SSH private key or passphrase", http.StatusBadRequest)
+			return
+		}
+	default:
+		slog.WarnContext(ctx, "Add credential failed - invalid type", "type", req.Type)
+		http.Error(w, "Type must be \"ssh\" or \"https\"", http.StatusBadRequest)
+		return
+	}
+
+	cred := models.Credential{
+		ID:         uuid.New().String(),
+		Name:       req.Name,
+		Type:       req.Type,
+		Username:   req.Username,
+		Secret:     req.Secret,
+		Passphrase: req.Passphrase,
+		CreatedAt:  time.Now(),
+	}
+
+	if err := s.store.SaveCredential(cred); err != nil {
+		slog.ErrorContext(ctx, "Add credential failed - unable to save credential", "name", req.Name, "error", err)
+		http.Error(w, "Failed to save credential", http.StatusInternalServerError)
+		return
+	}
+
+	slog.InfoContext(ctx, "Credential added successfully", "id", cred.ID, "name", cred.Name)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(newCredentialResponse(cred))
+}
+
+// handleDeleteCredential handles requests to delete a credential from the vault.
+// Credentials still bound to a repository cannot be deleted.
+func (s *Server) handleDeleteCredential(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	credID := vars["credentialId"]
+
+	slog.InfoContext(ctx, "Deleting credential", "credential_id", credID)
+
+	repos, err := s.store.LoadRepositories()
+	if err != nil {
+		slog.ErrorContext(ctx, "Delete credential failed - unable to load repositories", "error", err)
+		http.Error(w, "Failed to load repositories", http.StatusInternalServerError)
+		return
+	}
+	for _, repo := range repos {
+		if repo.CredentialID == credID {
+			slog.WarnContext(ctx, "Delete credential failed - credential in use", "credential_id", credID, "repo_id", repo.ID)
+			http.Error(w, "Credential is bound to repository "+repo.Name, http.StatusConflict)
+			return
+		}
+	}
+
+	removed, err := s.store.DeleteCredential(credID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Delete credential failed - unable to save credentials", "credential_id", credID, "error", err)
+		http.Error(w, "Failed to delete credential", http.StatusInternalServerError)
+		return
+	}
+	if !removed {
+		slog.WarnContext(ctx, "Delete credential failed - credential not found", "credential_id", credID)
+		http.Error(w, "Credential not found", http.StatusNotFound)
+		return
+	}
+
+	slog.InfoContext(ctx, "Credential deleted successfully", "credential_id", credID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleBindCredential handles requests to select the vault credential a
+// repository uses for push, pull and fetch.
+func (s *Server) handleBindCredential(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req BindCredentialRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.ErrorContext(ctx, "Failed to decode bind credential request", "id", id, "error", err)
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	slog.InfoContext(ctx, "Binding credential", "id", id, "credential_id", req.CredentialID)
+
+	cred, err := s.store.LoadCredential(req.CredentialID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Bind credential failed - unable to load credential", "id", id, "error", err)
+		http.Error(w, "Failed to load credential", http.StatusInternalServerError)
+		return
+	}
+	if cred == nil {
+		slog.WarnContext(ctx, "Bind credential failed - credential not found", "id", id, "credential_id", req.CredentialID)
+		http.Error(w, "Credential not found", http.StatusNotFound)
+		return
+	}
+
+	s.setRepoCredential(w, r, id, req.CredentialID)
+}
+
+// handleUnbindCredential handles requests to stop a repository from using a
+// vault credential.
+func (s *Server) handleUnbindCredential(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	slog.InfoContext(ctx, "Unbinding credential", "id", id)
+
+	s.setRepoCredential(w, r, id, "")
+}
+
+// setRepoCredential stores the credential binding of a repository and writes the response.
+func (s *Server) setRepoCredential(w http.ResponseWriter, r *http.Request, id string, credID string) {
+	ctx := r.Context()
+
+	repos, err := s.store.LoadRepositories()
+	if err != nil {
+		slog.ErrorContext(ctx, "Set repository credential failed - unable to load repositories", "id", id, "error", err)
+		http.Error(w, "Failed to load repositories", http.StatusInternalServerError)
+		return
+	}
+
+	found := false
+	for i := range repos {
+		if repos[i].ID == id {
+			repos[i].CredentialID = credID
+			found = true
+		}
+	}
+	if !found {
+		slog.WarnContext(ctx, "Set repository credential failed - repository not found", "id", id)
+		http.Error(w, "Repository not found", http.StatusNotFound)
+		return
+	}
+
+	if err := s.store.SaveRepositories(repos); err != nil {
+		slog.ErrorContext(ctx, "Set repository credential failed - unable to save repositories", "id", id, "error", err)
+		http.Error(w, "Failed to save repositories", http.StatusInternalServerError)
+		return
+	}
+
+	slog.InfoContext(ctx, "Repository credential updated successfully", "id", id, "credential_id", credID)
+	w.WriteHeader(http.StatusOK)
+}
+
+// repoCredentials returns the explicit credentials to use for a repository's
+// remote operations: its bound vault credential, else its stored HTTPS
+// token, else nil to fall back to the server defaults.
+func (s *Server) repoCredentials(repo *models.Repository) (*git.Credentials, error) {
+	if repo.CredentialID != "" {
+		cred, err := s.store.LoadCredential(repo.CredentialID)
+		if err != nil {
+			return nil, err
+		}
+		if cred != nil {
+			if cred.Type == models.CredentialSSH {
+				return &git.Credentials{Username: cred.Username, SSHKey: []byte(cred.Secret), Passphrase: cred.Passphrase}, nil
+			}
+			return &git.Credentials{Username: tokenUsername(cred.Username), Password: cred.Secret}, nil
+		}
+	}
+
+	token, err := s.store.LoadRepoToken(repo.ID)
+	if err != nil || token == nil {
+		return nil, err
+	}
+	return &git.Credentials{Username: tokenUsername(token.Username), Password: token.Token}, nil
+}
+
+// withPassphrase adds a request-supplied SSH key passphrase to creds.
+func withPassphrase(creds *git.Credentials, passphrase string) *git.Credentials {
+	if passphrase == "" {
+		return creds
+	}
+	if creds == nil {
+		return &git.Credentials{Passphrase: passphrase}
+	}
+	if creds.Passphrase == "" {
+		withPass := *creds
+		withPass.Passphrase = passphrase
+		return &withPass
+	}
+	return creds
+}
+
+// tokenUsername returns the username to send alongside an HTTPS token.
+func tokenUsername(username string) string {
+	if username == "" {
+		// Most hosts accept any non-empty username alongside a token
+		return "git"
+	}
+	return username
+}
+
+// newCredentialResponse converts a vault credential into its public representation.
+func newCredentialResponse(c models.Credential) CredentialResponse {
+	resp := CredentialResponse{
+		ID:            c.ID,
+		Name:          c.Name,
+		Type:          c.Type,
+		Username:      c.Username,
+		HasPassphrase: c.Passphrase != "",
+		CreatedAt:     c.CreatedAt,
+	}
+	if c.Type == models.CredentialSSH {
+		if signer, err := parseSSHKey(c.Secret, c.Passphrase); err == nil {
+			resp.Fingerprint = ssh.FingerprintSHA256(signer.PublicKey())
+		}
+	}
+	return resp
+}
+
+// parseSSHKey parses a PEM-encoded SSH private key, decrypting it with the
+// passphrase if one is given.
+func parseSSHKey(key string, passphrase string) (ssh.Signer, error) {
+	if passphrase != "" {
+		return ssh.ParsePrivateKeyWithPassphrase([]byte(key), []byte(passphrase))
+	}
+	return ssh.ParsePrivateKey([]byte(key))
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/Gemini8532/gitwapp/pkg/models"
+	"golang.org/x/crypto/ssh"
+)
+
+// generateSSHKey returns a PEM-encoded ed25519 private key and its fingerprint.
+func generateSSHKey(t *testing.T) (string, string) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+	return string(pem.EncodeToMemory(block)), ssh.FingerprintSHA256(signer.PublicKey())
+}
+
+func TestHandleCredentialVault(t *testing.T) {
+	server, configDir := setupTestServer(t)
+	defer os.RemoveAll(configDir)
+
+	repo := models.Repository{ID: "1", Name: "Test", Path: configDir}
+	server.store.SaveRepositories([]models.Repository{repo})
+
+	key, fingerprint := generateSSHKey(t)
+
+	// Invalid keys are rejected
+	body, _ := json.Marshal(AddCredentialRequest{Name: "bad", Type: "ssh", Secret: "not a key"})
+	req, _ := http.NewRequest("POST", "/api/credentials", bytes.NewBuffer(body))
+	addAuth(t, req)
+	rr := httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for invalid key, got %v", rr.Code)
+	}
+
+	body, _ = json.Marshal(AddCredentialRequest{Name: "deploy", Type: "ssh", Secret: key})
+	req, _ = http.NewRequest("POST", "/api/credentials", bytes.NewBuffer(body))
+	addAuth(t, req)
+	rr = httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Add credential failed: %v %s", rr.Code, rr.Body.String())
+	}
+	var created CredentialResponse
+	json.NewDecoder(rr.Body).Decode(&created)
+	if created.ID == "" || created.Fingerprint != fingerprint {
+		t.Errorf("Unexpected credential response: %+v", created)
+	}
+
+	// The vault must not hold the key in plain text
+	data, err := os.ReadFile(server.store.GetCredentialsPath())
+	if err != nil {
+		t.Fatalf("Failed to read credentials file: %v", err)
+	}
+	if bytes.Contains(data, []byte("PRIVATE KEY")) {
+		t.Error("Key stored in plain text")
+	}
+
+	req, _ = http.NewRequest("GET", "/api/credentials", nil)
+	addAuth(t, req)
+	rr = httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	if bytes.Contains(rr.Body.Bytes(), []byte("PRIVATE KEY")) {
+		t.Error("Key leaked in list response")
+	}
+	var list []CredentialResponse
+	json.NewDecoder(rr.Body).Decode(&list)
+	if len(list) != 1 || list[0].Name != "deploy" {
+		t.Errorf("Unexpected credential list: %+v", list)
+	}
+
+	// Bind the credential to the repository
+	body, _ = json.Marshal(BindCredentialRequest{CredentialID: created.ID})
+	req, _ = http.NewRequest("PUT", "/api/repos/1/credential", bytes.NewBuffer(body))
+	addAuth(t, req)
+	rr = httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Bind credential failed: %v %s", rr.Code, rr.Body.String())
+	}
+
+	bound, _ := server.getRepoByID("1")
+	creds, err := server.repoCredentials(bound)
+	if err != nil || creds == nil || string(creds.SSHKey) != key {
+		t.Errorf("Expected bound SSH key credentials, got %+v (err %v)", creds, err)
+	}
+
+	// Bound credentials cannot be deleted
+	req, _ = http.NewRequest("DELETE", "/api/credentials/"+created.ID, nil)
+	addAuth(t, req)
+	rr = httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusConflict {
+		t.Errorf("Expected 409 deleting bound credential, got %v", rr.Code)
+	}
+
+	req, _ = http.NewRequest("DELETE", "/api/repos/1/credential", nil)
+	addAuth(t, req)
+	rr = httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Unbind credential failed: %v %s", rr.Code, rr.Body.String())
+	}
+
+	req, _ = http.NewRequest("DELETE", "/api/credentials/"+created.ID, nil)
+	addAuth(t, req)
+	rr = httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNoContent {
+		t.Errorf("Delete credential failed: %v %s", rr.Code, rr.Body.String())
+	}
+}
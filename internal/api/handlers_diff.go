@@ -0,0 +1,59 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/Gemini8532/gitwapp/internal/git"
+	"github.com/gorilla/mux"
+)
+
+// handleGetStructuredDiff handles requests for the structured diff of a
+// repository. Supported query parameters: mode ("head" by default, "staged"
+// or "unstaged"), file to limit the diff to a file or directory, context
+// for the number of context lines (default 3) and ignore_whitespace=true.
+func (s *Server) handleGetStructuredDiff(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+	q := r.URL.Query()
+
+	opts := git.DiffOptions{
+		Mode:             q.Get("mode"),
+		Path:             q.Get("file"),
+		Context:          git.DefaultDiffContext,
+		IgnoreWhitespace: q.Get("ignore_whitespace") == "true",
+	}
+	if opts.Mode == "" {
+		opts.Mode = git.DiffHead
+	}
+	if v := q.Get("context"); v != "" {
+		var err error
+		if opts.Context, err = parseIntParam(v); err != nil {
+			http.Error(w, "Invalid context parameter", http.StatusBadRequest)
+			return
+		}
+	}
+
+	slog.InfoContext(ctx, "Getting structured diff", "id", id, "mode", opts.Mode, "file", opts.Path)
+
+	repo, err := s.getRepoByID(id)
+	if err != nil {
+		slog.WarnContext(ctx, "Get structured diff failed - repository not found", "id", id)
+		http.Error(w, "Repository not found", http.StatusNotFound)
+		return
+	}
+
+	diff, err := git.GetDiff(repo.Path, opts)
+	if err != nil {
+		slog.ErrorContext(ctx, "Get structured diff failed", "id", id, "path", repo.Path, "error", err)
+		http.Error(w, "Failed to get diff: "+err.Error(), gitErrorStatus(err))
+		return
+	}
+
+	slog.InfoContext(ctx, "Structured diff retrieved successfully", "id", id, "files", len(diff.Files))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(diff)
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Gemini8532/gitwapp/internal/git"
+	"github.com/Gemini8532/gitwapp/pkg/models"
+)
+
+func TestHandleGetStructuredDiff(t *testing.T) {
+	server, configDir := setupTestServer(t)
+	defer os.RemoveAll(configDir)
+
+	tmpDir, repoPath := setupRepoForTest(t)
+	defer os.RemoveAll(tmpDir)
+
+	repo := models.Repository{ID: "1", Name: "Test", Path: repoPath}
+	server.store.SaveRepositories([]models.Repository{repo})
+
+	// One staged new file and one unstaged modification
+	os.WriteFile(filepath.Join(repoPath, "new.txt"), []byte("new\n"), 0644)
+	gitCmd(t, repoPath, "add", "new.txt")
+	os.WriteFile(filepath.Join(repoPath, "README.md"), []byte("Changed\n"), 0644)
+
+	get := func(query string) (*httptest.ResponseRecorder, git.Diff) {
+		req, _ := http.NewRequest("GET", "/api/repos/1/structured-diff"+query, nil)
+		addAuth(t, req)
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, req)
+
+		var diff git.Diff
+		if rr.Code == http.StatusOK {
+			if err := json.NewDecoder(rr.Body).Decode(&diff); err != nil {
+				t.Fatalf("Failed to decode diff: %v", err)
+			}
+		}
+		return rr, diff
+	}
+
+	rr, diff := get("")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %v %s", rr.Code, rr.Body.String())
+	}
+	if diff.Mode != git.DiffHead || len(diff.Files) != 2 {
+		t.Errorf("Expected both files against HEAD, got %+v", diff)
+	}
+
+	_, diff = get("?mode=staged")
+	if len(diff.Files) != 1 || diff.Files[0].Path != "new.txt" || diff.Files[0].Status != git.ChangeAdded {
+		t.Errorf("Expected only the new file staged, got %+v", diff.Files)
+	}
+
+	_, diff = get("?mode=unstaged&file=README.md&context=0")
+	if len(diff.Files) != 1 || diff.Files[0].Path != "README.md" {
+		t.Fatalf("Expected only README.md unstaged, got %+v", diff.Files)
+	}
+	lines := diff.Files[0].Hunks[0].Lines
+	if len(lines) != 2 || lines[0].Type != git.LineDeleted || lines[1].Type != git.LineAdded || !lines[0].NoNewline {
+		t.Errorf("Unexpected lines: %+v", lines)
+	}
+
+	if rr, _ := get("?mode=bogus"); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for invalid mode, got %v", rr.Code)
+	}
+	if rr, _ := get("?context=-1"); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for invalid context, got %v", rr.Code)
+	}
+
+	req, _ := http.NewRequest("GET", "/api/repos/missing/structured-diff", nil)
+	addAuth(t, req)
+	rr = httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown repository, got %v", rr.Code)
+	}
+}
+
+func TestHandleGetDiffMode(t *testing.T) {
+	server, configDir := setupTestServer(t)
+	defer os.RemoveAll(configDir)
+
+	tmpDir, repoPath := setupRepoForTest(t)
+	defer os.RemoveAll(tmpDir)
+
+	repo := models.Repository{ID: "1", Name: "Test", Path: repoPath}
+	server.store.SaveRepositories([]models.Repository{repo})
+
+	// A staged change, then a further unstaged one
+	os.WriteFile(filepath.Join(repoPath, "README.md"), []byte("Staged\n"), 0644)
+	gitCmd(t, repoPath, "add", "README.md")
+	os.WriteFile(filepath.Join(repoPath, "README.md"), []byte("Unstaged\n"), 0644)
+
+	get := func(query string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/api/repos/1/diff?file=README.md"+query, nil)
+		addAuth(t, req)
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	tests := []struct {
+		query   string
+		added   string
+		removed string
+	}{
+		{"", "+Unstaged", "-Initial"},
+		{"&mode=head", "+Unstaged", "-Initial"},
+		{"&mode=staged", "+Staged", "-Initial"},
+		{"&mode=unstaged", "+Unstaged", "-Staged"},
+	}
+	for _, tt := range tests {
+		rr := get(tt.query)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected 200 for %q, got %v %s", tt.query, rr.Code, rr.Body.String())
+		}
+		if body := rr.Body.String(); !strings.Contains(body, tt.added) || !strings.Contains(body, tt.removed) {
+			t.Errorf("Unexpected diff for %q:\n%s", tt.query, body)
+		}
+	}
+
+	if rr := get("&mode=bogus"); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for invalid diff mode, got %v", rr.Code)
+	}
+}
@@ -0,0 +1,225 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/Gemini8532/gitwapp/internal/git"
+	"github.com/gorilla/mux"
+)
+
+// DiscardRequest represents the request body for discarding the changes of
+// a file, either entirely or only the selected hunks and lines of its
+// unstaged diff.
+type DiscardRequest struct {
+	File    string          `json:"file"`
+	Source  string          `json:"source"` // "index" (default) or "head"; ignored for selections
+	Hunks   []int           `json:"hunks"`
+	Lines   []git.LineRange `json:"lines"`
+	Confirm string          `json:"confirm"` // Token from the confirmation response
+}
+
+// CleanRequest represents the request body for removing untracked files.
+type CleanRequest struct {
+	Paths   []string `json:"paths"` // Optional, limits the removal to these paths
+	Confirm string   `json:"confirm"`
+}
+
+// ResetRequest represents the request body for a hard reset to HEAD.
+type ResetRequest struct {
+	Confirm string `json:"confirm"`
+}
+
+// handleDiscard handles requests to discard working tree changes of a file.
+// The request must be confirmed, see confirmed.
+func (s *Server) handleDiscard(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req DiscardRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.ErrorContext(ctx, "Failed to decode discard request", "id", id, "error", err)
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if req.Source == "" {
+		req.Source = git.DiscardFromIndex
+	}
+	partial := len(req.Hunks) > 0 || len(req.Lines) > 0
+
+	slog.InfoContext(ctx, "Discarding changes", "id", id, "file", req.File, "source", req.Source, "partial", partial)
+
+	if req.File == "" {
+		slog.WarnContext(ctx, "Discard failed - file is required", "id", id)
+		http.Error(w, "File is required", http.StatusBadRequest)
+		return
+	}
+
+	action, mode := "discard", git.DiffUnstaged
+	switch {
+	case partial:
+		action = "discard-hunks"
+	case req.Source == git.DiscardFromHead:
+		mode = git.DiffHead
+	case req.Source != git.DiscardFromIndex:
+		slog.WarnContext(ctx, "Discard failed - invalid source", "id", id, "source", req.Source)
+		http.Error(w, "Invalid source", http.StatusBadRequest)
+		return
+	}
+
+	repo, err := s.getRepoByID(id)
+	if err != nil {
+		slog.WarnContext(ctx, "Discard failed - repository not found", "id", id)
+		http.Error(w, "Repository not found", http.StatusNotFound)
+		return
+	}
+
+	diff, err := git.FileDiff(repo.Path, req.File, mode)
+	if err != nil {
+		slog.ErrorContext(ctx, "Discard failed - unable to get diff", "id", id, "file", req.File, "path", repo.Path, "error", err)
+		http.Error(w, "Failed to get diff: "+err.Error(), gitErrorStatus(err))
+		return
+	}
+	if diff == "" {
+		slog.WarnContext(ctx, "Discard failed - nothing to discard", "id", id, "file", req.File)
+		http.Error(w, "Nothing to discard", http.StatusConflict)
+		return
+	}
+
+	sel := git.HunkSelection{Hunks: req.Hunks, Lines: req.Lines}
+	selJSON, _ := json.Marshal(sel)
+	key := confirmationKey(id, action, req.File, req.Source, string(selJSON), diff)
+	if !s.confirmed(w, r, req.Confirm, action, key, []string{req.File}) {
+		return
+	}
+
+	if partial {
+		err = git.DiscardHunks(repo.Path, req.File, sel)
+	} else {
+		err = git.DiscardFile(repo.Path, req.File, req.Source)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Discard failed", "id", id, "file", req.File, "path", repo.Path, "error", err)
+		http.Error(w, "Failed to discard changes: "+err.Error(), gitErrorStatus(err))
+		return
+	}
+
+	slog.InfoContext(ctx, "Changes discarded successfully", "id", id, "file", req.File, "partial", partial)
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleClean handles requests to remove untracked files. The confirmation
+// response lists the files that will be removed.
+func (s *Server) handleClean(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req CleanRequest
+	if err := decodeOptionalJSON(r, &req); err != nil {
+		slog.ErrorContext(ctx, "Failed to decode clean request", "id", id, "error", err)
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	slog.InfoContext(ctx, "Removing untracked files", "id", id, "paths", req.Paths)
+
+	repo, err := s.getRepoByID(id)
+	if err != nil {
+		slog.WarnContext(ctx, "Clean failed - repository not found", "id", id)
+		http.Error(w, "Repository not found", http.StatusNotFound)
+		return
+	}
+
+	files, err := git.CleanUntracked(repo.Path, req.Paths, true)
+	if err != nil {
+		slog.ErrorContext(ctx, "Clean failed - unable to list untracked files", "id", id, "path", repo.Path, "error", err)
+		http.Error(w, "Failed to list untracked files: "+err.Error(), gitErrorStatus(err))
+		return
+	}
+	if len(files) == 0 {
+		slog.WarnContext(ctx, "Clean failed - nothing to remove", "id", id)
+		http.Error(w, "Nothing to remove", http.StatusConflict)
+		return
+	}
+
+	key := confirmationKey(id, "clean", strings.Join(req.Paths, "\x00"), strings.Join(files, "\x00"))
+	if !s.confirmed(w, r, req.Confirm, "clean", key, files) {
+		return
+	}
+
+	removed, err := git.CleanUntracked(repo.Path, req.Paths, false)
+	if err != nil {
+		slog.ErrorContext(ctx, "Clean failed", "id", id, "path", repo.Path, "error", err)
+		http.Error(w, "Failed to remove untracked files: "+err.Error(), gitErrorStatus(err))
+		return
+	}
+
+	slog.InfoContext(ctx, "Untracked files removed successfully", "id", id, "count", len(removed))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(removed)
+}
+
+// handleResetHard handles requests to discard all changes to tracked files.
+// The confirmation response lists the files that will be reverted.
+func (s *Server) handleResetHard(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req ResetRequest
+	if err := decodeOptionalJSON(r, &req); err != nil {
+		slog.ErrorContext(ctx, "Failed to decode reset request", "id", id, "error", err)
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	slog.InfoContext(ctx, "Resetting to HEAD", "id", id)
+
+	repo, err := s.getRepoByID(id)
+	if err != nil {
+		slog.WarnContext(ctx, "Reset failed - repository not found", "id", id)
+		http.Error(w, "Repository not found", http.StatusNotFound)
+		return
+	}
+
+	files, err := git.ChangedFiles(repo.Path)
+	if err != nil {
+		slog.ErrorContext(ctx, "Reset failed - unable to list changes", "id", id, "path", repo.Path, "error", err)
+		http.Error(w, "Failed to list changes: "+err.Error(), gitErrorStatus(err))
+		return
+	}
+	if len(files) == 0 {
+		slog.WarnContext(ctx, "Reset failed - nothing to reset", "id", id)
+		http.Error(w, "Nothing to reset", http.StatusConflict)
+		return
+	}
+
+	diff, err := git.FileDiff(repo.Path, ".", git.DiffHead)
+	if err != nil {
+		slog.ErrorContext(ctx, "Reset failed - unable to get diff", "id", id, "path", repo.Path, "error", err)
+		http.Error(w, "Failed to get diff: "+err.Error(), gitErrorStatus(err))
+		return
+	}
+
+	key := confirmationKey(id, "reset-hard", diff)
+	if !s.confirmed(w, r, req.Confirm, "reset-hard", key, files) {
+		return
+	}
+
+	if err := git.ResetHard(repo.Path); err != nil {
+		slog.ErrorContext(ctx, "Reset failed", "id", id, "path", repo.Path, "error", err)
+		http.Error(w, "Failed to reset: "+err.Error(), gitErrorStatus(err))
+		return
+	}
+
+	slog.InfoContext(ctx, "Reset to HEAD successfully", "id", id, "files", len(files))
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,161 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Gemini8532/gitwapp/pkg/models"
+)
+
+func TestHandleDiscard(t *testing.T) {
+	server, configDir := setupTestServer(t)
+	defer os.RemoveAll(configDir)
+
+	tmpDir, repoPath := setupRepoForTest(t)
+	defer os.RemoveAll(tmpDir)
+
+	repo := models.Repository{ID: "1", Name: "Test", Path: repoPath}
+	server.store.SaveRepositories([]models.Repository{repo})
+
+	do := func(path string, body any) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(body)
+		req, _ := http.NewRequest("POST", path, bytes.NewBuffer(data))
+		addAuth(t, req)
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, req)
+		return rr
+	}
+	confirmation := func(rr *httptest.ResponseRecorder) ConfirmationResponse {
+		t.Helper()
+		if rr.Code != http.StatusPreconditionRequired {
+			t.Fatalf("Expected 428, got %v %s", rr.Code, rr.Body.String())
+		}
+		var resp ConfirmationResponse
+		json.NewDecoder(rr.Body).Decode(&resp)
+		return resp
+	}
+
+	readme := filepath.Join(repoPath, "README.md")
+	if rr := do("/api/repos/1/discard", DiscardRequest{File: "README.md"}); rr.Code != http.StatusConflict {
+		t.Errorf("Expected 409 without changes, got %v", rr.Code)
+	}
+
+	os.WriteFile(readme, []byte("changed"), 0644)
+	resp := confirmation(do("/api/repos/1/discard", DiscardRequest{File: "README.md"}))
+	if resp.Token == "" || resp.Action != "discard" || len(resp.Files) != 1 || resp.Files[0] != "README.md" {
+		t.Fatalf("Unexpected confirmation: %+v", resp)
+	}
+	if data, _ := os.ReadFile(readme); string(data) != "changed" {
+		t.Fatal("Expected nothing to be discarded before confirmation")
+	}
+
+	// A token does not confirm changes made after it was issued
+	os.WriteFile(readme, []byte("changed again"), 0644)
+	if rr := do("/api/repos/1/discard", DiscardRequest{File: "README.md", Confirm: resp.Token}); rr.Code != http.StatusPreconditionFailed {
+		t.Fatalf("Expected 412 for stale token, got %v", rr.Code)
+	}
+
+	resp = confirmation(do("/api/repos/1/discard", DiscardRequest{File: "README.md"}))
+	if rr := do("/api/repos/1/discard", DiscardRequest{File: "README.md", Confirm: resp.Token}); rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200 for confirmed discard, got %v %s", rr.Code, rr.Body.String())
+	}
+	if data, _ := os.ReadFile(readme); string(data) != "Initial" {
+		t.Errorf("Expected committed content, got %q", data)
+	}
+
+	// Tokens are single-use
+	os.WriteFile(readme, []byte("changed again"), 0644)
+	if rr := do("/api/repos/1/discard", DiscardRequest{File: "README.md", Confirm: resp.Token}); rr.Code != http.StatusPreconditionFailed {
+		t.Errorf("Expected 412 for reused token, got %v", rr.Code)
+	}
+	if rr := do("/api/repos/1/discard", DiscardRequest{File: "README.md", Source: "stash"}); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for invalid source, got %v", rr.Code)
+	}
+
+	// Hunk selections are confirmed the same way
+	resp = confirmation(do("/api/repos/1/discard", DiscardRequest{File: "README.md", Hunks: []int{0}}))
+	if resp.Action != "discard-hunks" {
+		t.Errorf("Unexpected action %q", resp.Action)
+	}
+	if rr := do("/api/repos/1/discard", DiscardRequest{File: "README.md", Hunks: []int{0}, Confirm: resp.Token}); rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200 for confirmed hunk discard, got %v %s", rr.Code, rr.Body.String())
+	}
+	if data, _ := os.ReadFile(readme); string(data) != "Initial" {
+		t.Errorf("Expected committed content, got %q", data)
+	}
+}
+
+func TestHandleCleanAndResetHard(t *testing.T) {
+	server, configDir := setupTestServer(t)
+	defer os.RemoveAll(configDir)
+
+	tmpDir, repoPath := setupRepoForTest(t)
+	defer os.RemoveAll(tmpDir)
+
+	repo := models.Repository{ID: "1", Name: "Test", Path: repoPath}
+	server.store.SaveRepositories([]models.Repository{repo})
+
+	do := func(path string, body any) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(body)
+		req, _ := http.NewRequest("POST", path, bytes.NewBuffer(data))
+		addAuth(t, req)
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	os.WriteFile(filepath.Join(repoPath, "untracked.txt"), []byte("u"), 0644)
+	os.WriteFile(filepath.Join(repoPath, "README.md"), []byte("changed"), 0644)
+
+	// Clean lists the files in the confirmation (a dry run)
+	rr := do("/api/repos/1/clean", CleanRequest{})
+	if rr.Code != http.StatusPreconditionRequired {
+		t.Fatalf("Expected 428, got %v", rr.Code)
+	}
+	var resp ConfirmationResponse
+	json.NewDecoder(rr.Body).Decode(&resp)
+	if len(resp.Files) != 1 || resp.Files[0] != "untracked.txt" {
+		t.Errorf("Unexpected clean preview: %+v", resp)
+	}
+	if _, err := os.Stat(filepath.Join(repoPath, "untracked.txt")); err != nil {
+		t.Fatal("Expected preview not to remove files")
+	}
+
+	// A token for one operation does not confirm another
+	if rr := do("/api/repos/1/reset-hard", ResetRequest{Confirm: resp.Token}); rr.Code != http.StatusPreconditionFailed {
+		t.Errorf("Expected 412 for token of another operation, got %v", rr.Code)
+	}
+
+	rr = do("/api/repos/1/clean", CleanRequest{})
+	json.NewDecoder(rr.Body).Decode(&resp)
+	rr = do("/api/repos/1/clean", CleanRequest{Confirm: resp.Token})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200 for confirmed clean, got %v %s", rr.Code, rr.Body.String())
+	}
+	if _, err := os.Stat(filepath.Join(repoPath, "untracked.txt")); !os.IsNotExist(err) {
+		t.Error("Expected untracked file to be removed")
+	}
+	if rr := do("/api/repos/1/clean", CleanRequest{}); rr.Code != http.StatusConflict {
+		t.Errorf("Expected 409 with nothing to clean, got %v", rr.Code)
+	}
+
+	rr = do("/api/repos/1/reset-hard", ResetRequest{})
+	if rr.Code != http.StatusPreconditionRequired {
+		t.Fatalf("Expected 428, got %v", rr.Code)
+	}
+	json.NewDecoder(rr.Body).Decode(&resp)
+	if len(resp.Files) != 1 || resp.Files[0] != "README.md" {
+		t.Errorf("Unexpected reset preview: %+v", resp)
+	}
+	if rr := do("/api/repos/1/reset-hard", ResetRequest{Confirm: resp.Token}); rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200 for confirmed reset, got %v %s", rr.Code, rr.Body.String())
+	}
+	if data, _ := os.ReadFile(filepath.Join(repoPath, "README.md")); string(data) != "Initial" {
+		t.Errorf("Expected committed content after reset, got %q", data)
+	}
+}
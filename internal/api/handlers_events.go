@@ -0,0 +1,165 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Gemini8532/gitwapp/internal/git"
+	"github.com/Gemini8532/gitwapp/internal/watcher"
+	"github.com/Gemini8532/gitwapp/pkg/models"
+)
+
+// eventKeepAlive is how often an idle event stream sends a comment, so that
+// proxies do not close the connection.
+const eventKeepAlive = 30 * time.Second
+
+// StatusEvent is the payload of a "status" server-sent event.
+type StatusEvent struct {
+	RepoID string      `json:"repo_id"`
+	Status *git.Status `json:"status,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// handleEvents streams repository status changes as server-sent events.
+// The current status of every repository (or only the one given by ?repo=)
+// is sent first, followed by a "status" event whenever a repository's
+// working tree, index or refs change.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	repoID := r.URL.Query().Get("repo")
+	slog.InfoContext(ctx, "Opening event stream", "repo", repoID)
+
+	wt, err := s.repoWatcher()
+	if err != nil {
+		slog.ErrorContext(ctx, "Event stream failed - unable to start file watcher", "error", err)
+		http.Error(w, "Failed to start file watcher", http.StatusServiceUnavailable)
+		return
+	}
+
+	repos, err := s.store.LoadRepositories()
+	if err != nil {
+		slog.ErrorContext(ctx, "Event stream failed - unable to load repositories", "error", err)
+		http.Error(w, "Failed to load repositories", http.StatusInternalServerError)
+		return
+	}
+
+	changes, cancel := wt.Subscribe()
+	defer cancel()
+
+	// Streams outlive the server's write timeout
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	for _, repo := range repos {
+		if repoID == "" || repo.ID == repoID {
+			writeStatusEvent(w, repo)
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		slog.ErrorContext(ctx, "Event stream failed - streaming unsupported", "error", err)
+		return
+	}
+
+	keepAlive := time.NewTicker(eventKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.InfoContext(ctx, "Event stream closed", "repo", repoID)
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case id, ok := <-changes:
+			if !ok {
+				return // Server shutting down
+			}
+			if repoID != "" && id != repoID {
+				continue
+			}
+			repo, err := s.getRepoByID(id)
+			if err != nil {
+				continue // Removed since the change was detected
+			}
+			writeStatusEvent(w, *repo)
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// writeStatusEvent writes the current status of a repository as a "status" event.
+func writeStatusEvent(w http.ResponseWriter, repo models.Repository) {
+	event := StatusEvent{RepoID: repo.ID}
+	status, err := git.GetStatus(repo.Path)
+	if err != nil {
+		event.Error = err.Error()
+	} else {
+		event.Status = status
+	}
+
+	data, _ := json.Marshal(event)
+	fmt.Fprintf(w, "event: status\ndata: %s\n\n", data)
+}
+
+// repoWatcher returns the file watcher, starting it and watching all tracked
+// repositories on first use.
+func (s *Server) repoWatcher() (*watcher.Watcher, error) {
+	s.watcherMu.Lock()
+	defer s.watcherMu.Unlock()
+
+	if s.watcher != nil {
+		return s.watcher, nil
+	}
+
+	repos, err := s.store.LoadRepositories()
+	if err != nil {
+		return nil, err
+	}
+
+	wt, err := watcher.New(watcher.DefaultDelay)
+	if err != nil {
+		return nil, err
+	}
+	for _, repo := range repos {
+		if err := wt.Watch(repo.ID, repo.Path); err != nil {
+			slog.Warn("Failed to watch repository", "id", repo.ID, "path", repo.Path, "error", err)
+		}
+	}
+
+	s.watcher = wt
+	return wt, nil
+}
+
+// watchRepo starts watching a newly tracked repository if the watcher is running.
+func (s *Server) watchRepo(repo models.Repository) {
+	s.watcherMu.Lock()
+	defer s.watcherMu.Unlock()
+
+	if s.watcher == nil {
+		return
+	}
+	if err := s.watcher.Watch(repo.ID, repo.Path); err != nil {
+		slog.Warn("Failed to watch repository", "id", repo.ID, "path", repo.Path, "error", err)
+	}
+}
+
+// unwatchRepo stops watching a repository that is no longer tracked.
+func (s *Server) unwatchRepo(id string) {
+	s.watcherMu.Lock()
+	defer s.watcherMu.Unlock()
+
+	if s.watcher != nil {
+		s.watcher.Unwatch(id)
+	}
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Gemini8532/gitwapp/internal/middleware"
+	"github.com/Gemini8532/gitwapp/pkg/models"
+)
+
+// readStatusEvent reads the next "status" event from a server-sent event stream.
+func readStatusEvent(t *testing.T, lines <-chan string) StatusEvent {
+	t.Helper()
+	var event StatusEvent
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				t.Fatal("Event stream ended")
+			}
+			if data, found := strings.CutPrefix(line, "data: "); found {
+				if err := json.Unmarshal([]byte(data), &event); err != nil {
+					t.Fatalf("Invalid event data %q: %v", data, err)
+				}
+				return event
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatal("Timed out waiting for event")
+		}
+	}
+}
+
+func TestHandleEvents(t *testing.T) {
+	server, configDir := setupTestServer(t)
+	defer os.RemoveAll(configDir)
+	defer server.Shutdown(t.Context())
+
+	tmpDir, repoPath := setupRepoForTest(t)
+	defer os.RemoveAll(tmpDir)
+
+	repo := models.Repository{ID: "1", Name: "Test", Path: repoPath}
+	server.store.SaveRepositories([]models.Repository{repo})
+
+	ts := httptest.NewServer(server.router)
+	defer ts.Close()
+
+	// EventSource clients pass the token as a query parameter
+	token, _ := middleware.GenerateToken("user1", "testuser")
+	req, _ := http.NewRequestWithContext(t.Context(), "GET", ts.URL+"/api/events?repo=1&access_token="+token, nil)
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to open event stream: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("Unexpected response: %v %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	// The current status is sent first
+	event := readStatusEvent(t, lines)
+	if event.RepoID != "1" || event.Status == nil || !event.Status.Clean {
+		t.Fatalf("Unexpected initial event: %+v", event)
+	}
+
+	os.WriteFile(filepath.Join(repoPath, "new.txt"), []byte("change"), 0644)
+
+	event = readStatusEvent(t, lines)
+	if event.RepoID != "1" || event.Status == nil || event.Status.Clean {
+		t.Errorf("Expected dirty status event, got %+v", event)
+	}
+}
@@ -1,23 +1,49 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"log/slog"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/Gemini8532/gitwapp/internal/git"
+	"github.com/Gemini8532/gitwapp/internal/middleware"
 	"github.com/Gemini8532/gitwapp/pkg/models"
 	"github.com/gorilla/mux"
 )
 
+const (
+	// statusWorkers bounds how many repositories are inspected concurrently
+	// when the repository list is enriched with status.
+	statusWorkers = 8
+	// statusTimeout bounds how long a single repository's status may take
+	// before it is reported as an error.
+	statusTimeout = 5 * time.Second
+)
+
+// RepoWithStatus is a repository together with its summary status, as
+// returned by GET /repos?include=status. StatusError is set instead of
+// Status when the status could not be determined.
+type RepoWithStatus struct {
+	models.Repository
+	Status      *git.Summary `json:"status,omitempty"`
+	StatusError string       `json:"status_error,omitempty"`
+}
+
 // handleGetRepos handles requests to retrieve all repositories. It is part of the public API.
+// With ?include=status every repository is enriched with its summary status.
 func (s *Server) handleGetRepos(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	slog.InfoContext(ctx, "Getting all repositories")
+	include := r.URL.Query().Get("include")
+	slog.InfoContext(ctx, "Getting all repositories", "include", include)
 	repos, err := s.store.LoadRepositories()
 	if err != nil {
 		slog.ErrorContext(ctx, "Get repos failed - unable to load repositories", "error", err)
@@ -25,13 +51,70 @@ func (s *Server) handleGetRepos(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// TODO: Maybe enrich with simple status (clean/dirty) if performance allows
-	// For now, just return the list
+	if include == "status" {
+		result := repoStatuses(ctx, repos)
+		slog.InfoContext(ctx, "Repositories with status retrieved successfully", "count", len(result))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+		return
+	}
+
 	slog.InfoContext(ctx, "Repositories retrieved successfully", "count", len(repos))
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(repos)
 }
 
+// repoStatuses computes the summary status of each repository using a
+// bounded pool of workers. Results keep the order of repos.
+func repoStatuses(ctx context.Context, repos []models.Repository) []RepoWithStatus {
+	result := make([]RepoWithStatus, len(repos))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(statusWorkers, len(repos)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				result[i] = RepoWithStatus{Repository: repos[i]}
+				if err := ctx.Err(); err != nil {
+					result[i].StatusError = err.Error()
+					continue
+				}
+				summary, err := repoSummary(ctx, repos[i].Path)
+				if err != nil {
+					slog.WarnContext(ctx, "Unable to get repository status", "id", repos[i].ID, "path", repos[i].Path, "error", err)
+					result[i].StatusError = err.Error()
+					continue
+				}
+				result[i].Status = summary
+			}
+		}()
+	}
+
+	for i := range repos {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return result
+}
+
+// repoSummary returns the summary status of a repository, giving up after
+// statusTimeout or when ctx is done so that a hanging path does not hold
+// up the other repositories.
+func repoSummary(ctx context.Context, path string) (*git.Summary, error) {
+	ctx, cancel := context.WithTimeout(ctx, statusTimeout)
+	defer cancel()
+
+	summary, err := git.GetSummaryContext(ctx, path)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil, errors.New("timed out getting status")
+	}
+	return summary, err
+}
+
 // handleRepoStatus handles requests for the detailed status of a single repository.
 // It is part of the public API.
 func (s *Server) handleRepoStatus(w http.ResponseWriter, r *http.Request) {
@@ -228,20 +311,26 @@ func (s *Server) handleGetFile(w http.ResponseWriter, r *http.Request) {
 	w.Write(content)
 }
 
-// handleGetDiff handles requests to get the diff of a specific file in a repository.
+// handleGetDiff handles requests to get the diff of a specific file in a
+// repository. The optional mode parameter selects "head" (the default),
+// "staged" or "unstaged" changes.
 func (s *Server) handleGetDiff(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	vars := mux.Vars(r)
 	id := vars["id"]
 	file := r.URL.Query().Get("file")
+	mode := r.URL.Query().Get("mode")
+	if mode == "" {
+		mode = git.DiffHead
+	}
 
 	if file == "" {
 		http.Error(w, "File parameter is required", http.StatusBadRequest)
 		return
 	}
 
-	slog.InfoContext(ctx, "Getting file diff", "id", id, "file", file)
+	slog.InfoContext(ctx, "Getting file diff", "id", id, "file", file, "mode", mode)
 
 	repo, err := s.getRepoByID(id)
 	if err != nil {
@@ -250,10 +339,10 @@ func (s *Server) handleGetDiff(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	diff, err := git.GetFileDiff(repo.Path, file)
+	diff, err := git.FileDiff(repo.Path, file, mode)
 	if err != nil {
-		slog.ErrorContext(ctx, "Get diff failed", "id", id, "file", file, "path", repo.Path, "error", err)
-		http.Error(w, "Failed to get diff: "+err.Error(), http.StatusInternalServerError)
+		slog.ErrorContext(ctx, "Get diff failed", "id", id, "file", file, "mode", mode, "path", repo.Path, "error", err)
+		http.Error(w, "Failed to get diff: "+err.Error(), gitErrorStatus(err))
 		return
 	}
 
@@ -263,10 +352,22 @@ func (s *Server) handleGetDiff(w http.ResponseWriter, r *http.Request) {
 
 // CommitRequest represents the request body for committing changes.
 type CommitRequest struct {
-	Message string `json:"message"`
+	Message    string         `json:"message"`               // May be empty when amending, to keep the message
+	Author     *git.Identity  `json:"author,omitempty"`      // Overrides the logged-in user as author
+	Amend      bool           `json:"amend,omitempty"`       // Replace the last commit
+	AllowEmpty bool           `json:"allow_empty,omitempty"` // Commit even if nothing is staged
+	CoAuthors  []git.Identity `json:"co_authors,omitempty"`  // Credited with Co-authored-by trailers
+}
+
+// CommitResponse is returned after a successful commit.
+type CommitResponse struct {
+	Hash string `json:"hash"`
 }
 
 // handleCommit handles requests to commit staged changes in a repository.
+// Commits are authored and committed by the logged-in user unless the
+// request names another author; requests on the internal API, and users
+// without an email, fall back to the git configuration.
 func (s *Server) handleCommit(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -280,13 +381,13 @@ func (s *Server) handleCommit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.Message == "" {
+	if req.Message == "" && !req.Amend {
 		slog.WarnContext(ctx, "Commit failed - commit message required", "id", id)
 		http.Error(w, "Commit message required", http.StatusBadRequest)
 		return
 	}
 
-	slog.InfoContext(ctx, "Committing changes", "id", id, "message", req.Message)
+	slog.InfoContext(ctx, "Committing changes", "id", id, "message", req.Message, "amend", req.Amend, "allow_empty", req.AllowEmpty)
 
 	repo, err := s.getRepoByID(id)
 	if err != nil {
@@ -295,14 +396,72 @@ func (s *Server) handleCommit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := git.Commit(repo.Path, req.Message); err != nil {
+	opts := git.CommitOptions{
+		Author:     req.Author,
+		Amend:      req.Amend,
+		AllowEmpty: req.AllowEmpty,
+		CoAuthors:  req.CoAuthors,
+	}
+	user, err := s.userIdentity(r)
+	if err != nil {
+		slog.ErrorContext(ctx, "Commit failed - unable to load users", "id", id, "error", err)
+		http.Error(w, "Failed to load users", http.StatusInternalServerError)
+		return
+	}
+	if user != nil {
+		// When amending without an explicit author the amended commit keeps
+		// its author, as with git commit --amend
+		opts.Committer = user
+		if opts.Author == nil && !req.Amend {
+			opts.Author = user
+		}
+	}
+
+	hash, err := git.Commit(repo.Path, req.Message, opts)
+	if err != nil {
 		slog.ErrorContext(ctx, "Commit failed", "id", id, "path", repo.Path, "error", err)
-		http.Error(w, "Failed to commit: "+err.Error(), http.StatusInternalServerError)
+		http.Error(w, "Failed to commit: "+err.Error(), gitErrorStatus(err))
 		return
 	}
 
-	slog.InfoContext(ctx, "Changes committed successfully", "id", id, "path", repo.Path)
-	w.WriteHeader(http.StatusOK)
+	slog.InfoContext(ctx, "Changes committed successfully", "id", id, "path", repo.Path, "hash", hash)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(CommitResponse{Hash: hash})
+}
+
+// userIdentity returns the commit identity of the logged-in user: the name
+// and email stored for them, with the username standing in for a missing
+// name. It returns nil for requests without a logged-in user and for users
+// without an email, whose commits use the git configuration instead.
+func (s *Server) userIdentity(r *http.Request) (*git.Identity, error) {
+	claims, ok := r.Context().Value("user").(*middleware.Claims)
+	if !ok {
+		return nil, nil
+	}
+
+	users, err := s.store.LoadUsers()
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		if u.ID != claims.UserID || u.Email == "" {
+			continue
+		}
+		identity := &git.Identity{Name: u.Name, Email: u.Email}
+		if identity.Name == "" {
+			identity.Name = u.Username
+		}
+		return identity, nil
+	}
+	return nil, nil
+}
+
+// RemoteRequest represents the optional request body for push and pull,
+// selecting the remote to use instead of the branch's upstream.
+type RemoteRequest struct {
+	Remote     string `json:"remote"`
+	Tags       bool   `json:"tags,omitempty"`       // Push only: also push all tags
+	Passphrase string `json:"passphrase,omitempty"` // Unlocks the server's SSH key, if it is encrypted
 }
 
 // handlePush handles requests to push committed changes to a remote repository.
@@ -312,7 +471,14 @@ func (s *Server) handlePush(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	slog.InfoContext(ctx, "Pushing changes", "id", id)
+	var req RemoteRequest
+	if err := decodeOptionalJSON(r, &req); err != nil {
+		slog.ErrorContext(ctx, "Failed to decode push request", "id", id, "error", err)
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	slog.InfoContext(ctx, "Pushing changes", "id", id, "remote", req.Remote, "tags", req.Tags)
 
 	repo, err := s.getRepoByID(id)
 	if err != nil {
@@ -321,9 +487,17 @@ func (s *Server) handlePush(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := git.Push(repo.Path); err != nil {
+	creds, err := s.repoCredentials(repo)
+	if err != nil {
+		slog.ErrorContext(ctx, "Push failed - unable to load credentials", "id", id, "error", err)
+		http.Error(w, "Failed to load credentials", http.StatusInternalServerError)
+		return
+	}
+	creds = withPassphrase(creds, req.Passphrase)
+
+	if err := git.Push(repo.Path, git.PushOptions{Remote: req.Remote, Tags: req.Tags, Auth: creds}); err != nil {
 		slog.ErrorContext(ctx, "Push failed", "id", id, "path", repo.Path, "error", err)
-		http.Error(w, "Failed to push: "+err.Error(), http.StatusInternalServerError)
+		http.Error(w, "Failed to push: "+err.Error(), gitErrorStatus(err))
 		return
 	}
 
@@ -332,13 +506,23 @@ func (s *Server) handlePush(w http.ResponseWriter, r *http.Request) {
 }
 
 // handlePull handles requests to pull changes from a remote repository.
+// With ?rebase=true local commits are rebased onto the upstream instead of
+// merged; conflicts then stop the rebase and are reported with status 409.
 func (s *Server) handlePull(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	slog.InfoContext(ctx, "Pulling changes", "id", id)
+	var req RemoteRequest
+	if err := decodeOptionalJSON(r, &req); err != nil {
+		slog.ErrorContext(ctx, "Failed to decode pull request", "id", id, "error", err)
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	rebase := r.URL.Query().Get("rebase") == "true"
+	slog.InfoContext(ctx, "Pulling changes", "id", id, "remote", req.Remote, "rebase", rebase)
 
 	repo, err := s.getRepoByID(id)
 	if err != nil {
@@ -347,9 +531,17 @@ func (s *Server) handlePull(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := git.Pull(repo.Path); err != nil {
+	creds, err := s.repoCredentials(repo)
+	if err != nil {
+		slog.ErrorContext(ctx, "Pull failed - unable to load credentials", "id", id, "error", err)
+		http.Error(w, "Failed to load credentials", http.StatusInternalServerError)
+		return
+	}
+	creds = withPassphrase(creds, req.Passphrase)
+
+	if err := git.Pull(repo.Path, git.PullOptions{Remote: req.Remote, Rebase: rebase, Auth: creds}); err != nil {
 		slog.ErrorContext(ctx, "Pull failed", "id", id, "path", repo.Path, "error", err)
-		http.Error(w, "Failed to pull: "+err.Error(), http.StatusInternalServerError)
+		http.Error(w, "Failed to pull: "+err.Error(), gitErrorStatus(err))
 		return
 	}
 
@@ -357,6 +549,78 @@ func (s *Server) handlePull(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+// FetchRequest represents the optional request body for fetching remotes.
+type FetchRequest struct {
+	Remote string `json:"remote"` // Optional, all remotes when empty
+	Prune  bool   `json:"prune"`
+	Tags   bool   `json:"tags"`
+
+	Passphrase string `json:"passphrase,omitempty"` // Unlocks the server's SSH key, if it is encrypted
+}
+
+// FetchResponse reports the per-remote fetch results and the refreshed
+// repository status.
+type FetchResponse struct {
+	Remotes []git.FetchResult `json:"remotes"`
+	Status  *git.Status       `json:"status"`
+}
+
+// handleFetch handles requests to fetch all remotes of a repository without
+// modifying the working tree.
+func (s *Server) handleFetch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req FetchRequest
+	if err := decodeOptionalJSON(r, &req); err != nil {
+		slog.ErrorContext(ctx, "Failed to decode fetch request", "id", id, "error", err)
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	slog.InfoContext(ctx, "Fetching remotes", "id", id, "remote", req.Remote, "prune", req.Prune, "tags", req.Tags)
+
+	repo, err := s.getRepoByID(id)
+	if err != nil {
+		slog.WarnContext(ctx, "Fetch failed - repository not found", "id", id)
+		http.Error(w, "Repository not found", http.StatusNotFound)
+		return
+	}
+
+	creds, err := s.repoCredentials(repo)
+	if err != nil {
+		slog.ErrorContext(ctx, "Fetch failed - unable to load credentials", "id", id, "error", err)
+		http.Error(w, "Failed to load credentials", http.StatusInternalServerError)
+		return
+	}
+	creds = withPassphrase(creds, req.Passphrase)
+
+	results, err := git.Fetch(repo.Path, git.FetchOptions{Remote: req.Remote, Prune: req.Prune, Tags: req.Tags, Auth: creds})
+	if err != nil {
+		slog.ErrorContext(ctx, "Fetch failed", "id", id, "path", repo.Path, "error", err)
+		http.Error(w, "Failed to fetch: "+err.Error(), gitErrorStatus(err))
+		return
+	}
+	for _, res := range results {
+		if res.Error != "" {
+			slog.WarnContext(ctx, "Fetch failed for remote", "id", id, "remote", res.Remote, "error", res.Error)
+		}
+	}
+
+	status, err := git.GetStatus(repo.Path)
+	if err != nil {
+		slog.ErrorContext(ctx, "Fetch failed - unable to get git status", "id", id, "path", repo.Path, "error", err)
+		http.Error(w, "Failed to get git status: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	slog.InfoContext(ctx, "Remotes fetched successfully", "id", id, "path", repo.Path, "remotes", len(results))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(FetchResponse{Remotes: results, Status: status})
+}
+
 // getRepoByID is a helper function to find a repository by its ID.
 func (s *Server) getRepoByID(id string) (*models.Repository, error) {
 	repos, err := s.store.LoadRepositories()
@@ -370,3 +634,66 @@ func (s *Server) getRepoByID(id string) (*models.Repository, error) {
 	}
 	return nil, http.ErrMissingFile // Just a sentinel error
 }
+
+// decodeOptionalJSON decodes a JSON request body into v, leaving v untouched
+// when the body is empty.
+func decodeOptionalJSON(r *http.Request, v any) error {
+	if r.Body == nil {
+		return nil
+	}
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
+
+// gitErrorStatus maps errors returned by the git package to HTTP status codes.
+func gitErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, git.ErrRefNotFound),
+		errors.Is(err, git.ErrBranchNotFound),
+		errors.Is(err, git.ErrRemoteNotFound),
+		errors.Is(err, git.ErrNotConflicted),
+		errors.Is(err, git.ErrStashNotFound),
+		errors.Is(err, git.ErrTagNotFound),
+		errors.Is(err, git.ErrPathNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, git.ErrBranchExists),
+		errors.Is(err, git.ErrBranchCheckedOut),
+		errors.Is(err, git.ErrBranchNotMerged),
+		errors.Is(err, git.ErrRemoteExists),
+		errors.Is(err, git.ErrUnknownHostKey),
+		errors.Is(err, git.ErrHostKeyMismatch),
+		errors.Is(err, git.ErrNotFastForward),
+		errors.Is(err, git.ErrOperationInProgress),
+		errors.Is(err, git.ErrLocalChanges),
+		errors.Is(err, git.ErrNoOperation),
+		errors.Is(err, git.ErrUnresolvedConflicts),
+		errors.Is(err, git.ErrRebaseConflict),
+		errors.Is(err, git.ErrNothingToStash),
+		errors.Is(err, git.ErrTagExists),
+		errors.Is(err, git.ErrNoMergeBase),
+		errors.Is(err, git.ErrNothingToCommit):
+		return http.StatusConflict
+	case errors.Is(err, git.ErrInvalidBranchName),
+		errors.Is(err, git.ErrInvalidParent),
+		errors.Is(err, git.ErrInvalidRemote),
+		errors.Is(err, git.ErrPassphraseRequired),
+		errors.Is(err, git.ErrInvalidStrategy),
+		errors.Is(err, git.ErrInvalidSide),
+		errors.Is(err, git.ErrInvalidTagName),
+		errors.Is(err, git.ErrInvalidSelection),
+		errors.Is(err, git.ErrInvalidDiffMode),
+		errors.Is(err, git.ErrInvalidSource),
+		errors.Is(err, git.ErrNotDirectory),
+		errors.Is(err, git.ErrNotFile),
+		errors.Is(err, git.ErrInvalidPattern),
+		errors.Is(err, git.ErrMissingIdentity),
+		errors.Is(err, git.ErrInvalidIdentity):
+		return http.StatusBadRequest
+	case errors.Is(err, git.ErrBlobTooLarge):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -95,6 +95,7 @@ func TestHandleStageAndCommit(t *testing.T) {
 
 	repo := models.Repository{ID: "1", Name: "Test", Path: repoPath}
 	server.store.SaveRepositories([]models.Repository{repo})
+	server.store.SaveUsers([]models.User{{ID: "user1", Username: "testuser", Email: "testuser@example.com"}})
 
 	filename := "newfile.txt"
 	os.WriteFile(filepath.Join(repoPath, filename), []byte("content"), 0644)
@@ -137,3 +138,75 @@ func TestHandleStageAndCommit(t *testing.T) {
 		t.Error("Expected repo to be clean after commit")
 	}
 }
+
+func TestHandleFetch(t *testing.T) {
+	server, configDir := setupTestServer(t)
+	defer os.RemoveAll(configDir)
+
+	tmpDir, repoPath := setupRepoForTest(t)
+	defer os.RemoveAll(tmpDir)
+
+	// Clone the test repo so that it has an origin remote
+	clonePath := filepath.Join(tmpDir, "clone")
+	if _, err := git2.PlainClone(clonePath, false, &git2.CloneOptions{URL: repoPath}); err != nil {
+		t.Fatalf("Failed to clone repo: %v", err)
+	}
+
+	repo := models.Repository{ID: "1", Name: "Test", Path: clonePath}
+	server.store.SaveRepositories([]models.Repository{repo})
+
+	req, _ := http.NewRequest("POST", "/api/repos/1/fetch", nil)
+	addAuth(t, req)
+	rr := httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Fetch failed: %v %s", rr.Code, rr.Body.String())
+	}
+
+	var resp FetchResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(resp.Remotes) != 1 || resp.Remotes[0].Error != "" {
+		t.Errorf("Unexpected fetch results: %+v", resp.Remotes)
+	}
+	if resp.Status == nil || resp.Status.Branch != "master" {
+		t.Errorf("Expected refreshed status, got %+v", resp.Status)
+	}
+}
+
+func TestHandleGetReposWithStatus(t *testing.T) {
+	server, configDir := setupTestServer(t)
+	defer os.RemoveAll(configDir)
+
+	tmpDir, repoPath := setupRepoForTest(t)
+	defer os.RemoveAll(tmpDir)
+	os.WriteFile(filepath.Join(repoPath, "dirty.txt"), []byte("dirty"), 0644)
+
+	// A broken path must not prevent the others from being reported
+	server.store.SaveRepositories([]models.Repository{
+		{ID: "1", Name: "Good", Path: repoPath},
+		{ID: "2", Name: "Broken", Path: filepath.Join(tmpDir, "missing")},
+	})
+
+	req, _ := http.NewRequest("GET", "/api/repos?include=status", nil)
+	addAuth(t, req)
+	rr := httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Get repos failed: %v %s", rr.Code, rr.Body.String())
+	}
+
+	var repos []RepoWithStatus
+	json.NewDecoder(rr.Body).Decode(&repos)
+	if len(repos) != 2 || repos[0].ID != "1" || repos[1].ID != "2" {
+		t.Fatalf("Unexpected repositories: %+v", repos)
+	}
+	if repos[0].Status == nil || repos[0].Status.Clean || repos[0].Status.Untracked != 1 || repos[0].Status.LastCommit == nil {
+		t.Errorf("Unexpected status for good repo: %+v", repos[0].Status)
+	}
+	if repos[1].Status != nil || repos[1].StatusError == "" {
+		t.Errorf("Expected status error for broken repo, got %+v", repos[1])
+	}
+}
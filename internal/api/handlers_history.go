@@ -0,0 +1,58 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/Gemini8532/gitwapp/internal/git"
+	"github.com/gorilla/mux"
+)
+
+// handleGetFileHistory handles requests for the paginated history of a
+// single file, following renames. Supported query parameters: file
+// (required), ref, skip and limit.
+func (s *Server) handleGetFileHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+	q := r.URL.Query()
+	file := q.Get("file")
+
+	if file == "" {
+		http.Error(w, "File parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	opts := git.HistoryOptions{Ref: q.Get("ref")}
+	var err error
+	if opts.Skip, err = parseIntParam(q.Get("skip")); err != nil {
+		http.Error(w, "Invalid skip parameter", http.StatusBadRequest)
+		return
+	}
+	if opts.Limit, err = parseIntParam(q.Get("limit")); err != nil {
+		http.Error(w, "Invalid limit parameter", http.StatusBadRequest)
+		return
+	}
+
+	slog.InfoContext(ctx, "Getting file history", "id", id, "file", file, "ref", opts.Ref, "skip", opts.Skip, "limit", opts.Limit)
+
+	repo, err := s.getRepoByID(id)
+	if err != nil {
+		slog.WarnContext(ctx, "Get file history failed - repository not found", "id", id)
+		http.Error(w, "Repository not found", http.StatusNotFound)
+		return
+	}
+
+	page, err := git.FileHistory(repo.Path, file, opts)
+	if err != nil {
+		slog.ErrorContext(ctx, "Get file history failed", "id", id, "file", file, "path", repo.Path, "error", err)
+		http.Error(w, "Failed to get file history: "+err.Error(), gitErrorStatus(err))
+		return
+	}
+
+	slog.InfoContext(ctx, "File history retrieved successfully", "id", id, "file", file, "count", len(page.Revisions))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/Gemini8532/gitwapp/internal/git"
+	"github.com/Gemini8532/gitwapp/pkg/models"
+)
+
+func TestHandleGetFileHistory(t *testing.T) {
+	server, configDir := setupTestServer(t)
+	defer os.RemoveAll(configDir)
+
+	tmpDir, repoPath := setupRepoForTest(t)
+	defer os.RemoveAll(tmpDir)
+
+	repo := models.Repository{ID: "1", Name: "Test", Path: repoPath}
+	server.store.SaveRepositories([]models.Repository{repo})
+
+	writeAndCommit(t, repoPath, "notes.txt", "notes\n", "Add notes")
+	gitCmd(t, repoPath, "mv", "notes.txt", "NOTES.txt")
+	gitCmd(t, repoPath, "commit", "-m", "Rename notes")
+
+	get := func(query string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/api/repos/1/history"+query, nil)
+		addAuth(t, req)
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := get("?file=NOTES.txt")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %v %s", rr.Code, rr.Body.String())
+	}
+	var page git.FileHistoryPage
+	if err := json.NewDecoder(rr.Body).Decode(&page); err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Revisions) != 2 || page.Revisions[0].Change.Status != git.ChangeRenamed || page.Revisions[1].Change.Path != "notes.txt" {
+		t.Errorf("Expected history across the rename, got %+v", page.Revisions)
+	}
+
+	if rr := get(""); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without file, got %v", rr.Code)
+	}
+	if rr := get("?file=NOTES.txt&limit=-1"); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for invalid limit, got %v", rr.Code)
+	}
+	if rr := get("?file=missing.txt"); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a file without history, got %v", rr.Code)
+	}
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/Gemini8532/gitwapp/internal/git"
+	"github.com/gorilla/mux"
+)
+
+// HunkRequest represents the request body for staging or unstaging part of
+// a file: whole hunks by index and individual lines by their line number in
+// the diff text.
+type HunkRequest struct {
+	File  string          `json:"file"`
+	Hunks []int           `json:"hunks"`
+	Lines []git.LineRange `json:"lines"`
+}
+
+// handleStageHunks handles requests to stage selected hunks or lines of the
+// unstaged diff of a file.
+func (s *Server) handleStageHunks(w http.ResponseWriter, r *http.Request) {
+	s.handleHunks(w, r, "stage", git.StageHunks)
+}
+
+// handleUnstageHunks handles requests to unstage selected hunks or lines of
+// the staged diff of a file.
+func (s *Server) handleUnstageHunks(w http.ResponseWriter, r *http.Request) {
+	s.handleHunks(w, r, "unstage", git.UnstageHunks)
+}
+
+// handleHunks decodes a hunk selection and applies op to it.
+func (s *Server) handleHunks(w http.ResponseWriter, r *http.Request, action string, op func(path string, file string, sel git.HunkSelection) error) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req HunkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.ErrorContext(ctx, "Failed to decode hunk request", "id", id, "action", action, "error", err)
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	slog.InfoContext(ctx, "Updating hunks", "id", id, "action", action, "file", req.File, "hunks", len(req.Hunks), "lines", len(req.Lines))
+
+	if req.File == "" {
+		slog.WarnContext(ctx, "Update hunks failed - file is required", "id", id, "action", action)
+		http.Error(w, "File is required", http.StatusBadRequest)
+		return
+	}
+
+	repo, err := s.getRepoByID(id)
+	if err != nil {
+		slog.WarnContext(ctx, "Update hunks failed - repository not found", "id", id, "action", action)
+		http.Error(w, "Repository not found", http.StatusNotFound)
+		return
+	}
+
+	if err := op(repo.Path, req.File, git.HunkSelection{Hunks: req.Hunks, Lines: req.Lines}); err != nil {
+		slog.ErrorContext(ctx, "Update hunks failed", "id", id, "action", action, "file", req.File, "path", repo.Path, "error", err)
+		http.Error(w, "Failed to "+action+" hunks: "+err.Error(), gitErrorStatus(err))
+		return
+	}
+
+	slog.InfoContext(ctx, "Hunks updated successfully", "id", id, "action", action, "file", req.File)
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Gemini8532/gitwapp/internal/git"
+	"github.com/Gemini8532/gitwapp/pkg/models"
+)
+
+func TestHandleStageHunks(t *testing.T) {
+	server, configDir := setupTestServer(t)
+	defer os.RemoveAll(configDir)
+
+	tmpDir, repoPath := setupRepoForTest(t)
+	defer os.RemoveAll(tmpDir)
+
+	repo := models.Repository{ID: "1", Name: "Test", Path: repoPath}
+	server.store.SaveRepositories([]models.Repository{repo})
+
+	original := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\nn\n"
+	writeAndCommit(t, repoPath, "file.txt", original, "Add file")
+	changed := strings.Replace(strings.Replace(original, "b\n", "B\n", 1), "m\n", "M\n", 1)
+	os.WriteFile(filepath.Join(repoPath, "file.txt"), []byte(changed), 0644)
+
+	do := func(method, path string, body []byte) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(body))
+		addAuth(t, req)
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, req)
+		return rr
+	}
+	diff := func(mode string) string {
+		d, err := git.FileDiff(repoPath, "file.txt", mode)
+		if err != nil {
+			t.Fatalf("Failed to get %s diff: %v", mode, err)
+		}
+		return d
+	}
+
+	body, _ := json.Marshal(HunkRequest{File: "file.txt", Hunks: []int{0}})
+	if rr := do("POST", "/api/repos/1/stage-hunks", body); rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %v %s", rr.Code, rr.Body.String())
+	}
+	if staged := diff(git.DiffStaged); !strings.Contains(staged, "+B") || strings.Contains(staged, "+M") {
+		t.Errorf("Expected only the first hunk staged:\n%s", staged)
+	}
+	if unstaged := diff(git.DiffUnstaged); !strings.Contains(unstaged, "+M") || strings.Contains(unstaged, "+B") {
+		t.Errorf("Expected the second hunk to remain unstaged:\n%s", unstaged)
+	}
+
+	if rr := do("POST", "/api/repos/1/unstage-hunks", body); rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %v %s", rr.Code, rr.Body.String())
+	}
+	if staged := diff(git.DiffStaged); staged != "" {
+		t.Errorf("Expected nothing staged, got:\n%s", staged)
+	}
+
+	body, _ = json.Marshal(HunkRequest{File: "file.txt", Hunks: []int{5}})
+	if rr := do("POST", "/api/repos/1/stage-hunks", body); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for unknown hunk, got %v", rr.Code)
+	}
+	body, _ = json.Marshal(HunkRequest{Hunks: []int{0}})
+	if rr := do("POST", "/api/repos/1/stage-hunks", body); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without file, got %v", rr.Code)
+	}
+}
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Gemini8532/gitwapp/internal/git"
+	"github.com/gorilla/mux"
+)
+
+// handleGetLog handles requests for the paginated commit history of a repository.
+// Supported query parameters: ref, author, path, since, until (RFC 3339),
+// grep, skip and limit.
+func (s *Server) handleGetLog(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+	q := r.URL.Query()
+
+	opts := git.LogOptions{
+		Ref:    q.Get("ref"),
+		Author: q.Get("author"),
+		Path:   q.Get("path"),
+		Grep:   q.Get("grep"),
+	}
+
+	var err error
+	if opts.Since, err = parseTimeParam(q.Get("since")); err != nil {
+		http.Error(w, "Invalid since parameter", http.StatusBadRequest)
+		return
+	}
+	if opts.Until, err = parseTimeParam(q.Get("until")); err != nil {
+		http.Error(w, "Invalid until parameter", http.StatusBadRequest)
+		return
+	}
+	if opts.Skip, err = parseIntParam(q.Get("skip")); err != nil {
+		http.Error(w, "Invalid skip parameter", http.StatusBadRequest)
+		return
+	}
+	if opts.Limit, err = parseIntParam(q.Get("limit")); err != nil {
+		http.Error(w, "Invalid limit parameter", http.StatusBadRequest)
+		return
+	}
+
+	slog.InfoContext(ctx, "Getting commit log", "id", id, "ref", opts.Ref, "skip", opts.Skip, "limit", opts.Limit)
+
+	repo, err := s.getRepoByID(id)
+	if err != nil {
+		slog.WarnContext(ctx, "Get log failed - repository not found", "id", id)
+		http.Error(w, "Repository not found", http.StatusNotFound)
+		return
+	}
+
+	page, err := git.Log(repo.Path, opts)
+	if err != nil {
+		if errors.Is(err, git.ErrRefNotFound) {
+			slog.WarnContext(ctx, "Get log failed - reference not found", "id", id, "ref", opts.Ref)
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		slog.ErrorContext(ctx, "Get log failed", "id", id, "path", repo.Path, "error", err)
+		http.Error(w, "Failed to get log: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	slog.InfoContext(ctx, "Commit log retrieved successfully", "id", id, "count", len(page.Commits))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// parseTimeParam parses an optional RFC 3339 query parameter.
+func parseTimeParam(v string) (*time.Time, error) {
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// parseIntParam parses an optional non-negative integer query parameter.
+func parseIntParam(v string) (int, error) {
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		return 0, errors.New("must not be negative")
+	}
+	return n, nil
+}
+
+// handleGetCommit handles requests for the metadata and full diff of a single commit.
+// The optional parent query parameter selects which parent of a merge commit to
+// diff against (0 = first parent).
+func (s *Server) handleGetCommit(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+	hash := vars["hash"]
+
+	parent, err := parseIntParam(r.URL.Query().Get("parent"))
+	if err != nil {
+		http.Error(w, "Invalid parent parameter", http.StatusBadRequest)
+		return
+	}
+
+	slog.InfoContext(ctx, "Getting commit", "id", id, "hash", hash, "parent", parent)
+
+	repo, err := s.getRepoByID(id)
+	if err != nil {
+		slog.WarnContext(ctx, "Get commit failed - repository not found", "id", id)
+		http.Error(w, "Repository not found", http.StatusNotFound)
+		return
+	}
+
+	detail, err := git.GetCommit(repo.Path, hash, parent)
+	if err != nil {
+		if errors.Is(err, git.ErrRefNotFound) {
+			slog.WarnContext(ctx, "Get commit failed - commit not found", "id", id, "hash", hash)
+			http.Error(w, "Commit not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, git.ErrInvalidParent) {
+			slog.WarnContext(ctx, "Get commit failed - invalid parent", "id", id, "hash", hash, "parent", parent)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		slog.ErrorContext(ctx, "Get commit failed", "id", id, "hash", hash, "path", repo.Path, "error", err)
+		http.Error(w, "Failed to get commit: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	slog.InfoContext(ctx, "Commit retrieved successfully", "id", id, "hash", detail.Hash, "files", len(detail.Files))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(detail)
+}
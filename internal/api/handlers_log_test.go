@@ -0,0 +1,94 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/Gemini8532/gitwapp/internal/git"
+	"github.com/Gemini8532/gitwapp/pkg/models"
+)
+
+func TestHandleGetLog(t *testing.T) {
+	server, configDir := setupTestServer(t)
+	defer os.RemoveAll(configDir)
+
+	tmpDir, repoPath := setupRepoForTest(t)
+	defer os.RemoveAll(tmpDir)
+
+	repo := models.Repository{ID: "1", Name: "Test", Path: repoPath}
+	server.store.SaveRepositories([]models.Repository{repo})
+
+	req, _ := http.NewRequest("GET", "/api/repos/1/log?limit=10", nil)
+	addAuth(t, req)
+	rr := httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200 OK, got %v: %s", rr.Code, rr.Body.String())
+	}
+
+	var page git.LogPage
+	if err := json.NewDecoder(rr.Body).Decode(&page); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(page.Commits) != 1 || page.Commits[0].Summary != "Initial commit" {
+		t.Errorf("Unexpected log: %+v", page.Commits)
+	}
+
+	// Invalid date filter
+	req, _ = http.NewRequest("GET", "/api/repos/1/log?since=yesterday", nil)
+	addAuth(t, req)
+	rr = httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for invalid since, got %v", rr.Code)
+	}
+
+	// Unknown ref
+	req, _ = http.NewRequest("GET", "/api/repos/1/log?ref=missing", nil)
+	addAuth(t, req)
+	rr = httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown ref, got %v", rr.Code)
+	}
+}
+
+func TestHandleGetCommit(t *testing.T) {
+	server, configDir := setupTestServer(t)
+	defer os.RemoveAll(configDir)
+
+	tmpDir, repoPath := setupRepoForTest(t)
+	defer os.RemoveAll(tmpDir)
+
+	repo := models.Repository{ID: "1", Name: "Test", Path: repoPath}
+	server.store.SaveRepositories([]models.Repository{repo})
+
+	req, _ := http.NewRequest("GET", "/api/repos/1/commits/HEAD", nil)
+	addAuth(t, req)
+	rr := httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200 OK, got %v: %s", rr.Code, rr.Body.String())
+	}
+
+	var detail git.CommitDetail
+	if err := json.NewDecoder(rr.Body).Decode(&detail); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(detail.Files) != 1 || detail.Files[0].Path != "README.md" {
+		t.Errorf("Unexpected files: %+v", detail.Files)
+	}
+
+	req, _ = http.NewRequest("GET", "/api/repos/1/commits/0123456789abcdef0123456789abcdef01234567", nil)
+	addAuth(t, req)
+	rr = httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown commit, got %v", rr.Code)
+	}
+}
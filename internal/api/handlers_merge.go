@@ -0,0 +1,66 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/Gemini8532/gitwapp/internal/git"
+	"github.com/gorilla/mux"
+)
+
+// MergeRequest represents the request body for merging a ref into the
+// current branch.
+type MergeRequest struct {
+	Ref      string `json:"ref"`
+	Strategy string `json:"strategy"` // "ff-only", "no-ff" or "squash"; fast-forward when possible if empty
+	Message  string `json:"message"`  // Optional merge commit message
+}
+
+// handleMerge handles requests to merge a branch, tag or commit into the
+// current branch. Conflicts are reported with status 409 and the list of
+// conflicted paths.
+func (s *Server) handleMerge(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req MergeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.ErrorContext(ctx, "Failed to decode merge request", "id", id, "error", err)
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	slog.InfoContext(ctx, "Merging", "id", id, "ref", req.Ref, "strategy", req.Strategy)
+
+	if req.Ref == "" {
+		slog.WarnContext(ctx, "Merge failed - ref is required", "id", id)
+		http.Error(w, "Ref is required", http.StatusBadRequest)
+		return
+	}
+
+	repo, err := s.getRepoByID(id)
+	if err != nil {
+		slog.WarnContext(ctx, "Merge failed - repository not found", "id", id)
+		http.Error(w, "Repository not found", http.StatusNotFound)
+		return
+	}
+
+	result, err := git.Merge(repo.Path, git.MergeOptions{Ref: req.Ref, Strategy: req.Strategy, Message: req.Message})
+	if err != nil {
+		slog.ErrorContext(ctx, "Merge failed", "id", id, "path", repo.Path, "ref", req.Ref, "error", err)
+		http.Error(w, "Failed to merge: "+err.Error(), gitErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if result.Status == git.MergeConflicted {
+		slog.WarnContext(ctx, "Merge stopped with conflicts", "id", id, "ref", req.Ref, "conflicts", len(result.Conflicts))
+		w.WriteHeader(http.StatusConflict)
+	} else {
+		slog.InfoContext(ctx, "Merged successfully", "id", id, "ref", req.Ref, "status", result.Status)
+	}
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/Gemini8532/gitwapp/internal/git"
+	"github.com/Gemini8532/gitwapp/pkg/models"
+)
+
+// gitCmd runs the git command-line tool in repoPath with a fixed identity.
+func gitCmd(t *testing.T, repoPath string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repoPath
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, output)
+	}
+	return string(output)
+}
+
+// writeAndCommit writes a file and commits it with the git command-line tool.
+func writeAndCommit(t *testing.T, repoPath, name, content, msg string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(repoPath, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitCmd(t, repoPath, "add", name)
+	gitCmd(t, repoPath, "commit", "-m", msg)
+}
+
+func TestHandleMerge(t *testing.T) {
+	server, configDir := setupTestServer(t)
+	defer os.RemoveAll(configDir)
+
+	tmpDir, repoPath := setupRepoForTest(t)
+	defer os.RemoveAll(tmpDir)
+	gitCmd(t, repoPath, "config", "user.name", "Test")
+	gitCmd(t, repoPath, "config", "user.email", "test@example.com")
+
+	repo := models.Repository{ID: "1", Name: "Test", Path: repoPath}
+	server.store.SaveRepositories([]models.Repository{repo})
+
+	gitCmd(t, repoPath, "checkout", "-b", "feature")
+	writeAndCommit(t, repoPath, "README.md", "theirs\n", "Theirs")
+	gitCmd(t, repoPath, "checkout", "master")
+	writeAndCommit(t, repoPath, "README.md", "ours\n", "Ours")
+
+	body, _ := json.Marshal(MergeRequest{Ref: "feature", Strategy: "bogus"})
+	req, _ := http.NewRequest("POST", "/api/repos/1/merge", bytes.NewBuffer(body))
+	addAuth(t, req)
+	rr := httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for invalid strategy, got %v", rr.Code)
+	}
+
+	body, _ = json.Marshal(MergeRequest{Ref: "feature"})
+	req, _ = http.NewRequest("POST", "/api/repos/1/merge", bytes.NewBuffer(body))
+	addAuth(t, req)
+	rr = httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("Expected 409 for conflicting merge, got %v %s", rr.Code, rr.Body.String())
+	}
+
+	var result git.MergeResult
+	json.NewDecoder(rr.Body).Decode(&result)
+	if result.Status != git.MergeConflicted || len(result.Conflicts) != 1 || result.Conflicts[0].Path != "README.md" {
+		t.Errorf("Unexpected merge result: %+v", result)
+	}
+
+	status, _ := git.GetStatus(repoPath)
+	if status.State != git.StateMerging {
+		t.Errorf("Expected merging state, got %q", status.State)
+	}
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/Gemini8532/gitwapp/internal/git"
+	"github.com/gorilla/mux"
+)
+
+// RebaseRequest represents the request body for rebasing the current branch.
+type RebaseRequest struct {
+	Onto      string `json:"onto"`
+	Autostash bool   `json:"autostash"` // Stash local changes around the rebase
+}
+
+// handleRebase handles requests to rebase the current branch onto a branch,
+// tag or commit. A rebase stopped by conflicts is reported with status 409
+// and the list of conflicted paths.
+func (s *Server) handleRebase(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req RebaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.ErrorContext(ctx, "Failed to decode rebase request", "id", id, "error", err)
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	slog.InfoContext(ctx, "Rebasing", "id", id, "onto", req.Onto, "autostash", req.Autostash)
+
+	if req.Onto == "" {
+		slog.WarnContext(ctx, "Rebase failed - onto is required", "id", id)
+		http.Error(w, "Onto is required", http.StatusBadRequest)
+		return
+	}
+
+	repo, err := s.getRepoByID(id)
+	if err != nil {
+		slog.WarnContext(ctx, "Rebase failed - repository not found", "id", id)
+		http.Error(w, "Repository not found", http.StatusNotFound)
+		return
+	}
+
+	result, err := git.Rebase(repo.Path, git.RebaseOptions{Onto: req.Onto, Autostash: req.Autostash})
+	if err != nil {
+		slog.ErrorContext(ctx, "Rebase failed", "id", id, "path", repo.Path, "onto", req.Onto, "error", err)
+		http.Error(w, "Failed to rebase: "+err.Error(), gitErrorStatus(err))
+		return
+	}
+
+	writeRebaseResult(w, r, id, result)
+}
+
+// handleRebaseContinue handles requests to continue a stopped rebase once
+// the conflicts of the current commit are resolved.
+func (s *Server) handleRebaseContinue(w http.ResponseWriter, r *http.Request) {
+	s.handleRebaseStep(w, r, "continue", git.RebaseContinue)
+}
+
+// handleRebaseSkip handles requests to drop the commit a rebase stopped on
+// and continue with the next.
+func (s *Server) handleRebaseSkip(w http.ResponseWriter, r *http.Request) {
+	s.handleRebaseStep(w, r, "skip", git.RebaseSkip)
+}
+
+// handleRebaseAbort handles requests to abort a stopped rebase.
+func (s *Server) handleRebaseAbort(w http.ResponseWriter, r *http.Request) {
+	s.handleOperation(w, r, "abort rebase", git.RebaseAbort)
+}
+
+// handleRebaseStep applies step to a stopped rebase and responds with where
+// the rebase ended.
+func (s *Server) handleRebaseStep(w http.ResponseWriter, r *http.Request, action string, step func(path string) (*git.RebaseResult, error)) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	slog.InfoContext(ctx, "Updating rebase", "id", id, "action", action)
+
+	repo, err := s.getRepoByID(id)
+	if err != nil {
+		slog.WarnContext(ctx, "Update rebase failed - repository not found", "id", id, "action", action)
+		http.Error(w, "Repository not found", http.StatusNotFound)
+		return
+	}
+
+	result, err := step(repo.Path)
+	if err != nil {
+		slog.ErrorContext(ctx, "Update rebase failed", "id", id, "action", action, "path", repo.Path, "error", err)
+		http.Error(w, "Failed to "+action+" rebase: "+err.Error(), gitErrorStatus(err))
+		return
+	}
+
+	writeRebaseResult(w, r, id, result)
+}
+
+// writeRebaseResult writes a rebase result, with status 409 when the rebase
+// stopped on conflicts.
+func writeRebaseResult(w http.ResponseWriter, r *http.Request, id string, result *git.RebaseResult) {
+	ctx := r.Context()
+
+	w.Header().Set("Content-Type", "application/json")
+	if result.Status == git.RebaseConflicted {
+		slog.WarnContext(ctx, "Rebase stopped with conflicts", "id", id, "conflicts", len(result.Conflicts))
+		w.WriteHeader(http.StatusConflict)
+	} else {
+		slog.InfoContext(ctx, "Rebased successfully", "id", id, "status", result.Status, "commit", result.Commit)
+	}
+	json.NewEncoder(w).Encode(result)
+}
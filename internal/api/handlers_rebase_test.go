@@ -0,0 +1,155 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Gemini8532/gitwapp/internal/git"
+	"github.com/Gemini8532/gitwapp/pkg/models"
+)
+
+func TestHandleRebase(t *testing.T) {
+	server, configDir := setupTestServer(t)
+	defer os.RemoveAll(configDir)
+
+	tmpDir, repoPath := setupRepoForTest(t)
+	defer os.RemoveAll(tmpDir)
+	gitCmd(t, repoPath, "config", "user.name", "Test")
+	gitCmd(t, repoPath, "config", "user.email", "test@example.com")
+
+	repo := models.Repository{ID: "1", Name: "Test", Path: repoPath}
+	server.store.SaveRepositories([]models.Repository{repo})
+
+	gitCmd(t, repoPath, "checkout", "-b", "feature")
+	writeAndCommit(t, repoPath, "README.md", "feature\n", "Feature edit")
+	writeAndCommit(t, repoPath, "other.txt", "other\n", "Add other")
+	gitCmd(t, repoPath, "checkout", "master")
+	writeAndCommit(t, repoPath, "README.md", "main\n", "Main edit")
+	gitCmd(t, repoPath, "checkout", "feature")
+
+	post := func(path string, body []byte) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", path, bytes.NewBuffer(body))
+		addAuth(t, req)
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	body, _ := json.Marshal(RebaseRequest{})
+	if rr := post("/api/repos/1/rebase", body); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without onto, got %v", rr.Code)
+	}
+
+	body, _ = json.Marshal(RebaseRequest{Onto: "master"})
+	rr := post("/api/repos/1/rebase", body)
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("Expected 409 for conflicting rebase, got %v %s", rr.Code, rr.Body.String())
+	}
+	var result git.RebaseResult
+	json.NewDecoder(rr.Body).Decode(&result)
+	if result.Status != git.RebaseConflicted || len(result.Conflicts) != 1 || result.Conflicts[0].Path != "README.md" {
+		t.Errorf("Unexpected rebase result: %+v", result)
+	}
+
+	if rr := post("/api/repos/1/rebase/continue", nil); rr.Code != http.StatusConflict {
+		t.Errorf("Expected 409 while conflicts are unresolved, got %v", rr.Code)
+	}
+
+	rr = post("/api/repos/1/rebase/skip", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200 for skip, got %v %s", rr.Code, rr.Body.String())
+	}
+	json.NewDecoder(rr.Body).Decode(&result)
+	if result.Status != git.RebaseDone {
+		t.Errorf("Expected rebased after skip, got %+v", result)
+	}
+
+	if rr := post("/api/repos/1/rebase/abort", nil); rr.Code != http.StatusConflict {
+		t.Errorf("Expected 409 without a rebase in progress, got %v", rr.Code)
+	}
+	if rr := post("/api/repos/2/rebase", body); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown repository, got %v", rr.Code)
+	}
+}
+
+func TestHandlePullRebase(t *testing.T) {
+	server, configDir := setupTestServer(t)
+	defer os.RemoveAll(configDir)
+
+	tmpDir, remotePath := setupRepoForTest(t)
+	defer os.RemoveAll(tmpDir)
+	gitCmd(t, remotePath, "config", "user.name", "Test")
+	gitCmd(t, remotePath, "config", "user.email", "test@example.com")
+
+	clonePath := filepath.Join(tmpDir, "clone")
+	gitCmd(t, tmpDir, "clone", remotePath, clonePath)
+	gitCmd(t, clonePath, "config", "user.name", "Test")
+	gitCmd(t, clonePath, "config", "user.email", "test@example.com")
+
+	repo := models.Repository{ID: "1", Name: "Test", Path: clonePath}
+	server.store.SaveRepositories([]models.Repository{repo})
+
+	writeAndCommit(t, remotePath, "upstream.txt", "upstream\n", "Upstream work")
+	writeAndCommit(t, clonePath, "local.txt", "local\n", "Local work")
+
+	req, _ := http.NewRequest("POST", "/api/repos/1/pull?rebase=true", nil)
+	addAuth(t, req)
+	rr := httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200 for pull with rebase, got %v %s", rr.Code, rr.Body.String())
+	}
+
+	// Rebased rather than merged: HEAD is a single-parent commit on top of upstream
+	commits := strings.Fields(gitCmd(t, clonePath, "rev-list", "--parents", "-n", "1", "HEAD"))
+	upstream := strings.TrimSpace(gitCmd(t, remotePath, "rev-parse", "HEAD"))
+	if len(commits) != 2 || commits[1] != upstream {
+		t.Errorf("Expected local commit rebased onto %s, got %v", upstream, commits)
+	}
+
+	status, _ := git.GetStatus(clonePath)
+	if status.Ahead != 1 || status.Behind != 0 {
+		t.Errorf("Expected 1 ahead and 0 behind, got %+v", status)
+	}
+}
+
+func TestHandleContinueRebaseConflict(t *testing.T) {
+	server, configDir := setupTestServer(t)
+	defer os.RemoveAll(configDir)
+
+	tmpDir, repoPath := setupRepoForTest(t)
+	defer os.RemoveAll(tmpDir)
+	gitCmd(t, repoPath, "config", "user.name", "Test")
+	gitCmd(t, repoPath, "config", "user.email", "test@example.com")
+
+	repo := models.Repository{ID: "1", Name: "Test", Path: repoPath}
+	server.store.SaveRepositories([]models.Repository{repo})
+
+	gitCmd(t, repoPath, "checkout", "-b", "feature")
+	writeAndCommit(t, repoPath, "README.md", "feature 1\n", "Feature 1")
+	writeAndCommit(t, repoPath, "README.md", "feature 2\n", "Feature 2")
+	gitCmd(t, repoPath, "checkout", "master")
+	writeAndCommit(t, repoPath, "README.md", "main\n", "Main edit")
+	gitCmd(t, repoPath, "checkout", "feature")
+
+	if result, _ := git.Rebase(repoPath, git.RebaseOptions{Onto: "master"}); result.Status != git.RebaseConflicted {
+		t.Fatalf("Expected conflict, got %+v", result)
+	}
+	git.WriteResolution(repoPath, "README.md", []byte("resolved\n"))
+	git.MarkResolved(repoPath, "README.md")
+
+	// The second feature commit conflicts as well
+	req, _ := http.NewRequest("POST", "/api/repos/1/continue", nil)
+	addAuth(t, req)
+	rr := httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusConflict {
+		t.Errorf("Expected 409 when the rebase stops again, got %v %s", rr.Code, rr.Body.String())
+	}
+}
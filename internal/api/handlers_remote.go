@@ -0,0 +1,143 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/Gemini8532/gitwapp/internal/git"
+	"github.com/gorilla/mux"
+)
+
+// AddRemoteRequest represents the request body for adding a remote.
+type AddRemoteRequest struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// UpdateRemoteRequest represents the request body for changing a remote's URL.
+type UpdateRemoteRequest struct {
+	URL string `json:"url"`
+}
+
+// handleListRemotes handles requests to list the remotes of a repository.
+func (s *Server) handleListRemotes(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	slog.InfoContext(ctx, "Listing remotes", "id", id)
+
+	repo, err := s.getRepoByID(id)
+	if err != nil {
+		slog.WarnContext(ctx, "List remotes failed - repository not found", "id", id)
+		http.Error(w, "Repository not found", http.StatusNotFound)
+		return
+	}
+
+	remotes, err := git.ListRemotes(repo.Path)
+	if err != nil {
+		slog.ErrorContext(ctx, "List remotes failed", "id", id, "path", repo.Path, "error", err)
+		http.Error(w, "Failed to list remotes: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	slog.InfoContext(ctx, "Remotes listed successfully", "id", id, "count", len(remotes))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(remotes)
+}
+
+// handleAddRemote handles requests to add a remote to a repository.
+func (s *Server) handleAddRemote(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req AddRemoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.ErrorContext(ctx, "Failed to decode add remote request", "id", id, "error", err)
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	slog.InfoContext(ctx, "Adding remote", "id", id, "name", req.Name, "url", req.URL)
+
+	repo, err := s.getRepoByID(id)
+	if err != nil {
+		slog.WarnContext(ctx, "Add remote failed - repository not found", "id", id)
+		http.Error(w, "Repository not found", http.StatusNotFound)
+		return
+	}
+
+	if err := git.AddRemote(repo.Path, req.Name, req.URL); err != nil {
+		slog.ErrorContext(ctx, "Add remote failed", "id", id, "name", req.Name, "path", repo.Path, "error", err)
+		http.Error(w, "Failed to add remote: "+err.Error(), gitErrorStatus(err))
+		return
+	}
+
+	slog.InfoContext(ctx, "Remote added successfully", "id", id, "name", req.Name)
+	w.WriteHeader(http.StatusCreated)
+}
+
+// handleUpdateRemote handles requests to change the URL of a remote.
+func (s *Server) handleUpdateRemote(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+	name := vars["name"]
+
+	var req UpdateRemoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.ErrorContext(ctx, "Failed to decode update remote request", "id", id, "error", err)
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	slog.InfoContext(ctx, "Updating remote", "id", id, "name", name, "url", req.URL)
+
+	repo, err := s.getRepoByID(id)
+	if err != nil {
+		slog.WarnContext(ctx, "Update remote failed - repository not found", "id", id)
+		http.Error(w, "Repository not found", http.StatusNotFound)
+		return
+	}
+
+	if err := git.SetRemoteURL(repo.Path, name, req.URL); err != nil {
+		slog.ErrorContext(ctx, "Update remote failed", "id", id, "name", name, "path", repo.Path, "error", err)
+		http.Error(w, "Failed to update remote: "+err.Error(), gitErrorStatus(err))
+		return
+	}
+
+	slog.InfoContext(ctx, "Remote updated successfully", "id", id, "name", name)
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleRemoveRemote handles requests to remove a remote from a repository.
+func (s *Server) handleRemoveRemote(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+	name := vars["name"]
+
+	slog.InfoContext(ctx, "Removing remote", "id", id, "name", name)
+
+	repo, err := s.getRepoByID(id)
+	if err != nil {
+		slog.WarnContext(ctx, "Remove remote failed - repository not found", "id", id)
+		http.Error(w, "Repository not found", http.StatusNotFound)
+		return
+	}
+
+	if err := git.RemoveRemote(repo.Path, name); err != nil {
+		slog.ErrorContext(ctx, "Remove remote failed", "id", id, "name", name, "path", repo.Path, "error", err)
+		http.Error(w, "Failed to remove remote: "+err.Error(), gitErrorStatus(err))
+		return
+	}
+
+	slog.InfoContext(ctx, "Remote removed successfully", "id", id, "name", name)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/Gemini8532/gitwapp/internal/git"
+	"github.com/Gemini8532/gitwapp/pkg/models"
+)
+
+func TestHandleRemotes(t *testing.T) {
+	server, configDir := setupTestServer(t)
+	defer os.RemoveAll(configDir)
+
+	tmpDir, repoPath := setupRepoForTest(t)
+	defer os.RemoveAll(tmpDir)
+
+	repo := models.Repository{ID: "1", Name: "Test", Path: repoPath}
+	server.store.SaveRepositories([]models.Repository{repo})
+
+	body, _ := json.Marshal(AddRemoteRequest{Name: "upstream", URL: "https://example.com/repo.git"})
+	req, _ := http.NewRequest("POST", "/api/repos/1/remotes", bytes.NewBuffer(body))
+	addAuth(t, req)
+	rr := httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Add remote failed: %v %s", rr.Code, rr.Body.String())
+	}
+
+	body, _ = json.Marshal(UpdateRemoteRequest{URL: "https://example.com/moved.git"})
+	req, _ = http.NewRequest("PUT", "/api/repos/1/remotes/upstream", bytes.NewBuffer(body))
+	addAuth(t, req)
+	rr = httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Update remote failed: %v %s", rr.Code, rr.Body.String())
+	}
+
+	req, _ = http.NewRequest("GET", "/api/repos/1/remotes", nil)
+	addAuth(t, req)
+	rr = httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	var remotes []git.Remote
+	json.NewDecoder(rr.Body).Decode(&remotes)
+	if len(remotes) != 1 || remotes[0].URLs[0] != "https://example.com/moved.git" {
+		t.Errorf("Unexpected remotes: %+v", remotes)
+	}
+
+	req, _ = http.NewRequest("DELETE", "/api/repos/1/remotes/upstream", nil)
+	addAuth(t, req)
+	rr = httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNoContent {
+		t.Errorf("Remove remote failed: %v %s", rr.Code, rr.Body.String())
+	}
+
+	req, _ = http.NewRequest("DELETE", "/api/repos/1/remotes/upstream", nil)
+	addAuth(t, req)
+	rr = httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 removing missing remote, got %v", rr.Code)
+	}
+}
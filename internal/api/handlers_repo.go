@@ -117,6 +117,8 @@ func (s *Server) handleAddRepo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	s.watchRepo(newRepo)
+
 	slog.InfoContext(ctx, "Repository added successfully", "id", newRepo.ID, "path", newRepo.Path)
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(newRepo)
@@ -160,6 +162,11 @@ func (s *Server) handleRemoveRepo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if _, err := s.store.DeleteRepoToken(id); err != nil {
+		slog.WarnContext(ctx, "Remove repository - unable to delete stored token", "id", id, "error", err)
+	}
+	s.unwatchRepo(id)
+
 	slog.InfoContext(ctx, "Repository removed successfully", "id", id)
 	w.WriteHeader(http.StatusNoContent)
 }
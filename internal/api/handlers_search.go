@@ -0,0 +1,316 @@
+package api
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Gemini8532/gitwapp/internal/git"
+	"github.com/Gemini8532/gitwapp/pkg/models"
+	"github.com/gorilla/mux"
+)
+
+const (
+	// searchWorkers bounds how many repositories are searched concurrently.
+	searchWorkers = 4
+	// searchTimeout bounds how long searching a single repository may take
+	// before it is reported as an error.
+	searchTimeout = 10 * time.Second
+	// defaultSearchLimit is the number of hits returned per repository when
+	// no limit is given.
+	defaultSearchLimit = 20
+	// maxSearchLimit is the largest number of hits returned per repository.
+	maxSearchLimit = 100
+)
+
+// Kinds of search hits, also accepted by the types query parameter.
+const (
+	hitFile    = "file"
+	hitCommit  = "commit"
+	hitContent = "content"
+)
+
+// SearchHit is a single result of a cross-repository search: a file whose
+// path matches, a commit whose message matches, or a matching line.
+type SearchHit struct {
+	Kind    string          `json:"kind"`
+	Score   int             `json:"score"`
+	Path    string          `json:"path,omitempty"`
+	Line    int             `json:"line,omitempty"`
+	Text    string          `json:"text,omitempty"` // The matching line, or the commit summary
+	Matches []git.Range     `json:"matches,omitempty"`
+	Commit  *git.CommitInfo `json:"commit,omitempty"`
+}
+
+// RepoSearchResult holds the hits in one repository, best first. Error is
+// set when the repository could not be searched.
+type RepoSearchResult struct {
+	ID        string      `json:"id"`
+	Name      string      `json:"name"`
+	Score     int         `json:"score"` // Sum of the scores of the hits
+	Hits      []SearchHit `json:"hits"`
+	Truncated bool        `json:"truncated"`
+	Error     string      `json:"error,omitempty"`
+}
+
+// SearchResponse is the response of a cross-repository search. Repositories
+// with hits come first, best first; repositories without hits are left out.
+type SearchResponse struct {
+	Query   string             `json:"query"`
+	Results []RepoSearchResult `json:"results"`
+}
+
+// handleSearch handles requests to search all tracked repositories for file
+// names, commit messages and file contents containing a text, ignoring
+// case. Supported query parameters: q (required), types (comma-separated
+// "file", "commit" and "content", all by default) and limit per repository.
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	q := r.URL.Query()
+
+	query := strings.TrimSpace(q.Get("q"))
+	if query == "" {
+		http.Error(w, "Query parameter q is required", http.StatusBadRequest)
+		return
+	}
+
+	kinds := map[string]bool{hitFile: true, hitCommit: true, hitContent: true}
+	if v := q.Get("types"); v != "" {
+		kinds = make(map[string]bool)
+		for _, kind := range strings.Split(v, ",") {
+			switch kind = strings.TrimSpace(kind); kind {
+			case hitFile, hitCommit, hitContent:
+				kinds[kind] = true
+			default:
+				http.Error(w, "Invalid types parameter", http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
+	limit, err := parseIntParam(q.Get("limit"))
+	if err != nil {
+		http.Error(w, "Invalid limit parameter", http.StatusBadRequest)
+		return
+	}
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+	limit = min(limit, maxSearchLimit)
+
+	slog.InfoContext(ctx, "Searching all repositories", "query", query, "limit", limit)
+
+	repos, err := s.store.LoadRepositories()
+	if err != nil {
+		slog.ErrorContext(ctx, "Search failed - unable to load repositories", "error", err)
+		http.Error(w, "Failed to load repositories", http.StatusInternalServerError)
+		return
+	}
+
+	resp := SearchResponse{Query: query, Results: []RepoSearchResult{}}
+	for _, result := range searchRepos(ctx, repos, query, kinds, limit) {
+		if len(result.Hits) > 0 || result.Error != "" {
+			resp.Results = append(resp.Results, result)
+		}
+	}
+	slices.SortStableFunc(resp.Results, func(a, b RepoSearchResult) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
+
+	slog.InfoContext(ctx, "Repositories searched successfully", "query", query, "repositories", len(resp.Results))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// searchRepos searches each repository using a bounded pool of workers.
+// Results keep the order of repos. Once ctx is done, the remaining
+// repositories are not searched and report ctx's error.
+func searchRepos(ctx context.Context, repos []models.Repository, query string, kinds map[string]bool, limit int) []RepoSearchResult {
+	result := make([]RepoSearchResult, len(repos))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(searchWorkers, len(repos)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				result[i] = RepoSearchResult{ID: repos[i].ID, Name: repos[i].Name, Hits: []SearchHit{}}
+				if err := ctx.Err(); err != nil {
+					result[i].Error = err.Error()
+					continue
+				}
+				hits, truncated, err := searchRepoTimeout(ctx, repos[i].Path, query, kinds, limit)
+				if err != nil {
+					slog.WarnContext(ctx, "Unable to search repository", "id", repos[i].ID, "path", repos[i].Path, "error", err)
+					result[i].Error = err.Error()
+					continue
+				}
+				result[i].Hits, result[i].Truncated = hits, truncated
+				for _, hit := range hits {
+					result[i].Score += hit.Score
+				}
+			}
+		}()
+	}
+
+	for i := range repos {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return result
+}
+
+// searchRepoTimeout searches a repository, giving up after searchTimeout or
+// when ctx is done. Giving up stops the git commands of the search.
+func searchRepoTimeout(ctx context.Context, repoPath string, query string, kinds map[string]bool, limit int) ([]SearchHit, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, searchTimeout)
+	defer cancel()
+
+	hits, truncated, err := searchRepo(ctx, repoPath, query, kinds, limit)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil, false, errors.New("timed out searching repository")
+	}
+	return hits, truncated, err
+}
+
+// searchRepo searches one repository and returns its best hits, ranked by
+// score and reporting whether hits were dropped. Files score highest when
+// their name matches exactly, then by prefix, then anywhere in the path;
+// commits score higher when the summary matches than the body; matching
+// lines score lowest, slightly higher when the case matches too.
+func searchRepo(ctx context.Context, repoPath string, query string, kinds map[string]bool, limit int) ([]SearchHit, bool, error) {
+	var hits []SearchHit
+	truncated := false
+	lower := strings.ToLower(query)
+
+	if kinds[hitFile] {
+		files, err := git.FindFilesContext(ctx, repoPath, query)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, file := range files {
+			name := strings.ToLower(path.Base(file))
+			score := 20
+			switch {
+			case name == lower:
+				score = 100
+			case strings.HasPrefix(name, lower):
+				score = 60
+			case strings.Contains(name, lower):
+				score = 40
+			}
+			hits = append(hits, SearchHit{Kind: hitFile, Score: score, Path: file})
+		}
+	}
+
+	if kinds[hitCommit] {
+		page, err := git.LogContext(ctx, repoPath, git.LogOptions{Grep: query, Limit: limit})
+		switch {
+		case errors.Is(err, git.ErrRefNotFound):
+			// No commits yet
+			page = &git.LogPage{}
+		case err != nil:
+			return nil, false, err
+		}
+		truncated = truncated || page.HasMore
+		for _, c := range page.Commits {
+			score := 15
+			if strings.Contains(strings.ToLower(c.Summary), lower) {
+				score = 30
+			}
+			hits = append(hits, SearchHit{Kind: hitCommit, Score: score, Text: c.Summary, Commit: &c})
+		}
+	}
+
+	if kinds[hitContent] {
+		result, err := git.GrepContext(ctx, repoPath, git.GrepOptions{Pattern: query, IgnoreCase: true, Limit: limit})
+		if err != nil {
+			return nil, false, err
+		}
+		truncated = truncated || result.Truncated
+		for _, m := range result.Matches {
+			score := 10
+			if strings.Contains(m.Text, query) {
+				score = 15
+			}
+			hits = append(hits, SearchHit{Kind: hitContent, Score: score, Path: m.Path, Line: m.Line, Text: m.Text, Matches: m.Matches})
+		}
+	}
+
+	slices.SortStableFunc(hits, func(a, b SearchHit) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
+	if len(hits) > limit {
+		hits = hits[:limit]
+		truncated = true
+	}
+	if hits == nil {
+		hits = []SearchHit{}
+	}
+	return hits, truncated, nil
+}
+
+// handleGrep handles requests to search the contents of a repository's
+// tracked files. Supported query parameters: q (required), regex=true,
+// ignore_case=true, ref (the working tree by default), path (repeatable
+// pathspec, e.g. "*.go"), context and limit.
+func (s *Server) handleGrep(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+	q := r.URL.Query()
+
+	opts := git.GrepOptions{
+		Pattern:    q.Get("q"),
+		Regex:      q.Get("regex") == "true",
+		IgnoreCase: q.Get("ignore_case") == "true",
+		Ref:        q.Get("ref"),
+		Paths:      q["path"],
+	}
+	if opts.Pattern == "" {
+		http.Error(w, "Query parameter q is required", http.StatusBadRequest)
+		return
+	}
+
+	var err error
+	if opts.Context, err = parseIntParam(q.Get("context")); err != nil {
+		http.Error(w, "Invalid context parameter", http.StatusBadRequest)
+		return
+	}
+	if opts.Limit, err = parseIntParam(q.Get("limit")); err != nil {
+		http.Error(w, "Invalid limit parameter", http.StatusBadRequest)
+		return
+	}
+
+	slog.InfoContext(ctx, "Searching repository", "id", id, "pattern", opts.Pattern, "ref", opts.Ref, "regex", opts.Regex)
+
+	repo, err := s.getRepoByID(id)
+	if err != nil {
+		slog.WarnContext(ctx, "Search failed - repository not found", "id", id)
+		http.Error(w, "Repository not found", http.StatusNotFound)
+		return
+	}
+
+	result, err := git.GrepContext(ctx, repo.Path, opts)
+	if err != nil {
+		slog.ErrorContext(ctx, "Search failed", "id", id, "path", repo.Path, "error", err)
+		http.Error(w, "Failed to search: "+err.Error(), gitErrorStatus(err))
+		return
+	}
+
+	slog.InfoContext(ctx, "Repository searched successfully", "id", id, "matches", len(result.Matches), "truncated", result.Truncated)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
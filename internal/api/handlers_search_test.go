@@ -0,0 +1,174 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Gemini8532/gitwapp/internal/git"
+	"github.com/Gemini8532/gitwapp/pkg/models"
+)
+
+func TestHandleGrep(t *testing.T) {
+	server, configDir := setupTestServer(t)
+	defer os.RemoveAll(configDir)
+
+	tmpDir, repoPath := setupRepoForTest(t)
+	defer os.RemoveAll(tmpDir)
+
+	repo := models.Repository{ID: "1", Name: "Test", Path: repoPath}
+	server.store.SaveRepositories([]models.Repository{repo})
+
+	writeAndCommit(t, repoPath, "settings.json", "{\n  \"api_key\": \"x\"\n}\n", "Add settings")
+	writeAndCommit(t, repoPath, "notes.txt", "Rotate the API_KEY yearly\n", "Add notes")
+
+	get := func(query string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/api/repos/1/search"+query, nil)
+		addAuth(t, req)
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := get("?q=api_key&ignore_case=true&path=*.json&context=1")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %v %s", rr.Code, rr.Body.String())
+	}
+	var result git.GrepResult
+	if err := json.NewDecoder(rr.Body).Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Matches) != 1 || result.Matches[0].Path != "settings.json" || result.Matches[0].Line != 2 || len(result.Matches[0].Before) != 1 {
+		t.Errorf("Expected the settings match with context, got %+v", result.Matches)
+	}
+
+	rr = get("?q=API_KEY&ref=HEAD")
+	json.NewDecoder(rr.Body).Decode(&result)
+	if len(result.Matches) != 1 || result.Matches[0].Path != "notes.txt" {
+		t.Errorf("Expected a case-sensitive match in notes.txt, got %+v", result.Matches)
+	}
+
+	if rr := get(""); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without q, got %v", rr.Code)
+	}
+	if rr := get("?q=(&regex=true"); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid regex, got %v", rr.Code)
+	}
+	if rr := get("?q=x&ref=no-such-branch"); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown ref, got %v", rr.Code)
+	}
+
+	// git grep is not run for a client that has gone away
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", "/api/repos/1/search?q=api_key", nil)
+	addAuth(t, req)
+	rr = httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	if rr.Code == http.StatusOK {
+		t.Errorf("Expected the search to be cancelled, got %v %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestHandleSearch(t *testing.T) {
+	server, configDir := setupTestServer(t)
+	defer os.RemoveAll(configDir)
+
+	tmpA, repoA := setupRepoForTest(t)
+	defer os.RemoveAll(tmpA)
+	tmpB, repoB := setupRepoForTest(t)
+	defer os.RemoveAll(tmpB)
+	emptyRepo := filepath.Join(tmpB, "empty")
+	os.MkdirAll(emptyRepo, 0755)
+	gitCmd(t, emptyRepo, "init")
+
+	// Repository A has a file named after the query, B only mentions it
+	writeAndCommit(t, repoA, "timeout.go", "package main\n", "Add timeout handling")
+	writeAndCommit(t, repoB, "main.go", "package main\n\n// the Timeout is fixed\n", "Initial main")
+
+	server.store.SaveRepositories([]models.Repository{
+		{ID: "b", Name: "B", Path: repoB},
+		{ID: "a", Name: "A", Path: repoA},
+		{ID: "empty", Name: "Empty", Path: emptyRepo},
+		{ID: "gone", Name: "Gone", Path: filepath.Join(tmpB, "gone")},
+	})
+
+	get := func(query string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/api/search"+query, nil)
+		addAuth(t, req)
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := get("?q=timeout")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %v %s", rr.Code, rr.Body.String())
+	}
+	var resp SearchResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Results) != 3 {
+		t.Fatalf("Expected A, B and the failing repository, got %+v", resp.Results)
+	}
+
+	a, b, gone := resp.Results[0], resp.Results[1], resp.Results[2]
+	if a.ID != "a" || b.ID != "b" || a.Score <= b.Score {
+		t.Fatalf("Expected A ranked above B, got %s (%d) and %s (%d)", a.ID, a.Score, b.ID, b.Score)
+	}
+	if len(a.Hits) != 2 || a.Hits[0].Kind != hitFile || a.Hits[0].Path != "timeout.go" || a.Hits[1].Kind != hitCommit {
+		t.Errorf("Expected the file then the commit in A, got %+v", a.Hits)
+	}
+	if len(b.Hits) != 1 || b.Hits[0].Kind != hitContent || b.Hits[0].Line != 3 {
+		t.Errorf("Expected the matching line in B, got %+v", b.Hits)
+	}
+	if gone.ID != "gone" || gone.Error == "" {
+		t.Errorf("Expected an error for the missing repository, got %+v", gone)
+	}
+
+	// Restricting the kinds and the number of hits
+	rr = get("?q=timeout&types=commit,content&limit=1")
+	json.NewDecoder(rr.Body).Decode(&resp)
+	for _, result := range resp.Results {
+		if result.ID == "a" && (len(result.Hits) != 1 || result.Hits[0].Kind != hitCommit) {
+			t.Errorf("Expected only the commit in A, got %+v", result.Hits)
+		}
+	}
+
+	if rr := get(""); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without q, got %v", rr.Code)
+	}
+	if rr := get("?q=x&types=bogus"); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for invalid types, got %v", rr.Code)
+	}
+}
+
+func TestSearchReposCanceled(t *testing.T) {
+	tmpDir, repoPath := setupRepoForTest(t)
+	defer os.RemoveAll(tmpDir)
+
+	repos := []models.Repository{
+		{ID: "1", Name: "One", Path: repoPath},
+		{ID: "2", Name: "Two", Path: repoPath},
+	}
+
+	// Repositories are not searched once the client has gone away
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	kinds := map[string]bool{hitFile: true, hitCommit: true, hitContent: true}
+	for _, result := range searchRepos(ctx, repos, "Initial", kinds, defaultSearchLimit) {
+		if result.Error != context.Canceled.Error() || len(result.Hits) != 0 {
+			t.Errorf("Expected %s to be skipped, got %+v", result.ID, result)
+		}
+	}
+
+	results := searchRepos(context.Background(), repos, "Initial", kinds, defaultSearchLimit)
+	if results[0].Error != "" || len(results[0].Hits) == 0 {
+		t.Errorf("Expected hits without cancellation, got %+v", results[0])
+	}
+}
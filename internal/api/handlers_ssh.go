@@ -0,0 +1,73 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/Gemini8532/gitwapp/internal/git"
+)
+
+// KnownHostsResponse lists the trusted SSH host keys and the unknown keys
+// awaiting acceptance.
+type KnownHostsResponse struct {
+	Known   []git.KnownHost `json:"known"`
+	Pending []git.KnownHost `json:"pending"`
+}
+
+// AcceptHostKeyRequest represents the request body for trusting a pending
+// SSH host key. Fingerprint must match the key the host presented.
+type AcceptHostKeyRequest struct {
+	Host        string `json:"host"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// handleListKnownHosts handles requests to list known and pending SSH host keys.
+func (s *Server) handleListKnownHosts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	slog.InfoContext(ctx, "Listing known hosts")
+	known, err := git.ListKnownHosts()
+	if err != nil {
+		slog.ErrorContext(ctx, "List known hosts failed", "error", err)
+		http.Error(w, "Failed to read known hosts: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := KnownHostsResponse{Known: known, Pending: git.PendingHostKeys()}
+
+	slog.InfoContext(ctx, "Known hosts listed successfully", "known", len(resp.Known), "pending", len(resp.Pending))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleAcceptHostKey handles requests to add a pending SSH host key to known_hosts.
+func (s *Server) handleAcceptHostKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req AcceptHostKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.ErrorContext(ctx, "Failed to decode accept host key request", "error", err)
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	slog.InfoContext(ctx, "Accepting host key", "host", req.Host, "fingerprint", req.Fingerprint)
+
+	if err := git.AcceptHostKey(req.Host, req.Fingerprint); err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, git.ErrHostKeyNotPending):
+			status = http.StatusNotFound
+		case errors.Is(err, git.ErrFingerprintMismatch):
+			status = http.StatusConflict
+		}
+		slog.ErrorContext(ctx, "Accept host key failed", "host", req.Host, "error", err)
+		http.Error(w, "Failed to accept host key: "+err.Error(), status)
+		return
+	}
+
+	slog.InfoContext(ctx, "Host key accepted successfully", "host", req.Host)
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Gemini8532/gitwapp/internal/git"
+)
+
+func TestHandleKnownHosts(t *testing.T) {
+	server, configDir := setupTestServer(t)
+	defer os.RemoveAll(configDir)
+
+	knownHosts := filepath.Join(configDir, "known_hosts")
+	line := "github.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl\n"
+	os.WriteFile(knownHosts, []byte(line), 0600)
+	git.ConfigureSSH(git.SSHConfig{KnownHostsPath: knownHosts})
+	defer git.ConfigureSSH(git.SSHConfig{})
+
+	req, _ := http.NewRequest("GET", "/api/known-hosts", nil)
+	addAuth(t, req)
+	rr := httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("List known hosts failed: %v %s", rr.Code, rr.Body.String())
+	}
+	var resp KnownHostsResponse
+	json.NewDecoder(rr.Body).Decode(&resp)
+	if len(resp.Known) != 1 || resp.Known[0].Host != "github.com" || resp.Known[0].KeyType != "ssh-ed25519" {
+		t.Errorf("Unexpected known hosts: %+v", resp.Known)
+	}
+
+	// Only keys presented by a host can be accepted
+	body, _ := json.Marshal(AcceptHostKeyRequest{Host: "example.com", Fingerprint: "SHA256:abc"})
+	req, _ = http.NewRequest("POST", "/api/known-hosts", bytes.NewBuffer(body))
+	addAuth(t, req)
+	rr = httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for host without pending key, got %v", rr.Code)
+	}
+}
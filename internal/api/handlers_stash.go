@@ -0,0 +1,188 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/Gemini8532/gitwapp/internal/git"
+	"github.com/gorilla/mux"
+)
+
+// SaveStashRequest represents the optional request body for stashing the
+// local changes of a repository.
+type SaveStashRequest struct {
+	Message          string `json:"message"`
+	IncludeUntracked bool   `json:"include_untracked"`
+	KeepIndex        bool   `json:"keep_index"`
+}
+
+// handleListStashes handles requests to list the stashes of a repository.
+func (s *Server) handleListStashes(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	slog.InfoContext(ctx, "Listing stashes", "id", id)
+
+	repo, err := s.getRepoByID(id)
+	if err != nil {
+		slog.WarnContext(ctx, "List stashes failed - repository not found", "id", id)
+		http.Error(w, "Repository not found", http.StatusNotFound)
+		return
+	}
+
+	stashes, err := git.ListStashes(repo.Path)
+	if err != nil {
+		slog.ErrorContext(ctx, "List stashes failed", "id", id, "path", repo.Path, "error", err)
+		http.Error(w, "Failed to list stashes: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	slog.InfoContext(ctx, "Stashes listed successfully", "id", id, "count", len(stashes))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stashes)
+}
+
+// handleSaveStash handles requests to stash the local changes of a repository.
+func (s *Server) handleSaveStash(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req SaveStashRequest
+	if err := decodeOptionalJSON(r, &req); err != nil {
+		slog.ErrorContext(ctx, "Failed to decode save stash request", "id", id, "error", err)
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	slog.InfoContext(ctx, "Saving stash", "id", id, "include_untracked", req.IncludeUntracked, "keep_index", req.KeepIndex)
+
+	repo, err := s.getRepoByID(id)
+	if err != nil {
+		slog.WarnContext(ctx, "Save stash failed - repository not found", "id", id)
+		http.Error(w, "Repository not found", http.StatusNotFound)
+		return
+	}
+
+	stash, err := git.SaveStash(repo.Path, git.StashOptions{
+		Message:          req.Message,
+		IncludeUntracked: req.IncludeUntracked,
+		KeepIndex:        req.KeepIndex,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Save stash failed", "id", id, "path", repo.Path, "error", err)
+		http.Error(w, "Failed to save stash: "+err.Error(), gitErrorStatus(err))
+		return
+	}
+
+	slog.InfoContext(ctx, "Stash saved successfully", "id", id, "hash", stash.Hash)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(stash)
+}
+
+// handleGetStash handles requests to show a stash and the changes it holds.
+func (s *Server) handleGetStash(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+	index, _ := strconv.Atoi(vars["index"])
+
+	slog.InfoContext(ctx, "Getting stash", "id", id, "index", index)
+
+	repo, err := s.getRepoByID(id)
+	if err != nil {
+		slog.WarnContext(ctx, "Get stash failed - repository not found", "id", id)
+		http.Error(w, "Repository not found", http.StatusNotFound)
+		return
+	}
+
+	detail, err := git.GetStash(repo.Path, index)
+	if err != nil {
+		slog.ErrorContext(ctx, "Get stash failed", "id", id, "path", repo.Path, "index", index, "error", err)
+		http.Error(w, "Failed to get stash: "+err.Error(), gitErrorStatus(err))
+		return
+	}
+
+	slog.InfoContext(ctx, "Stash retrieved successfully", "id", id, "index", index, "files", len(detail.Files))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(detail)
+}
+
+// handleApplyStash handles requests to apply a stash while keeping it.
+func (s *Server) handleApplyStash(w http.ResponseWriter, r *http.Request) {
+	s.handleRestoreStash(w, r, false)
+}
+
+// handlePopStash handles requests to apply a stash and remove it.
+func (s *Server) handlePopStash(w http.ResponseWriter, r *http.Request) {
+	s.handleRestoreStash(w, r, true)
+}
+
+// handleRestoreStash applies a stash, removing it when pop is true.
+// Conflicts are reported with status 409 and the list of conflicted paths.
+func (s *Server) handleRestoreStash(w http.ResponseWriter, r *http.Request, pop bool) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+	index, _ := strconv.Atoi(vars["index"])
+
+	slog.InfoContext(ctx, "Applying stash", "id", id, "index", index, "pop", pop)
+
+	repo, err := s.getRepoByID(id)
+	if err != nil {
+		slog.WarnContext(ctx, "Apply stash failed - repository not found", "id", id)
+		http.Error(w, "Repository not found", http.StatusNotFound)
+		return
+	}
+
+	result, err := git.ApplyStash(repo.Path, index, pop)
+	if err != nil {
+		slog.ErrorContext(ctx, "Apply stash failed", "id", id, "path", repo.Path, "index", index, "error", err)
+		http.Error(w, "Failed to apply stash: "+err.Error(), gitErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if len(result.Conflicts) > 0 {
+		slog.WarnContext(ctx, "Stash applied with conflicts", "id", id, "index", index, "conflicts", len(result.Conflicts))
+		w.WriteHeader(http.StatusConflict)
+	} else {
+		slog.InfoContext(ctx, "Stash applied successfully", "id", id, "index", index, "dropped", result.Dropped)
+	}
+	json.NewEncoder(w).Encode(result)
+}
+
+// handleDropStash handles requests to remove a stash.
+func (s *Server) handleDropStash(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+	index, _ := strconv.Atoi(vars["index"])
+
+	slog.InfoContext(ctx, "Dropping stash", "id", id, "index", index)
+
+	repo, err := s.getRepoByID(id)
+	if err != nil {
+		slog.WarnContext(ctx, "Drop stash failed - repository not found", "id", id)
+		http.Error(w, "Repository not found", http.StatusNotFound)
+		return
+	}
+
+	if err := git.DropStash(repo.Path, index); err != nil {
+		slog.ErrorContext(ctx, "Drop stash failed", "id", id, "path", repo.Path, "index", index, "error", err)
+		http.Error(w, "Failed to drop stash: "+err.Error(), gitErrorStatus(err))
+		return
+	}
+
+	slog.InfoContext(ctx, "Stash dropped successfully", "id", id, "index", index)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Gemini8532/gitwapp/internal/git"
+	"github.com/Gemini8532/gitwapp/pkg/models"
+)
+
+func TestHandleStashes(t *testing.T) {
+	server, configDir := setupTestServer(t)
+	defer os.RemoveAll(configDir)
+
+	tmpDir, repoPath := setupRepoForTest(t)
+	defer os.RemoveAll(tmpDir)
+	gitCmd(t, repoPath, "config", "user.name", "Test")
+	gitCmd(t, repoPath, "config", "user.email", "test@example.com")
+
+	repo := models.Repository{ID: "1", Name: "Test", Path: repoPath}
+	server.store.SaveRepositories([]models.Repository{repo})
+
+	do := func(method, path string, body []byte) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(body))
+		addAuth(t, req)
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := do("POST", "/api/repos/1/stashes", nil); rr.Code != http.StatusConflict {
+		t.Errorf("Expected 409 without local changes, got %v", rr.Code)
+	}
+
+	os.WriteFile(filepath.Join(repoPath, "README.md"), []byte("parked"), 0644)
+	os.WriteFile(filepath.Join(repoPath, "new.txt"), []byte("new"), 0644)
+
+	body, _ := json.Marshal(SaveStashRequest{Message: "before pull", IncludeUntracked: true})
+	rr := do("POST", "/api/repos/1/stashes", body)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %v %s", rr.Code, rr.Body.String())
+	}
+	var stash git.Stash
+	json.NewDecoder(rr.Body).Decode(&stash)
+	if stash.Message != "before pull" || stash.Index != 0 {
+		t.Errorf("Unexpected stash: %+v", stash)
+	}
+
+	rr = do("GET", "/api/repos/1/stashes", nil)
+	var stashes []git.Stash
+	json.NewDecoder(rr.Body).Decode(&stashes)
+	if rr.Code != http.StatusOK || len(stashes) != 1 {
+		t.Fatalf("Expected one stash, got %v %+v", rr.Code, stashes)
+	}
+
+	rr = do("GET", "/api/repos/1/stashes/0", nil)
+	var detail git.StashDetail
+	json.NewDecoder(rr.Body).Decode(&detail)
+	if rr.Code != http.StatusOK || len(detail.Files) != 2 {
+		t.Errorf("Expected stash with 2 files, got %v %+v", rr.Code, detail)
+	}
+	if rr := do("GET", "/api/repos/1/stashes/5", nil); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown stash, got %v", rr.Code)
+	}
+
+	rr = do("POST", "/api/repos/1/stashes/0/apply", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200 for apply, got %v %s", rr.Code, rr.Body.String())
+	}
+	if data, _ := os.ReadFile(filepath.Join(repoPath, "README.md")); string(data) != "parked" {
+		t.Errorf("Expected stashed change to be applied, got %q", data)
+	}
+
+	// The applied change now conflicts with a commit
+	gitCmd(t, repoPath, "add", "-A")
+	gitCmd(t, repoPath, "commit", "-m", "Conflicting")
+	os.WriteFile(filepath.Join(repoPath, "README.md"), []byte("other"), 0644)
+	gitCmd(t, repoPath, "commit", "-am", "Other")
+
+	rr = do("POST", "/api/repos/1/stashes/0/pop", nil)
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("Expected 409 for conflicting pop, got %v %s", rr.Code, rr.Body.String())
+	}
+	var result git.StashApplyResult
+	json.NewDecoder(rr.Body).Decode(&result)
+	if result.Dropped || len(result.Conflicts) == 0 {
+		t.Errorf("Expected kept stash with conflicts, got %+v", result)
+	}
+	gitCmd(t, repoPath, "reset", "--hard")
+
+	if rr := do("DELETE", "/api/repos/1/stashes/0", nil); rr.Code != http.StatusNoContent {
+		t.Errorf("Expected 204 for drop, got %v", rr.Code)
+	}
+	if rr := do("DELETE", "/api/repos/1/stashes/0", nil); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for dropped stash, got %v", rr.Code)
+	}
+}
@@ -0,0 +1,168 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/Gemini8532/gitwapp/internal/git"
+	"github.com/gorilla/mux"
+)
+
+// CreateTagRequest represents the request body for creating a tag.
+type CreateTagRequest struct {
+	Name    string `json:"name"`
+	Target  string `json:"target"`  // Optional, defaults to HEAD
+	Message string `json:"message"` // Optional, creates an annotated tag
+	Sign    bool   `json:"sign"`    // Sign the tag with the server's GPG key
+}
+
+// PushTagRequest represents the request body for pushing a tag.
+type PushTagRequest struct {
+	Name       string `json:"name"`
+	Remote     string `json:"remote"`               // Optional, defaults to the current branch's remote
+	Passphrase string `json:"passphrase,omitempty"` // Unlocks the server's SSH key, if it is encrypted
+}
+
+// handleListTags handles requests to list the tags of a repository.
+func (s *Server) handleListTags(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	slog.InfoContext(ctx, "Listing tags", "id", id)
+
+	repo, err := s.getRepoByID(id)
+	if err != nil {
+		slog.WarnContext(ctx, "List tags failed - repository not found", "id", id)
+		http.Error(w, "Repository not found", http.StatusNotFound)
+		return
+	}
+
+	tags, err := git.ListTags(repo.Path)
+	if err != nil {
+		slog.ErrorContext(ctx, "List tags failed", "id", id, "path", repo.Path, "error", err)
+		http.Error(w, "Failed to list tags: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	slog.InfoContext(ctx, "Tags listed successfully", "id", id, "count", len(tags))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tags)
+}
+
+// handleCreateTag handles requests to create a lightweight or annotated tag.
+func (s *Server) handleCreateTag(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req CreateTagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.ErrorContext(ctx, "Failed to decode create tag request", "id", id, "error", err)
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	slog.InfoContext(ctx, "Creating tag", "id", id, "name", req.Name, "target", req.Target, "sign", req.Sign)
+
+	if req.Sign && req.Message == "" {
+		slog.WarnContext(ctx, "Create tag failed - signed tags require a message", "id", id, "name", req.Name)
+		http.Error(w, "Signed tags require a message", http.StatusBadRequest)
+		return
+	}
+
+	repo, err := s.getRepoByID(id)
+	if err != nil {
+		slog.WarnContext(ctx, "Create tag failed - repository not found", "id", id)
+		http.Error(w, "Repository not found", http.StatusNotFound)
+		return
+	}
+
+	tag, err := git.CreateTag(repo.Path, git.TagOptions{
+		Name:    req.Name,
+		Target:  req.Target,
+		Message: req.Message,
+		Sign:    req.Sign,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Create tag failed", "id", id, "path", repo.Path, "name", req.Name, "error", err)
+		http.Error(w, "Failed to create tag: "+err.Error(), gitErrorStatus(err))
+		return
+	}
+
+	slog.InfoContext(ctx, "Tag created successfully", "id", id, "name", tag.Name, "hash", tag.Hash)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(tag)
+}
+
+// handleDeleteTag handles requests to delete a local tag.
+func (s *Server) handleDeleteTag(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+	name := vars["name"]
+
+	slog.InfoContext(ctx, "Deleting tag", "id", id, "name", name)
+
+	repo, err := s.getRepoByID(id)
+	if err != nil {
+		slog.WarnContext(ctx, "Delete tag failed - repository not found", "id", id)
+		http.Error(w, "Repository not found", http.StatusNotFound)
+		return
+	}
+
+	if err := git.DeleteTag(repo.Path, name); err != nil {
+		slog.ErrorContext(ctx, "Delete tag failed", "id", id, "path", repo.Path, "name", name, "error", err)
+		http.Error(w, "Failed to delete tag: "+err.Error(), gitErrorStatus(err))
+		return
+	}
+
+	slog.InfoContext(ctx, "Tag deleted successfully", "id", id, "name", name)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlePushTag handles requests to push a single tag to a remote.
+func (s *Server) handlePushTag(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req PushTagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.ErrorContext(ctx, "Failed to decode push tag request", "id", id, "error", err)
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	slog.InfoContext(ctx, "Pushing tag", "id", id, "name", req.Name, "remote", req.Remote)
+
+	repo, err := s.getRepoByID(id)
+	if err != nil {
+		slog.WarnContext(ctx, "Push tag failed - repository not found", "id", id)
+		http.Error(w, "Repository not found", http.StatusNotFound)
+		return
+	}
+
+	creds, err := s.repoCredentials(repo)
+	if err != nil {
+		slog.ErrorContext(ctx, "Push tag failed - unable to load credentials", "id", id, "error", err)
+		http.Error(w, "Failed to load credentials", http.StatusInternalServerError)
+		return
+	}
+	creds = withPassphrase(creds, req.Passphrase)
+
+	if err := git.PushTag(repo.Path, req.Name, git.PushOptions{Remote: req.Remote, Auth: creds}); err != nil {
+		slog.ErrorContext(ctx, "Push tag failed", "id", id, "path", repo.Path, "name", req.Name, "error", err)
+		http.Error(w, "Failed to push tag: "+err.Error(), gitErrorStatus(err))
+		return
+	}
+
+	slog.InfoContext(ctx, "Tag pushed successfully", "id", id, "name", req.Name)
+	w.WriteHeader(http.StatusOK)
+}
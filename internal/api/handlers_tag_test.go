@@ -0,0 +1,93 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Gemini8532/gitwapp/internal/git"
+	"github.com/Gemini8532/gitwapp/pkg/models"
+)
+
+func TestHandleTags(t *testing.T) {
+	server, configDir := setupTestServer(t)
+	defer os.RemoveAll(configDir)
+
+	tmpDir, repoPath := setupRepoForTest(t)
+	defer os.RemoveAll(tmpDir)
+	gitCmd(t, repoPath, "config", "user.name", "Test")
+	gitCmd(t, repoPath, "config", "user.email", "test@example.com")
+
+	remotePath := filepath.Join(tmpDir, "remote.git")
+	gitCmd(t, tmpDir, "clone", "--bare", repoPath, remotePath)
+	gitCmd(t, repoPath, "remote", "add", "origin", remotePath)
+
+	repo := models.Repository{ID: "1", Name: "Test", Path: repoPath}
+	server.store.SaveRepositories([]models.Repository{repo})
+
+	do := func(method, path string, body []byte) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(body))
+		addAuth(t, req)
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	body, _ := json.Marshal(CreateTagRequest{Name: "v1.0", Message: "Release 1.0"})
+	rr := do("POST", "/api/repos/1/tags", body)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %v %s", rr.Code, rr.Body.String())
+	}
+	var tag git.Tag
+	json.NewDecoder(rr.Body).Decode(&tag)
+	if !tag.Annotated || tag.Message != "Release 1.0" {
+		t.Errorf("Unexpected tag: %+v", tag)
+	}
+
+	if rr := do("POST", "/api/repos/1/tags", body); rr.Code != http.StatusConflict {
+		t.Errorf("Expected 409 for existing tag, got %v", rr.Code)
+	}
+	body, _ = json.Marshal(CreateTagRequest{Name: "bad..name"})
+	if rr := do("POST", "/api/repos/1/tags", body); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for invalid name, got %v", rr.Code)
+	}
+	body, _ = json.Marshal(CreateTagRequest{Name: "v2.0", Sign: true})
+	if rr := do("POST", "/api/repos/1/tags", body); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for signed tag without message, got %v", rr.Code)
+	}
+	body, _ = json.Marshal(CreateTagRequest{Name: "release/next"})
+	if rr := do("POST", "/api/repos/1/tags", body); rr.Code != http.StatusCreated {
+		t.Errorf("Expected 201 for lightweight tag, got %v", rr.Code)
+	}
+
+	rr = do("GET", "/api/repos/1/tags", nil)
+	var tags []git.Tag
+	json.NewDecoder(rr.Body).Decode(&tags)
+	if rr.Code != http.StatusOK || len(tags) != 2 {
+		t.Fatalf("Expected 2 tags, got %v %+v", rr.Code, tags)
+	}
+
+	body, _ = json.Marshal(PushTagRequest{Name: "v1.0"})
+	if rr := do("POST", "/api/repos/1/tags/push", body); rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200 for tag push, got %v %s", rr.Code, rr.Body.String())
+	}
+	if refs := gitCmd(t, remotePath, "tag", "--list"); strings.TrimSpace(refs) != "v1.0" {
+		t.Errorf("Expected only v1.0 on remote, got %q", refs)
+	}
+	body, _ = json.Marshal(PushTagRequest{Name: "v9.9"})
+	if rr := do("POST", "/api/repos/1/tags/push", body); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown tag, got %v", rr.Code)
+	}
+
+	if rr := do("DELETE", "/api/repos/1/tags/release/next", nil); rr.Code != http.StatusNoContent {
+		t.Errorf("Expected 204 for delete, got %v", rr.Code)
+	}
+	if rr := do("DELETE", "/api/repos/1/tags/release/next", nil); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for deleted tag, got %v", rr.Code)
+	}
+}
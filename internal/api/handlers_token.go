@@ -0,0 +1,120 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/Gemini8532/gitwapp/pkg/models"
+	"github.com/gorilla/mux"
+)
+
+// RepoTokenRequest represents the request body for storing a repository's HTTPS token.
+type RepoTokenRequest struct {
+	Username string `json:"username"`
+	Token    string `json:"token"`
+}
+
+// RepoTokenResponse describes the stored token of a repository without
+// revealing the token itself.
+type RepoTokenResponse struct {
+	Configured bool   `json:"configured"`
+	Username   string `json:"username,omitempty"`
+}
+
+// handleGetRepoToken handles requests to check whether a repository has a stored HTTPS token.
+func (s *Server) handleGetRepoToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	slog.InfoContext(ctx, "Getting repository token", "id", id)
+
+	if _, err := s.getRepoByID(id); err != nil {
+		slog.WarnContext(ctx, "Get token failed - repository not found", "id", id)
+		http.Error(w, "Repository not found", http.StatusNotFound)
+		return
+	}
+
+	token, err := s.store.LoadRepoToken(id)
+	if err != nil {
+		slog.ErrorContext(ctx, "Get token failed - unable to load token", "id", id, "error", err)
+		http.Error(w, "Failed to load token", http.StatusInternalServerError)
+		return
+	}
+
+	resp := RepoTokenResponse{}
+	if token != nil {
+		resp.Configured = true
+		resp.Username = token.Username
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleSetRepoToken handles requests to store an HTTPS token for a repository.
+// The token is used instead of the system credential helper.
+func (s *Server) handleSetRepoToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req RepoTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.ErrorContext(ctx, "Failed to decode token request", "id", id, "error", err)
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	slog.InfoContext(ctx, "Setting repository token", "id", id, "username", req.Username, "token_length", len(req.Token))
+
+	if req.Token == "" {
+		slog.WarnContext(ctx, "Set token failed - token is required", "id", id)
+		http.Error(w, "Token is required", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := s.getRepoByID(id); err != nil {
+		slog.WarnContext(ctx, "Set token failed - repository not found", "id", id)
+		http.Error(w, "Repository not found", http.StatusNotFound)
+		return
+	}
+
+	token := models.RepoToken{RepoID: id, Username: req.Username, Token: req.Token}
+	if err := s.store.SaveRepoToken(token); err != nil {
+		slog.ErrorContext(ctx, "Set token failed - unable to save token", "id", id, "error", err)
+		http.Error(w, "Failed to save token", http.StatusInternalServerError)
+		return
+	}
+
+	slog.InfoContext(ctx, "Repository token saved successfully", "id", id)
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleDeleteRepoToken handles requests to remove the stored HTTPS token of a repository.
+func (s *Server) handleDeleteRepoToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	slog.InfoContext(ctx, "Deleting repository token", "id", id)
+
+	removed, err := s.store.DeleteRepoToken(id)
+	if err != nil {
+		slog.ErrorContext(ctx, "Delete token failed - unable to save tokens", "id", id, "error", err)
+		http.Error(w, "Failed to delete token", http.StatusInternalServerError)
+		return
+	}
+	if !removed {
+		slog.WarnContext(ctx, "Delete token failed - no token stored", "id", id)
+		http.Error(w, "Token not found", http.StatusNotFound)
+		return
+	}
+
+	slog.InfoContext(ctx, "Repository token deleted successfully", "id", id)
+	w.WriteHeader(http.StatusNoContent)
+}
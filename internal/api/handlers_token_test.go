@@ -0,0 +1,67 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/Gemini8532/gitwapp/pkg/models"
+)
+
+func TestHandleRepoToken(t *testing.T) {
+	server, configDir := setupTestServer(t)
+	defer os.RemoveAll(configDir)
+
+	repo := models.Repository{ID: "1", Name: "Test", Path: configDir}
+	server.store.SaveRepositories([]models.Repository{repo})
+
+	body, _ := json.Marshal(RepoTokenRequest{Username: "alice", Token: "glpat-secret-value"})
+	req, _ := http.NewRequest("PUT", "/api/repos/1/token", bytes.NewBuffer(body))
+	addAuth(t, req)
+	rr := httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Set token failed: %v %s", rr.Code, rr.Body.String())
+	}
+
+	// The token must not be stored in plain text
+	data, err := os.ReadFile(server.store.GetTokensPath())
+	if err != nil {
+		t.Fatalf("Failed to read tokens file: %v", err)
+	}
+	if bytes.Contains(data, []byte("glpat-secret-value")) {
+		t.Error("Token stored in plain text")
+	}
+
+	creds, err := server.repoCredentials(&repo)
+	if err != nil || creds == nil || creds.Password != "glpat-secret-value" || creds.Username != "alice" {
+		t.Errorf("Unexpected credentials: %+v (err %v)", creds, err)
+	}
+
+	req, _ = http.NewRequest("GET", "/api/repos/1/token", nil)
+	addAuth(t, req)
+	rr = httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	var resp RepoTokenResponse
+	json.NewDecoder(rr.Body).Decode(&resp)
+	if !resp.Configured || resp.Username != "alice" {
+		t.Errorf("Unexpected token response: %+v", resp)
+	}
+	if bytes.Contains(rr.Body.Bytes(), []byte("glpat")) {
+		t.Error("Token leaked in response")
+	}
+
+	req, _ = http.NewRequest("DELETE", "/api/repos/1/token", nil)
+	addAuth(t, req)
+	rr = httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNoContent {
+		t.Errorf("Delete token failed: %v %s", rr.Code, rr.Body.String())
+	}
+	if creds, _ := server.repoCredentials(&repo); creds != nil {
+		t.Errorf("Expected no credentials after delete, got %+v", creds)
+	}
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/Gemini8532/gitwapp/internal/git"
+	"github.com/gorilla/mux"
+)
+
+// handleGetTree handles requests to list a directory at a revision. The
+// ref query parameter defaults to HEAD and path to the repository root;
+// last_commit=true adds the last commit that changed each entry.
+func (s *Server) handleGetTree(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+	ref := r.URL.Query().Get("ref")
+	dir := r.URL.Query().Get("path")
+	lastCommits := r.URL.Query().Get("last_commit") == "true"
+
+	slog.InfoContext(ctx, "Getting tree", "id", id, "ref", ref, "dir", dir, "last_commit", lastCommits)
+
+	repo, err := s.getRepoByID(id)
+	if err != nil {
+		slog.WarnContext(ctx, "Get tree failed - repository not found", "id", id)
+		http.Error(w, "Repository not found", http.StatusNotFound)
+		return
+	}
+
+	tree, err := git.GetTree(repo.Path, ref, dir, lastCommits)
+	if err != nil {
+		slog.ErrorContext(ctx, "Get tree failed", "id", id, "ref", ref, "dir", dir, "path", repo.Path, "error", err)
+		http.Error(w, "Failed to get tree: "+err.Error(), gitErrorStatus(err))
+		return
+	}
+
+	slog.InfoContext(ctx, "Tree retrieved successfully", "id", id, "commit", tree.Commit, "entries", len(tree.Entries))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tree)
+}
+
+// handleGetBlob handles requests for the raw content of a file at a
+// revision. The ref query parameter defaults to HEAD; path is required.
+// Text is always served as plain text, so that repository content is never
+// rendered as HTML; binary files get their sniffed type. The blob hash is
+// used as ETag, and range requests are supported.
+func (s *Server) handleGetBlob(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+	ref := r.URL.Query().Get("ref")
+	file := r.URL.Query().Get("path")
+
+	if file == "" {
+		http.Error(w, "Path parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	slog.InfoContext(ctx, "Getting blob", "id", id, "ref", ref, "file", file)
+
+	repo, err := s.getRepoByID(id)
+	if err != nil {
+		slog.WarnContext(ctx, "Get blob failed - repository not found", "id", id)
+		http.Error(w, "Repository not found", http.StatusNotFound)
+		return
+	}
+
+	blob, err := git.GetBlob(repo.Path, ref, file)
+	if err != nil {
+		slog.ErrorContext(ctx, "Get blob failed", "id", id, "ref", ref, "file", file, "path", repo.Path, "error", err)
+		http.Error(w, "Failed to get blob: "+err.Error(), gitErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", blobContentType(blob))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("ETag", `"`+blob.Hash+`"`)
+	http.ServeContent(w, r, path.Base(blob.Path), time.Time{}, bytes.NewReader(blob.Content))
+}
+
+// blobContentType returns the content type a blob is served with.
+func blobContentType(blob *git.Blob) string {
+	if !blob.Binary {
+		return "text/plain; charset=utf-8"
+	}
+	if ct := http.DetectContentType(blob.Content); !strings.HasPrefix(ct, "text/") {
+		return ct
+	}
+	return "application/octet-stream"
+}
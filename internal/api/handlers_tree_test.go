@@ -0,0 +1,93 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Gemini8532/gitwapp/internal/git"
+	"github.com/Gemini8532/gitwapp/pkg/models"
+)
+
+func TestHandleTreeAndBlob(t *testing.T) {
+	server, configDir := setupTestServer(t)
+	defer os.RemoveAll(configDir)
+
+	tmpDir, repoPath := setupRepoForTest(t)
+	defer os.RemoveAll(tmpDir)
+
+	repo := models.Repository{ID: "1", Name: "Test", Path: repoPath}
+	server.store.SaveRepositories([]models.Repository{repo})
+
+	os.MkdirAll(filepath.Join(repoPath, "docs"), 0755)
+	writeAndCommit(t, repoPath, "docs/page.html", "<script>alert(1)</script>\n", "Add page")
+	writeAndCommit(t, repoPath, "image.png", "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR", "Add image")
+
+	get := func(url string, header ...string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", url, nil)
+		addAuth(t, req)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := get("/api/repos/1/tree?last_commit=true")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %v %s", rr.Code, rr.Body.String())
+	}
+	var tree git.Tree
+	json.NewDecoder(rr.Body).Decode(&tree)
+	if len(tree.Entries) != 3 || tree.Entries[0].Name != "docs" || tree.Entries[0].LastCommit == nil {
+		t.Errorf("Unexpected tree: %+v", tree.Entries)
+	}
+
+	rr = get("/api/repos/1/blob?ref=HEAD&path=docs/page.html")
+	if rr.Code != http.StatusOK || rr.Body.String() != "<script>alert(1)</script>\n" {
+		t.Fatalf("Unexpected blob response: %v %q", rr.Code, rr.Body.String())
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "text/plain; charset=utf-8" {
+		t.Errorf("Expected HTML to be served as plain text, got %q", ct)
+	}
+
+	// Conditional requests with the blob hash
+	etag := rr.Header().Get("ETag")
+	if rr := get("/api/repos/1/blob?path=docs/page.html", "If-None-Match", etag); rr.Code != http.StatusNotModified {
+		t.Errorf("Expected 304 for matching ETag, got %v", rr.Code)
+	}
+
+	rr = get("/api/repos/1/blob?path=image.png")
+	if ct := rr.Header().Get("Content-Type"); ct != "image/png" {
+		t.Errorf("Expected image/png, got %q", ct)
+	}
+
+	var plain git.Tree
+	json.NewDecoder(get("/api/repos/1/tree").Body).Decode(&plain)
+	if len(plain.Entries) != 3 || plain.Entries[0].LastCommit != nil {
+		t.Errorf("Expected no last commits by default, got %+v", plain.Entries)
+	}
+
+	if rr := get("/api/repos/1/blob"); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without path, got %v", rr.Code)
+	}
+	if rr := get("/api/repos/1/blob?path=docs"); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a directory, got %v", rr.Code)
+	}
+	if rr := get("/api/repos/1/blob?path=missing.txt"); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a missing file, got %v", rr.Code)
+	}
+	if rr := get("/api/repos/1/tree?ref=no-such-branch"); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown ref, got %v", rr.Code)
+	}
+
+	writeAndCommit(t, repoPath, "big.txt", strings.Repeat("x", git.MaxBlobSize+1), "Add big file")
+	if rr := get("/api/repos/1/blob?path=big.txt"); rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected 422 for a file over the size limit, got %v", rr.Code)
+	}
+}
@@ -15,6 +15,15 @@ import (
 type AddUserRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
+	Name     string `json:"name,omitempty"`  // Commit author name
+	Email    string `json:"email,omitempty"` // Commit author email
+}
+
+// UpdateUserRequest defines the structure for a request to change the
+// commit identity of a user. Omitted fields are left unchanged.
+type UpdateUserRequest struct {
+	Name  *string `json:"name"`
+	Email *string `json:"email"`
 }
 
 // UserResponse defines the structure for a user-related API response.
@@ -22,6 +31,8 @@ type AddUserRequest struct {
 type UserResponse struct {
 	ID       string `json:"id"`
 	Username string `json:"username"`
+	Name     string `json:"name,omitempty"`
+	Email    string `json:"email,omitempty"`
 }
 
 // handleAddUser handles the API request to create a new user.
@@ -69,6 +80,8 @@ func (s *Server) handleAddUser(w http.ResponseWriter, r *http.Request) {
 		ID:           uuid.New().String(),
 		Username:     req.Username,
 		PasswordHash: string(hashedPassword),
+		Name:         req.Name,
+		Email:        req.Email,
 	}
 
 	users = append(users, newUser)
@@ -85,6 +98,8 @@ func (s *Server) handleAddUser(w http.ResponseWriter, r *http.Request) {
 	response := UserResponse{
 		ID:       newUser.ID,
 		Username: newUser.Username,
+		Name:     newUser.Name,
+		Email:    newUser.Email,
 	}
 	slog.InfoContext(ctx, "User added successfully", "id", newUser.ID, "username", req.Username)
 	w.WriteHeader(http.StatusCreated)
@@ -133,6 +148,61 @@ func (s *Server) handleRemoveUser(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// handleUpdateUser handles the API request to change the commit identity
+// (name and email) of a user.
+func (s *Server) handleUpdateUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req UpdateUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.ErrorContext(ctx, "Failed to decode update user request", "id", id, "error", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	slog.InfoContext(ctx, "Updating user", "id", id)
+
+	users, err := s.store.LoadUsers()
+	if err != nil {
+		slog.ErrorContext(ctx, "Update user failed - unable to load users", "id", id, "error", err)
+		http.Error(w, "Failed to load users", http.StatusInternalServerError)
+		return
+	}
+
+	var user *models.User
+	for i := range users {
+		if users[i].ID == id {
+			user = &users[i]
+			break
+		}
+	}
+	if user == nil {
+		slog.WarnContext(ctx, "Update user failed - user not found", "id", id)
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	if req.Name != nil {
+		user.Name = *req.Name
+	}
+	if req.Email != nil {
+		user.Email = *req.Email
+	}
+
+	if err := s.store.SaveUsers(users); err != nil {
+		slog.ErrorContext(ctx, "Update user failed - unable to save users", "id", id, "error", err)
+		http.Error(w, "Failed to save users", http.StatusInternalServerError)
+		return
+	}
+
+	slog.InfoContext(ctx, "User updated successfully", "id", id)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(UserResponse{ID: user.ID, Username: user.Username, Name: user.Name, Email: user.Email})
+}
+
 // handleListUsers handles the API request to list all users.
 // The password hash is omitted from the response for security.
 func (s *Server) handleListUsers(w http.ResponseWriter, r *http.Request) {
@@ -109,3 +109,36 @@ func TestHandleRemoveUser(t *testing.T) {
 		t.Errorf("Expected 0 users, got %d", len(users))
 	}
 }
+
+func TestHandleUpdateUser(t *testing.T) {
+	server, tmpDir := setupTestServer(t)
+	defer os.RemoveAll(tmpDir)
+
+	server.store.SaveUsers([]models.User{
+		{ID: "1", Username: "u1", PasswordHash: "hash1", Email: "old@example.com"},
+	})
+
+	body := []byte(`{"name": "User One"}`)
+	req, _ := http.NewRequest("PATCH", "/internal/api/users/1", bytes.NewBuffer(body))
+	rr := httptest.NewRecorder()
+
+	server.router.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v",
+			status, http.StatusOK)
+	}
+
+	// Omitted fields are left unchanged
+	users, _ := server.store.LoadUsers()
+	if users[0].Name != "User One" || users[0].Email != "old@example.com" || users[0].PasswordHash != "hash1" {
+		t.Errorf("Unexpected user after update: %+v", users[0])
+	}
+
+	req, _ = http.NewRequest("PATCH", "/internal/api/users/2", bytes.NewBuffer(body))
+	rr = httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown user, got %d", rr.Code)
+	}
+}
@@ -6,10 +6,12 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/Gemini8532/gitwapp/internal/config"
 	"github.com/Gemini8532/gitwapp/internal/middleware"
+	"github.com/Gemini8532/gitwapp/internal/watcher"
 	"github.com/Gemini8532/gitwapp/frontend"
 	"github.com/gorilla/mux"
 )
@@ -28,6 +30,11 @@ type Server struct {
 	store     *config.Store
 	http      *http.Server
 	buildInfo BuildInfo
+
+	watcherMu sync.Mutex
+	watcher   *watcher.Watcher // Started by the first event stream, see repoWatcher
+
+	confirms confirmations // Pending confirmations of destructive operations
 }
 
 // NewServer creates a new instance of the Server.
@@ -65,21 +72,80 @@ func (s *Server) routes() {
 	apiProtected.Use(middleware.JWTMiddleware)
 
 	apiProtected.HandleFunc("/repos", s.handleGetRepos).Methods("GET")
+	apiProtected.HandleFunc("/events", s.handleEvents).Methods("GET")
+	apiProtected.HandleFunc("/credentials", s.handleListCredentials).Methods("GET")
+	apiProtected.HandleFunc("/credentials", s.handleAddCredential).Methods("POST")
+	apiProtected.HandleFunc("/credentials/{credentialId}", s.handleDeleteCredential).Methods("DELETE")
+	apiProtected.HandleFunc("/known-hosts", s.handleListKnownHosts).Methods("GET")
+	apiProtected.HandleFunc("/known-hosts", s.handleAcceptHostKey).Methods("POST")
 	apiProtected.HandleFunc("/repos/{id}/status", s.handleRepoStatus).Methods("GET")
 	apiProtected.HandleFunc("/repos/{id}/file", s.handleGetFile).Methods("GET")
 	apiProtected.HandleFunc("/repos/{id}/diff", s.handleGetDiff).Methods("GET")
+	apiProtected.HandleFunc("/repos/{id}/structured-diff", s.handleGetStructuredDiff).Methods("GET")
 	apiProtected.HandleFunc("/repos/{id}/stage", s.handleStage).Methods("POST")
 	apiProtected.HandleFunc("/repos/{id}/stage-all", s.handleStageAll).Methods("POST")
 	apiProtected.HandleFunc("/repos/{id}/unstage", s.handleUnstage).Methods("POST")
 	apiProtected.HandleFunc("/repos/{id}/unstage-all", s.handleUnstageAll).Methods("POST")
+	apiProtected.HandleFunc("/repos/{id}/stage-hunks", s.handleStageHunks).Methods("POST")
+	apiProtected.HandleFunc("/repos/{id}/unstage-hunks", s.handleUnstageHunks).Methods("POST")
+	apiProtected.HandleFunc("/repos/{id}/discard", s.handleDiscard).Methods("POST")
+	apiProtected.HandleFunc("/repos/{id}/clean", s.handleClean).Methods("POST")
+	apiProtected.HandleFunc("/repos/{id}/reset-hard", s.handleResetHard).Methods("POST")
 	apiProtected.HandleFunc("/repos/{id}/commit", s.handleCommit).Methods("POST")
 	apiProtected.HandleFunc("/repos/{id}/push", s.handlePush).Methods("POST")
 	apiProtected.HandleFunc("/repos/{id}/pull", s.handlePull).Methods("POST")
+	apiProtected.HandleFunc("/repos/{id}/fetch", s.handleFetch).Methods("POST")
+	apiProtected.HandleFunc("/repos/{id}/log", s.handleGetLog).Methods("GET")
+	apiProtected.HandleFunc("/repos/{id}/commits/{hash}", s.handleGetCommit).Methods("GET")
+	apiProtected.HandleFunc("/repos/{id}/compare", s.handleCompare).Methods("GET")
+	apiProtected.HandleFunc("/repos/{id}/tree", s.handleGetTree).Methods("GET")
+	apiProtected.HandleFunc("/repos/{id}/blob", s.handleGetBlob).Methods("GET")
+	apiProtected.HandleFunc("/repos/{id}/blame", s.handleGetBlame).Methods("GET")
+	apiProtected.HandleFunc("/repos/{id}/history", s.handleGetFileHistory).Methods("GET")
+	apiProtected.HandleFunc("/repos/{id}/search", s.handleGrep).Methods("GET")
+	apiProtected.HandleFunc("/search", s.handleSearch).Methods("GET")
+	apiProtected.HandleFunc("/repos/{id}/branches", s.handleListBranches).Methods("GET")
+	apiProtected.HandleFunc("/repos/{id}/branches", s.handleCreateBranch).Methods("POST")
+	apiProtected.HandleFunc("/repos/{id}/branches/checkout", s.handleCheckoutBranch).Methods("POST")
+	apiProtected.HandleFunc("/repos/{id}/branches/rename", s.handleRenameBranch).Methods("POST")
+	apiProtected.HandleFunc("/repos/{id}/branches/{name:.+}", s.handleDeleteBranch).Methods("DELETE")
+	apiProtected.HandleFunc("/repos/{id}/remotes", s.handleListRemotes).Methods("GET")
+	apiProtected.HandleFunc("/repos/{id}/remotes", s.handleAddRemote).Methods("POST")
+	apiProtected.HandleFunc("/repos/{id}/remotes/{name}", s.handleUpdateRemote).Methods("PUT")
+	apiProtected.HandleFunc("/repos/{id}/remotes/{name}", s.handleRemoveRemote).Methods("DELETE")
+	apiProtected.HandleFunc("/repos/{id}/token", s.handleGetRepoToken).Methods("GET")
+	apiProtected.HandleFunc("/repos/{id}/token", s.handleSetRepoToken).Methods("PUT")
+	apiProtected.HandleFunc("/repos/{id}/token", s.handleDeleteRepoToken).Methods("DELETE")
+	apiProtected.HandleFunc("/repos/{id}/credential", s.handleBindCredential).Methods("PUT")
+	apiProtected.HandleFunc("/repos/{id}/credential", s.handleUnbindCredential).Methods("DELETE")
+	apiProtected.HandleFunc("/repos/{id}/merge", s.handleMerge).Methods("POST")
+	apiProtected.HandleFunc("/repos/{id}/conflicts", s.handleListConflicts).Methods("GET")
+	apiProtected.HandleFunc("/repos/{id}/conflicts/versions", s.handleGetConflictVersions).Methods("GET")
+	apiProtected.HandleFunc("/repos/{id}/conflicts/resolution", s.handleWriteResolution).Methods("PUT")
+	apiProtected.HandleFunc("/repos/{id}/conflicts/resolve", s.handleMarkResolved).Methods("POST")
+	apiProtected.HandleFunc("/repos/{id}/conflicts/take", s.handleTakeSide).Methods("POST")
+	apiProtected.HandleFunc("/repos/{id}/abort", s.handleAbortOperation).Methods("POST")
+	apiProtected.HandleFunc("/repos/{id}/continue", s.handleContinueOperation).Methods("POST")
+	apiProtected.HandleFunc("/repos/{id}/rebase", s.handleRebase).Methods("POST")
+	apiProtected.HandleFunc("/repos/{id}/rebase/continue", s.handleRebaseContinue).Methods("POST")
+	apiProtected.HandleFunc("/repos/{id}/rebase/skip", s.handleRebaseSkip).Methods("POST")
+	apiProtected.HandleFunc("/repos/{id}/rebase/abort", s.handleRebaseAbort).Methods("POST")
+	apiProtected.HandleFunc("/repos/{id}/stashes", s.handleListStashes).Methods("GET")
+	apiProtected.HandleFunc("/repos/{id}/stashes", s.handleSaveStash).Methods("POST")
+	apiProtected.HandleFunc("/repos/{id}/stashes/{index:[0-9]+}", s.handleGetStash).Methods("GET")
+	apiProtected.HandleFunc("/repos/{id}/stashes/{index:[0-9]+}", s.handleDropStash).Methods("DELETE")
+	apiProtected.HandleFunc("/repos/{id}/stashes/{index:[0-9]+}/apply", s.handleApplyStash).Methods("POST")
+	apiProtected.HandleFunc("/repos/{id}/stashes/{index:[0-9]+}/pop", s.handlePopStash).Methods("POST")
+	apiProtected.HandleFunc("/repos/{id}/tags", s.handleListTags).Methods("GET")
+	apiProtected.HandleFunc("/repos/{id}/tags", s.handleCreateTag).Methods("POST")
+	apiProtected.HandleFunc("/repos/{id}/tags/push", s.handlePushTag).Methods("POST")
+	apiProtected.HandleFunc("/repos/{id}/tags/{name:.+}", s.handleDeleteTag).Methods("DELETE")
 
 	// Internal API (Localhost only)
 	internal := s.router.PathPrefix("/internal/api").Subrouter()
 	internal.Use(localOnlyMiddleware)
 	internal.HandleFunc("/health", s.handleHealth).Methods("GET")
+	internal.HandleFunc("/events", s.handleEvents).Methods("GET")
 
 	// Repository management (admin)
 	internal.HandleFunc("/repos", s.handleListRepos).Methods("GET")
@@ -90,18 +156,80 @@ func (s *Server) routes() {
 	internal.HandleFunc("/repos/{id}/status", s.handleRepoStatus).Methods("GET")
 	internal.HandleFunc("/repos/{id}/file", s.handleGetFile).Methods("GET")
 	internal.HandleFunc("/repos/{id}/diff", s.handleGetDiff).Methods("GET")
+	internal.HandleFunc("/repos/{id}/structured-diff", s.handleGetStructuredDiff).Methods("GET")
 	internal.HandleFunc("/repos/{id}/stage", s.handleStage).Methods("POST")
 	internal.HandleFunc("/repos/{id}/stage-all", s.handleStageAll).Methods("POST")
 	internal.HandleFunc("/repos/{id}/unstage", s.handleUnstage).Methods("POST")
 	internal.HandleFunc("/repos/{id}/unstage-all", s.handleUnstageAll).Methods("POST")
+	internal.HandleFunc("/repos/{id}/stage-hunks", s.handleStageHunks).Methods("POST")
+	internal.HandleFunc("/repos/{id}/unstage-hunks", s.handleUnstageHunks).Methods("POST")
+	internal.HandleFunc("/repos/{id}/discard", s.handleDiscard).Methods("POST")
+	internal.HandleFunc("/repos/{id}/clean", s.handleClean).Methods("POST")
+	internal.HandleFunc("/repos/{id}/reset-hard", s.handleResetHard).Methods("POST")
 	internal.HandleFunc("/repos/{id}/commit", s.handleCommit).Methods("POST")
 	internal.HandleFunc("/repos/{id}/push", s.handlePush).Methods("POST")
 	internal.HandleFunc("/repos/{id}/pull", s.handlePull).Methods("POST")
+	internal.HandleFunc("/repos/{id}/fetch", s.handleFetch).Methods("POST")
+	internal.HandleFunc("/repos/{id}/log", s.handleGetLog).Methods("GET")
+	internal.HandleFunc("/repos/{id}/commits/{hash}", s.handleGetCommit).Methods("GET")
+	internal.HandleFunc("/repos/{id}/compare", s.handleCompare).Methods("GET")
+	internal.HandleFunc("/repos/{id}/tree", s.handleGetTree).Methods("GET")
+	internal.HandleFunc("/repos/{id}/blob", s.handleGetBlob).Methods("GET")
+	internal.HandleFunc("/repos/{id}/blame", s.handleGetBlame).Methods("GET")
+	internal.HandleFunc("/repos/{id}/history", s.handleGetFileHistory).Methods("GET")
+	internal.HandleFunc("/repos/{id}/search", s.handleGrep).Methods("GET")
+	internal.HandleFunc("/search", s.handleSearch).Methods("GET")
+	internal.HandleFunc("/repos/{id}/branches", s.handleListBranches).Methods("GET")
+	internal.HandleFunc("/repos/{id}/branches", s.handleCreateBranch).Methods("POST")
+	internal.HandleFunc("/repos/{id}/branches/checkout", s.handleCheckoutBranch).Methods("POST")
+	internal.HandleFunc("/repos/{id}/branches/rename", s.handleRenameBranch).Methods("POST")
+	internal.HandleFunc("/repos/{id}/branches/{name:.+}", s.handleDeleteBranch).Methods("DELETE")
+	internal.HandleFunc("/repos/{id}/remotes", s.handleListRemotes).Methods("GET")
+	internal.HandleFunc("/repos/{id}/remotes", s.handleAddRemote).Methods("POST")
+	internal.HandleFunc("/repos/{id}/remotes/{name}", s.handleUpdateRemote).Methods("PUT")
+	internal.HandleFunc("/repos/{id}/remotes/{name}", s.handleRemoveRemote).Methods("DELETE")
+	internal.HandleFunc("/repos/{id}/token", s.handleGetRepoToken).Methods("GET")
+	internal.HandleFunc("/repos/{id}/token", s.handleSetRepoToken).Methods("PUT")
+	internal.HandleFunc("/repos/{id}/token", s.handleDeleteRepoToken).Methods("DELETE")
+	internal.HandleFunc("/repos/{id}/credential", s.handleBindCredential).Methods("PUT")
+	internal.HandleFunc("/repos/{id}/credential", s.handleUnbindCredential).Methods("DELETE")
+	internal.HandleFunc("/repos/{id}/merge", s.handleMerge).Methods("POST")
+	internal.HandleFunc("/repos/{id}/conflicts", s.handleListConflicts).Methods("GET")
+	internal.HandleFunc("/repos/{id}/conflicts/versions", s.handleGetConflictVersions).Methods("GET")
+	internal.HandleFunc("/repos/{id}/conflicts/resolution", s.handleWriteResolution).Methods("PUT")
+	internal.HandleFunc("/repos/{id}/conflicts/resolve", s.handleMarkResolved).Methods("POST")
+	internal.HandleFunc("/repos/{id}/conflicts/take", s.handleTakeSide).Methods("POST")
+	internal.HandleFunc("/repos/{id}/abort", s.handleAbortOperation).Methods("POST")
+	internal.HandleFunc("/repos/{id}/continue", s.handleContinueOperation).Methods("POST")
+	internal.HandleFunc("/repos/{id}/rebase", s.handleRebase).Methods("POST")
+	internal.HandleFunc("/repos/{id}/rebase/continue", s.handleRebaseContinue).Methods("POST")
+	internal.HandleFunc("/repos/{id}/rebase/skip", s.handleRebaseSkip).Methods("POST")
+	internal.HandleFunc("/repos/{id}/rebase/abort", s.handleRebaseAbort).Methods("POST")
+	internal.HandleFunc("/repos/{id}/stashes", s.handleListStashes).Methods("GET")
+	internal.HandleFunc("/repos/{id}/stashes", s.handleSaveStash).Methods("POST")
+	internal.HandleFunc("/repos/{id}/stashes/{index:[0-9]+}", s.handleGetStash).Methods("GET")
+	internal.HandleFunc("/repos/{id}/stashes/{index:[0-9]+}", s.handleDropStash).Methods("DELETE")
+	internal.HandleFunc("/repos/{id}/stashes/{index:[0-9]+}/apply", s.handleApplyStash).Methods("POST")
+	internal.HandleFunc("/repos/{id}/stashes/{index:[0-9]+}/pop", s.handlePopStash).Methods("POST")
+	internal.HandleFunc("/repos/{id}/tags", s.handleListTags).Methods("GET")
+	internal.HandleFunc("/repos/{id}/tags", s.handleCreateTag).Methods("POST")
+	internal.HandleFunc("/repos/{id}/tags/push", s.handlePushTag).Methods("POST")
+	internal.HandleFunc("/repos/{id}/tags/{name:.+}", s.handleDeleteTag).Methods("DELETE")
+
+	// Credential vault (admin)
+	internal.HandleFunc("/credentials", s.handleListCredentials).Methods("GET")
+	internal.HandleFunc("/credentials", s.handleAddCredential).Methods("POST")
+	internal.HandleFunc("/credentials/{credentialId}", s.handleDeleteCredential).Methods("DELETE")
+
+	// SSH host keys (admin)
+	internal.HandleFunc("/known-hosts", s.handleListKnownHosts).Methods("GET")
+	internal.HandleFunc("/known-hosts", s.handleAcceptHostKey).Methods("POST")
 
 	// User management (admin)
 	internal.HandleFunc("/users", s.handleListUsers).Methods("GET")
 	internal.HandleFunc("/users", s.handleAddUser).Methods("POST")
 	internal.HandleFunc("/users/{id}", s.handleRemoveUser).Methods("DELETE")
+	internal.HandleFunc("/users/{id}", s.handleUpdateUser).Methods("PATCH")
 
 	// Serve frontend static files
 	distFS, err := frontend.GetDistFS()
@@ -143,8 +271,17 @@ func (s *Server) Start(port string) error {
 	return s.http.ListenAndServe()
 }
 
-// Shutdown gracefully shuts down the HTTP server.
+// Shutdown gracefully shuts down the HTTP server, ending open event streams.
 func (s *Server) Shutdown(ctx context.Context) error {
+	s.watcherMu.Lock()
+	if s.watcher != nil {
+		s.watcher.Close()
+	}
+	s.watcherMu.Unlock()
+
+	if s.http == nil {
+		return nil
+	}
 	return s.http.Shutdown(ctx)
 }
 
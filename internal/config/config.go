@@ -16,6 +16,9 @@ const (
 	ConfigDirName = "gitwapp"
 	UsersFile     = "users.json"
 	ReposFile     = "repositories.json"
+	TokensFile    = "tokens.json"
+	CredsFile     = "credentials.json"
+	SecretKeyFile = "secret.key"
 	PIDFile       = "gitwapp.pid"
 )
 
@@ -24,6 +27,7 @@ const (
 type Store struct {
 	configDir string
 	mu        sync.RWMutex
+	key       []byte // Cached encryption key, see encryptionKey
 }
 
 // NewStore creates a new Store and initializes the configuration directory
@@ -65,6 +69,21 @@ func (s *Store) GetReposPath() string {
 	return filepath.Join(s.configDir, ReposFile)
 }
 
+// GetTokensPath returns the full path to the repository tokens JSON file.
+func (s *Store) GetTokensPath() string {
+	return filepath.Join(s.configDir, TokensFile)
+}
+
+// GetCredentialsPath returns the full path to the credential vault JSON file.
+func (s *Store) GetCredentialsPath() string {
+	return filepath.Join(s.configDir, CredsFile)
+}
+
+// GetSecretKeyPath returns the full path to the generated secret key file.
+func (s *Store) GetSecretKeyPath() string {
+	return filepath.Join(s.configDir, SecretKeyFile)
+}
+
 // GetPIDFilePath returns the full path to the PID file.
 func (s *Store) GetPIDFilePath() string {
 	return filepath.Join(s.configDir, PIDFile)
@@ -0,0 +1,150 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/Gemini8532/gitwapp/pkg/models"
+)
+
+// LoadCredentials reads the credential vault and returns all credentials
+// with their secrets decrypted.
+func (s *Store) LoadCredentials() ([]models.Credential, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	creds, err := s.readCredentials()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range creds {
+		if err := s.decryptCredential(&creds[i]); err != nil {
+			return nil, err
+		}
+	}
+	return creds, nil
+}
+
+// LoadCredential returns a single decrypted credential, or nil if no
+// credential with that ID exists.
+func (s *Store) LoadCredential(id string) (*models.Credential, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	creds, err := s.readCredentials()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range creds {
+		if c.ID == id {
+			if err := s.decryptCredential(&c); err != nil {
+				return nil, err
+			}
+			return &c, nil
+		}
+	}
+	return nil, nil
+}
+
+// SaveCredential encrypts and stores a credential, replacing any existing
+// credential with the same ID.
+func (s *Store) SaveCredential(cred models.Credential) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	creds, err := s.readCredentials()
+	if err != nil {
+		return err
+	}
+
+	if cred.Secret, err = s.encrypt(cred.Secret); err != nil {
+		return err
+	}
+	if cred.Passphrase != "" {
+		if cred.Passphrase, err = s.encrypt(cred.Passphrase); err != nil {
+			return err
+		}
+	}
+
+	updated := []models.Credential{}
+	replaced := false
+	for _, c := range creds {
+		if c.ID == cred.ID {
+			updated = append(updated, cred)
+			replaced = true
+			continue
+		}
+		updated = append(updated, c)
+	}
+	if !replaced {
+		updated = append(updated, cred)
+	}
+
+	return s.writeCredentials(updated)
+}
+
+// DeleteCredential removes a credential from the vault. It reports whether
+// a credential was removed.
+func (s *Store) DeleteCredential(id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	creds, err := s.readCredentials()
+	if err != nil {
+		return false, err
+	}
+
+	updated := []models.Credential{}
+	for _, c := range creds {
+		if c.ID != id {
+			updated = append(updated, c)
+		}
+	}
+	if len(updated) == len(creds) {
+		return false, nil
+	}
+
+	return true, s.writeCredentials(updated)
+}
+
+// decryptCredential decrypts the secret fields in place. The caller must hold s.mu.
+func (s *Store) decryptCredential(c *models.Credential) error {
+	var err error
+	if c.Secret, err = s.decrypt(c.Secret); err != nil {
+		return err
+	}
+	if c.Passphrase != "" {
+		if c.Passphrase, err = s.decrypt(c.Passphrase); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readCredentials reads the still-encrypted vault. The caller must hold s.mu.
+func (s *Store) readCredentials() ([]models.Credential, error) {
+	data, err := os.ReadFile(s.GetCredentialsPath())
+	if os.IsNotExist(err) {
+		return []models.Credential{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var creds []models.Credential
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return nil, err
+	}
+	return creds, nil
+}
+
+// writeCredentials writes the encrypted vault. The caller must hold s.mu.
+func (s *Store) writeCredentials(creds []models.Credential) error {
+	data, err := json.MarshalIndent(creds, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.GetCredentialsPath(), data, 0600)
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Gemini8532/gitwapp/pkg/models"
+)
+
+func TestCredentials(t *testing.T) {
+	dir := t.TempDir()
+	s := newTestStore(t, dir)
+
+	cred := models.Credential{ID: "c1", Name: "GitHub", Type: models.CredentialSSH, Secret: "-----BEGIN KEY-----", Passphrase: "hunter2"}
+	if err := s.SaveCredential(cred); err != nil {
+		t.Fatalf("SaveCredential failed: %v", err)
+	}
+	if err := s.SaveCredential(models.Credential{ID: "c2", Name: "Gitea", Type: models.CredentialHTTPS, Secret: "token"}); err != nil {
+		t.Fatalf("SaveCredential failed: %v", err)
+	}
+
+	// Secrets are encrypted at rest
+	data, _ := os.ReadFile(s.GetCredentialsPath())
+	if strings.Contains(string(data), "BEGIN KEY") || strings.Contains(string(data), "hunter2") {
+		t.Errorf("Expected encrypted secrets, got %s", data)
+	}
+
+	// Replacing keeps a single entry
+	cred.Name = "GitHub work"
+	if err := s.SaveCredential(cred); err != nil {
+		t.Fatalf("SaveCredential failed: %v", err)
+	}
+	creds, err := s.LoadCredentials()
+	if err != nil || len(creds) != 2 {
+		t.Fatalf("Expected 2 credentials, got %v (err %v)", creds, err)
+	}
+
+	removed, err := s.DeleteCredential("c2")
+	if err != nil || !removed {
+		t.Errorf("Expected c2 to be removed, got %v (err %v)", removed, err)
+	}
+	if removed, _ := s.DeleteCredential("c2"); removed {
+		t.Error("Expected nothing to remove")
+	}
+	if c, err := s.LoadCredential("c2"); err != nil || c != nil {
+		t.Errorf("Expected no credential, got %+v (err %v)", c, err)
+	}
+}
+
+func TestCredentialBindingReload(t *testing.T) {
+	dir := t.TempDir()
+	s := newTestStore(t, dir)
+
+	if err := s.SaveCredential(models.Credential{ID: "c1", Name: "GitHub", Type: models.CredentialSSH, Secret: "-----BEGIN KEY-----", Passphrase: "hunter2"}); err != nil {
+		t.Fatalf("SaveCredential failed: %v", err)
+	}
+	if err := s.SaveRepositories([]models.Repository{{ID: "r1", Name: "app", Path: "/src/app", CredentialID: "c1"}}); err != nil {
+		t.Fatalf("SaveRepositories failed: %v", err)
+	}
+	if err := s.SaveRepoToken(models.RepoToken{RepoID: "r1", Username: "alice", Token: "ghp_secret"}); err != nil {
+		t.Fatalf("SaveRepoToken failed: %v", err)
+	}
+
+	// A restarted server finds the bound credential and the token again
+	reloaded := newTestStore(t, dir)
+	repos, err := reloaded.LoadRepositories()
+	if err != nil || len(repos) != 1 || repos[0].CredentialID != "c1" {
+		t.Fatalf("Expected repository bound to c1, got %+v (err %v)", repos, err)
+	}
+	cred, err := reloaded.LoadCredential(repos[0].CredentialID)
+	if err != nil || cred == nil {
+		t.Fatalf("Expected credential c1, got %+v (err %v)", cred, err)
+	}
+	if cred.Secret != "-----BEGIN KEY-----" || cred.Passphrase != "hunter2" {
+		t.Errorf("Unexpected decrypted credential: %+v", cred)
+	}
+	token, err := reloaded.LoadRepoToken("r1")
+	if err != nil || token == nil || token.Username != "alice" || token.Token != "ghp_secret" {
+		t.Errorf("Unexpected repository token: %+v (err %v)", token, err)
+	}
+
+	removed, err := reloaded.DeleteRepoToken("r1")
+	if err != nil || !removed {
+		t.Errorf("Expected token to be removed, got %v (err %v)", removed, err)
+	}
+	if token, _ := reloaded.LoadRepoToken("r1"); token != nil {
+		t.Errorf("Expected no token, got %+v", token)
+	}
+}
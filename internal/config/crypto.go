@@ -0,0 +1,118 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// SecretEnvVar names the environment variable holding the server secret
+// from which the encryption key for stored credentials is derived. When it
+// is unset a random secret is generated and kept in the config directory.
+const SecretEnvVar = "GITWAPP_SECRET"
+
+// encryptionKey returns the AES-256 key used to encrypt secrets at rest,
+// creating the secret key file on first use. The caller must hold s.mu.
+func (s *Store) encryptionKey() ([]byte, error) {
+	if s.key != nil {
+		return s.key, nil
+	}
+
+	secret := []byte(os.Getenv(SecretEnvVar))
+	if len(secret) == 0 {
+		var err error
+		if secret, err = s.loadOrCreateSecret(); err != nil {
+			return nil, err
+		}
+	}
+
+	key, err := hkdf.Key(sha256.New, secret, nil, "gitwapp credential encryption", 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive encryption key: %w", err)
+	}
+
+	s.key = key
+	return key, nil
+}
+
+// loadOrCreateSecret reads the random server secret from the config
+// directory, generating it if it does not exist yet.
+func (s *Store) loadOrCreateSecret() ([]byte, error) {
+	path := s.GetSecretKeyPath()
+
+	secret, err := os.ReadFile(path)
+	if err == nil {
+		return secret, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	secret = make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, secret); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, secret, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write secret key: %w", err)
+	}
+	return secret, nil
+}
+
+// encrypt seals plaintext with AES-GCM and returns it base64 encoded with
+// the nonce prepended. The caller must hold s.mu.
+func (s *Store) encrypt(plaintext string) (string, error) {
+	gcm, err := s.cipher()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decrypt reverses encrypt. The caller must hold s.mu.
+func (s *Store) decrypt(encoded string) (string, error) {
+	gcm, err := s.cipher()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", errors.New("ciphertext too short")
+	}
+
+	plaintext, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// cipher returns an AES-GCM AEAD keyed with the store's encryption key.
+func (s *Store) cipher() (cipher.AEAD, error) {
+	key, err := s.encryptionKey()
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
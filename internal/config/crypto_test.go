@@ -0,0 +1,103 @@
+package config
+
+import (
+	"encoding/base64"
+	"os"
+	"testing"
+)
+
+// newTestStore creates a store in a temporary directory using a generated
+// secret.
+func newTestStore(t *testing.T, dir string) *Store {
+	t.Helper()
+	t.Setenv(SecretEnvVar, "")
+	s, err := NewStoreWithDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	return s
+}
+
+func TestEncryptRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	s := newTestStore(t, dir)
+
+	sealed, err := s.encrypt("ghp_secret")
+	if err != nil {
+		t.Fatalf("encrypt failed: %v", err)
+	}
+	if sealed == "ghp_secret" {
+		t.Error("Expected ciphertext to differ from plaintext")
+	}
+	again, _ := s.encrypt("ghp_secret")
+	if again == sealed {
+		t.Error("Expected a fresh nonce for each encryption")
+	}
+
+	plain, err := s.decrypt(sealed)
+	if err != nil || plain != "ghp_secret" {
+		t.Errorf("Expected ghp_secret, got %q (err %v)", plain, err)
+	}
+
+	// The generated secret is kept, so a new store can still decrypt
+	if info, err := os.Stat(s.GetSecretKeyPath()); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected secret key file with mode 0600, got %v (err %v)", info, err)
+	}
+	plain, err = newTestStore(t, dir).decrypt(sealed)
+	if err != nil || plain != "ghp_secret" {
+		t.Errorf("Expected ghp_secret after reload, got %q (err %v)", plain, err)
+	}
+}
+
+func TestDecryptWrongKey(t *testing.T) {
+	sealed, err := newTestStore(t, t.TempDir()).encrypt("ghp_secret")
+	if err != nil {
+		t.Fatalf("encrypt failed: %v", err)
+	}
+
+	// Another generated secret
+	if _, err := newTestStore(t, t.TempDir()).decrypt(sealed); err == nil {
+		t.Error("Expected decryption with another generated secret to fail")
+	}
+
+	// Secrets from the environment take precedence over the key file
+	dir := t.TempDir()
+	t.Setenv(SecretEnvVar, "one")
+	s, _ := NewStoreWithDir(dir)
+	sealed, err = s.encrypt("ghp_secret")
+	if err != nil {
+		t.Fatalf("encrypt failed: %v", err)
+	}
+	t.Setenv(SecretEnvVar, "two")
+	s, _ = NewStoreWithDir(dir)
+	if _, err := s.decrypt(sealed); err == nil {
+		t.Error("Expected decryption with another environment secret to fail")
+	}
+	t.Setenv(SecretEnvVar, "one")
+	s, _ = NewStoreWithDir(dir)
+	if plain, err := s.decrypt(sealed); err != nil || plain != "ghp_secret" {
+		t.Errorf("Expected ghp_secret, got %q (err %v)", plain, err)
+	}
+}
+
+func TestDecryptTampered(t *testing.T) {
+	s := newTestStore(t, t.TempDir())
+	sealed, err := s.encrypt("ghp_secret")
+	if err != nil {
+		t.Fatalf("encrypt failed: %v", err)
+	}
+
+	data, _ := base64.StdEncoding.DecodeString(sealed)
+	data[len(data)-1] ^= 0x01
+	tampered := base64.StdEncoding.EncodeToString(data)
+
+	for name, encoded := range map[string]string{
+		"tampered":   tampered,
+		"truncated":  base64.StdEncoding.EncodeToString(data[:4]),
+		"not base64": "%%%",
+	} {
+		if _, err := s.decrypt(encoded); err == nil {
+			t.Errorf("Expected %s ciphertext to fail", name)
+		}
+	}
+}
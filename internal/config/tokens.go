@@ -0,0 +1,109 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/Gemini8532/gitwapp/pkg/models"
+)
+
+// LoadRepoToken returns the decrypted HTTPS token stored for a repository,
+// or nil if none is stored.
+func (s *Store) LoadRepoToken(repoID string) (*models.RepoToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.readTokens()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range tokens {
+		if t.RepoID == repoID {
+			plain, err := s.decrypt(t.Token)
+			if err != nil {
+				return nil, err
+			}
+			t.Token = plain
+			return &t, nil
+		}
+	}
+	return nil, nil
+}
+
+// SaveRepoToken encrypts and stores the HTTPS token for a repository,
+// replacing any existing one.
+func (s *Store) SaveRepoToken(token models.RepoToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.readTokens()
+	if err != nil {
+		return err
+	}
+
+	encrypted, err := s.encrypt(token.Token)
+	if err != nil {
+		return err
+	}
+	token.Token = encrypted
+
+	updated := []models.RepoToken{token}
+	for _, t := range tokens {
+		if t.RepoID != token.RepoID {
+			updated = append(updated, t)
+		}
+	}
+
+	return s.writeTokens(updated)
+}
+
+// DeleteRepoToken removes the stored token of a repository. It reports
+// whether a token was removed.
+func (s *Store) DeleteRepoToken(repoID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.readTokens()
+	if err != nil {
+		return false, err
+	}
+
+	updated := []models.RepoToken{}
+	for _, t := range tokens {
+		if t.RepoID != repoID {
+			updated = append(updated, t)
+		}
+	}
+	if len(updated) == len(tokens) {
+		return false, nil
+	}
+
+	return true, s.writeTokens(updated)
+}
+
+// readTokens reads the still-encrypted tokens. The caller must hold s.mu.
+func (s *Store) readTokens() ([]models.RepoToken, error) {
+	data, err := os.ReadFile(s.GetTokensPath())
+	if os.IsNotExist(err) {
+		return []models.RepoToken{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var tokens []models.RepoToken
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// writeTokens writes the encrypted tokens. The caller must hold s.mu.
+func (s *Store) writeTokens(tokens []models.RepoToken) error {
+	data, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.GetTokensPath(), data, 0600)
+}
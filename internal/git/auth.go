@@ -0,0 +1,189 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+// Credentials are explicit credentials for a remote operation. When
+// supplied they take precedence over the server's ssh-agent, SSH keys and
+// git credential helper. Only the fields matching the remote's protocol
+// are used; a Passphrase without SSHKey unlocks the server's key files.
+type Credentials struct {
+	Username   string
+	Password   string // HTTPS password or personal access token
+	SSHKey     []byte // PEM-encoded SSH private key
+	Passphrase string // Passphrase of the SSH key, if it is encrypted
+}
+
+// credential holds the attributes exchanged with git's credential helper
+// protocol (see gitcredentials(7)), along with the repository whose
+// configuration selects the helper.
+type credential struct {
+	RepoPath string
+	Protocol string
+	Host     string
+	Path     string
+	Username string
+	Password string
+}
+
+// getAuth determines the appropriate authentication method (SSH or HTTPS)
+// based on the URL of the named remote. When the credentials came from the
+// git credential helper they are returned as well, so the caller can report
+// back whether they worked with settleCredential.
+func getAuth(repoPath string, remoteName string, creds *Credentials) (transport.AuthMethod, *credential, error) {
+	r, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Get the remote URL to determine auth type
+	remote, err := r.Remote(remoteName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(remote.Config().URLs) == 0 {
+		return nil, nil, fmt.Errorf("no remote URL configured")
+	}
+
+	ep, err := transport.NewEndpoint(remote.Config().URLs[0])
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid remote URL: %w", err)
+	}
+
+	switch ep.Protocol {
+	case "file", "git":
+		// Local paths and the git:// protocol are unauthenticated
+		return nil, nil, nil
+	case "ssh":
+		auth, err := getSSHAuth(ep, creds)
+		return auth, nil, err
+	}
+
+	// Explicit credentials, either supplied or embedded in the URL
+	if creds != nil && creds.Password != "" {
+		return &http.BasicAuth{Username: creds.Username, Password: creds.Password}, nil, nil
+	}
+	if ep.Password != "" {
+		return &http.BasicAuth{Username: ep.User, Password: ep.Password}, nil, nil
+	}
+
+	// For HTTPS, use git credential helper
+	cred, err := credentialFill(endpointCredential(repoPath, ep))
+	if err != nil {
+		return nil, nil, err
+	}
+	return &http.BasicAuth{Username: cred.Username, Password: cred.Password}, cred, nil
+}
+
+// endpointCredential builds the credential helper attributes for an endpoint.
+func endpointCredential(repoPath string, ep *transport.Endpoint) credential {
+	host := ep.Host
+	if ep.Port != 0 && ep.Port != defaultPort(ep.Protocol) {
+		host += ":" + strconv.Itoa(ep.Port)
+	}
+	return credential{
+		RepoPath: repoPath,
+		Protocol: ep.Protocol,
+		Host:     host,
+		Path:     strings.TrimPrefix(ep.Path, "/"),
+		Username: ep.User,
+	}
+}
+
+// defaultPort returns the well-known port of an HTTP protocol.
+func defaultPort(protocol string) int {
+	switch protocol {
+	case "http":
+		return 80
+	case "https":
+		return 443
+	}
+	return 0
+}
+
+// encode serializes the credential in the helper's key=value format.
+func (c credential) encode() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "protocol=%s\nhost=%s\n", c.Protocol, c.Host)
+	if c.Path != "" {
+		fmt.Fprintf(&b, "path=%s\n", c.Path)
+	}
+	if c.Username != "" {
+		fmt.Fprintf(&b, "username=%s\n", c.Username)
+	}
+	if c.Password != "" {
+		fmt.Fprintf(&b, "password=%s\n", c.Password)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// runCredentialHelper runs "git credential <action>" with the given input
+// inside the repository, so repository-level helper settings apply.
+// Interactive prompting is disabled since the server has no terminal.
+func runCredentialHelper(action string, c credential) (string, error) {
+	cmd := exec.Command("git", "credential", action)
+	cmd.Dir = c.RepoPath
+	cmd.Stdin = strings.NewReader(c.encode())
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	output, err := cmd.Output()
+	return string(output), err
+}
+
+// credentialFill retrieves HTTPS credentials using the git credential helper.
+func credentialFill(c credential) (*credential, error) {
+	output, err := runCredentialHelper("fill", c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credentials: %w", err)
+	}
+
+	// Parse the output
+	filled := c
+	for _, line := range strings.Split(output, "\n") {
+		if v, ok := strings.CutPrefix(line, "username="); ok {
+			filled.Username = v
+		} else if v, ok := strings.CutPrefix(line, "password="); ok {
+			filled.Password = v
+		}
+	}
+
+	if filled.Username == "" || filled.Password == "" {
+		return nil, fmt.Errorf("no credentials found")
+	}
+
+	return &filled, nil
+}
+
+// settleCredential tells the credential helper whether a credential it
+// supplied worked: it is approved (stored) after a successful operation and
+// rejected (erased) after an authentication failure.
+func settleCredential(c *credential, opErr error) {
+	if c == nil {
+		return
+	}
+
+	action := ""
+	switch {
+	case opErr == nil || opErr == git.NoErrAlreadyUpToDate:
+		action = "approve"
+	case errors.Is(opErr, transport.ErrAuthenticationRequired),
+		errors.Is(opErr, transport.ErrAuthorizationFailed):
+		action = "reject"
+	default:
+		return
+	}
+
+	// Failures here only affect credential caching, not the operation itself
+	runCredentialHelper(action, *c)
+}
@@ -0,0 +1,84 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+func TestEndpointCredential(t *testing.T) {
+	tests := []struct {
+		url  string
+		want credential
+	}{
+		{"https://github.com/org/repo.git", credential{Protocol: "https", Host: "github.com", Path: "org/repo.git"}},
+		{"https://gitlab.example.com:8443/group/proj.git", credential{Protocol: "https", Host: "gitlab.example.com:8443", Path: "group/proj.git"}},
+		{"https://alice@git.example.com:443/x.git", credential{Protocol: "https", Host: "git.example.com", Path: "x.git", Username: "alice"}},
+		{"http://intranet/repo", credential{Protocol: "http", Host: "intranet", Path: "repo"}},
+	}
+
+	for _, tt := range tests {
+		ep, err := transport.NewEndpoint(tt.url)
+		if err != nil {
+			t.Fatalf("NewEndpoint(%q) failed: %v", tt.url, err)
+		}
+		if got := endpointCredential("", ep); got != tt.want {
+			t.Errorf("endpointCredential(%q) = %+v, want %+v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestGetAuthCredentialHelper(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	defer os.RemoveAll(repoPath)
+
+	// A credential helper that records each request and answers "get"
+	logDir := t.TempDir()
+	helper := filepath.Join(logDir, "helper.sh")
+	script := "#!/bin/sh\ncat > \"" + logDir + "/$1.txt\"\nif [ \"$1\" = get ]; then echo username=bob; echo password=s3cret; fi\n"
+	if err := os.WriteFile(helper, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runGit(repoPath, "config", "credential.helper", helper); err != nil {
+		t.Fatalf("Failed to configure helper: %v", err)
+	}
+	if err := AddRemote(repoPath, "origin", "https://gitlab.example.com:8443/group/proj.git"); err != nil {
+		t.Fatal(err)
+	}
+
+	auth, cred, err := getAuth(repoPath, "origin", nil)
+	if err != nil {
+		t.Fatalf("getAuth failed: %v", err)
+	}
+	basic, ok := auth.(*http.BasicAuth)
+	if !ok || basic.Username != "bob" || basic.Password != "s3cret" {
+		t.Errorf("Unexpected auth from helper: %#v", auth)
+	}
+
+	request, _ := os.ReadFile(filepath.Join(logDir, "get.txt"))
+	if !strings.Contains(string(request), "host=gitlab.example.com:8443") {
+		t.Errorf("Expected helper to receive the remote host, got:\n%s", request)
+	}
+
+	settleCredential(cred, nil)
+	if _, err := os.Stat(filepath.Join(logDir, "store.txt")); err != nil {
+		t.Error("Expected credential to be approved after success")
+	}
+	settleCredential(cred, transport.ErrAuthorizationFailed)
+	if _, err := os.Stat(filepath.Join(logDir, "erase.txt")); err != nil {
+		t.Error("Expected credential to be rejected after auth failure")
+	}
+
+	// Explicit credentials bypass the helper
+	auth, cred, err = getAuth(repoPath, "origin", &Credentials{Username: "ci", Password: "token"})
+	if err != nil || cred != nil {
+		t.Fatalf("Expected explicit credentials without helper, got cred %v err %v", cred, err)
+	}
+	if basic, ok := auth.(*http.BasicAuth); !ok || basic.Password != "token" {
+		t.Errorf("Unexpected explicit auth: %#v", auth)
+	}
+}
@@ -0,0 +1,96 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Blame attributes each line of a file at a revision to the commit that
+// last changed it.
+type Blame struct {
+	Commit string      `json:"commit"`
+	Path   string      `json:"path"`
+	Hunks  []BlameHunk `json:"hunks"`
+}
+
+// BlameHunk is a run of consecutive lines last changed by the same commit.
+type BlameHunk struct {
+	Hash        string    `json:"hash"`
+	Author      string    `json:"author"`
+	AuthorEmail string    `json:"author_email"`
+	Date        time.Time `json:"date"`
+	Summary     string    `json:"summary"`
+	StartLine   int       `json:"start_line"` // Line number of the first line, starting at 1
+	Lines       []string  `json:"lines"`
+}
+
+// GetBlame blames the file at file as of the given branch, tag or revision
+// (HEAD when empty).
+func GetBlame(path string, ref string, file string) (*Blame, error) {
+	r, err := git.PlainOpen(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := resolveCommit(r, ref)
+	if err != nil {
+		return nil, err
+	}
+	file = strings.Trim(file, "/")
+
+	entry, err := findFile(c, file)
+	if err != nil {
+		return nil, err
+	}
+	size, err := r.Storer.EncodedObjectSize(entry.Hash)
+	if err != nil {
+		return nil, err
+	}
+	if size > MaxBlobSize {
+		return nil, fmt.Errorf("%w: %s is %d bytes, the limit is %d", ErrBlobTooLarge, file, size, MaxBlobSize)
+	}
+
+	result, err := git.Blame(c, file)
+	if err != nil {
+		if errors.Is(err, object.ErrFileNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrPathNotFound, file)
+		}
+		return nil, err
+	}
+
+	blame := &Blame{Commit: c.Hash.String(), Path: file, Hunks: []BlameHunk{}}
+	summaries := make(map[plumbing.Hash]string)
+	for i, line := range result.Lines {
+		if n := len(blame.Hunks); n > 0 && blame.Hunks[n-1].Hash == line.Hash.String() {
+			blame.Hunks[n-1].Lines = append(blame.Hunks[n-1].Lines, line.Text)
+			continue
+		}
+
+		summary, ok := summaries[line.Hash]
+		if !ok {
+			lc, err := r.CommitObject(line.Hash)
+			if err != nil {
+				return nil, err
+			}
+			summary = commitSummary(lc.Message)
+			summaries[line.Hash] = summary
+		}
+		blame.Hunks = append(blame.Hunks, BlameHunk{
+			Hash:        line.Hash.String(),
+			Author:      line.AuthorName,
+			AuthorEmail: line.Author,
+			Date:        line.Date,
+			Summary:     summary,
+			StartLine:   i + 1,
+			Lines:       []string{line.Text},
+		})
+	}
+
+	return blame, nil
+}
@@ -0,0 +1,51 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestGetBlame(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	defer os.RemoveAll(repoPath)
+
+	first := commitFile(t, repoPath, "file.txt", "one\ntwo\nthree\nfour\n", "Add file", "alice")
+	second := commitFile(t, repoPath, "file.txt", "one\nTWO\nTHREE\nfour\nfive\n", "Shout", "bob")
+
+	blame, err := GetBlame(repoPath, "", "file.txt")
+	if err != nil {
+		t.Fatalf("GetBlame failed: %v", err)
+	}
+	if blame.Commit != second || len(blame.Hunks) != 4 {
+		t.Fatalf("Expected 4 hunks at HEAD, got %+v", blame.Hunks)
+	}
+
+	want := []struct {
+		hash  string
+		start int
+		lines int
+	}{{first, 1, 1}, {second, 2, 2}, {first, 4, 1}, {second, 5, 1}}
+	for i, w := range want {
+		h := blame.Hunks[i]
+		if h.Hash != w.hash || h.StartLine != w.start || len(h.Lines) != w.lines {
+			t.Errorf("Hunk %d: expected %s at line %d with %d line(s), got %s at %d with %v", i, w.hash[:7], w.start, w.lines, h.Hash[:7], h.StartLine, h.Lines)
+		}
+	}
+	if h := blame.Hunks[1]; h.Author != "bob" || h.AuthorEmail != "bob@example.com" || h.Summary != "Shout" || h.Lines[1] != "THREE" {
+		t.Errorf("Unexpected hunk: %+v", h)
+	}
+
+	// Older revisions blame their own content
+	blame, _ = GetBlame(repoPath, first, "file.txt")
+	if len(blame.Hunks) != 1 || len(blame.Hunks[0].Lines) != 4 {
+		t.Errorf("Expected a single hunk at the first commit, got %+v", blame.Hunks)
+	}
+
+	if _, err := GetBlame(repoPath, "", "missing.txt"); !errors.Is(err, ErrPathNotFound) {
+		t.Errorf("Expected ErrPathNotFound, got %v", err)
+	}
+	if _, err := GetBlame(repoPath, "no-such-branch", "file.txt"); !errors.Is(err, ErrRefNotFound) {
+		t.Errorf("Expected ErrRefNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,252 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+var (
+	// ErrBranchNotFound is returned when a local branch does not exist.
+	ErrBranchNotFound = errors.New("branch not found")
+	// ErrBranchExists is returned when creating or renaming onto an existing branch.
+	ErrBranchExists = errors.New("branch already exists")
+	// ErrInvalidBranchName is returned for names git would reject.
+	ErrInvalidBranchName = errors.New("invalid branch name")
+	// ErrBranchCheckedOut is returned when deleting the current branch.
+	ErrBranchCheckedOut = errors.New("branch is checked out")
+	// ErrBranchNotMerged is returned when deleting an unmerged branch without force.
+	ErrBranchNotMerged = errors.New("branch is not fully merged")
+)
+
+// Branch describes a local branch and its upstream tracking state.
+type Branch struct {
+	Name       string    `json:"name"`
+	Hash       string    `json:"hash"`
+	Summary    string    `json:"summary"`
+	CommitDate time.Time `json:"commit_date"`
+	IsHead     bool      `json:"is_head"`
+	Upstream   string    `json:"upstream,omitempty"` // e.g. "origin/main"
+	Ahead      int       `json:"ahead"`
+	Behind     int       `json:"behind"`
+}
+
+// ListBranches returns all local branches sorted by name, including
+// upstream tracking information and ahead/behind counts for each.
+func ListBranches(path string) ([]Branch, error) {
+	r, err := git.PlainOpen(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := r.Config()
+	if err != nil {
+		return nil, err
+	}
+
+	var headName plumbing.ReferenceName
+	if head, err := r.Head(); err == nil {
+		headName = head.Name()
+	}
+
+	iter, err := r.Branches()
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	branches := []Branch{}
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		b := Branch{
+			Name:   ref.Name().Short(),
+			Hash:   ref.Hash().String(),
+			IsHead: ref.Name() == headName,
+		}
+
+		if c, err := r.CommitObject(ref.Hash()); err == nil {
+			b.Summary = commitSummary(c.Message)
+			b.CommitDate = c.Committer.When
+		}
+
+		if bc, ok := cfg.Branches[b.Name]; ok && bc.Remote != "" && bc.Merge != "" {
+			upstreamRef := upstreamRefName(bc.Remote, bc.Merge)
+			b.Upstream = upstreamRef.Short()
+			if up, err := r.Reference(upstreamRef, true); err == nil {
+				if b.Ahead, b.Behind, err = aheadBehind(path, ref.Hash(), up.Hash()); err != nil {
+					return err
+				}
+			}
+		}
+
+		branches = append(branches, b)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(branches, func(i, j int) bool { return branches[i].Name < branches[j].Name })
+	return branches, nil
+}
+
+// CreateBranch creates a new local branch at startPoint (default HEAD) and
+// optionally checks it out.
+func CreateBranch(path string, name string, startPoint string, checkout bool) error {
+	r, err := git.PlainOpen(path)
+	if err != nil {
+		return err
+	}
+
+	refName, err := branchRefName(name)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.Reference(refName, false); err == nil {
+		return fmt.Errorf("%w: %s", ErrBranchExists, name)
+	}
+
+	hash, err := resolveRef(r, startPoint)
+	if err != nil {
+		return err
+	}
+
+	if err := r.Storer.SetReference(plumbing.NewHashReference(refName, hash)); err != nil {
+		return err
+	}
+
+	if checkout {
+		return CheckoutBranch(path, name)
+	}
+	return nil
+}
+
+// CheckoutBranch switches the working tree to the given local branch. Local
+// modifications are carried over when they do not conflict. It uses the git
+// command-line tool as go-git refuses to switch with a dirty worktree.
+func CheckoutBranch(path string, name string) error {
+	r, err := git.PlainOpen(path)
+	if err != nil {
+		return err
+	}
+
+	refName, err := branchRefName(name)
+	if err != nil {
+		return err
+	}
+	if _, err := r.Reference(refName, false); err != nil {
+		return fmt.Errorf("%w: %s", ErrBranchNotFound, name)
+	}
+
+	_, err = runGit(path, "checkout", name, "--")
+	return err
+}
+
+// RenameBranch renames a local branch, carrying over its configuration.
+func RenameBranch(path string, oldName string, newName string) error {
+	r, err := git.PlainOpen(path)
+	if err != nil {
+		return err
+	}
+
+	oldRef, err := branchRefName(oldName)
+	if err != nil {
+		return err
+	}
+	newRef, err := branchRefName(newName)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.Reference(oldRef, false); err != nil {
+		return fmt.Errorf("%w: %s", ErrBranchNotFound, oldName)
+	}
+	if _, err := r.Reference(newRef, false); err == nil {
+		return fmt.Errorf("%w: %s", ErrBranchExists, newName)
+	}
+
+	_, err = runGit(path, "branch", "-m", oldName, newName)
+	return err
+}
+
+// DeleteBranch deletes a local branch. Unless force is set, branches that
+// are not merged into their upstream or HEAD are refused.
+func DeleteBranch(path string, name string, force bool) error {
+	r, err := git.PlainOpen(path)
+	if err != nil {
+		return err
+	}
+
+	refName, err := branchRefName(name)
+	if err != nil {
+		return err
+	}
+	if _, err := r.Reference(refName, false); err != nil {
+		return fmt.Errorf("%w: %s", ErrBranchNotFound, name)
+	}
+	if head, err := r.Head(); err == nil && head.Name() == refName {
+		return fmt.Errorf("%w: %s", ErrBranchCheckedOut, name)
+	}
+
+	flag := "-d"
+	if force {
+		flag = "-D"
+	}
+	if _, err := runGit(path, "branch", flag, name); err != nil {
+		if strings.Contains(err.Error(), "not fully merged") {
+			return fmt.Errorf("%w: %s", ErrBranchNotMerged, name)
+		}
+		return err
+	}
+	return nil
+}
+
+// branchRefName validates a short branch name and returns its full reference name.
+func branchRefName(name string) (plumbing.ReferenceName, error) {
+	refName := plumbing.NewBranchReferenceName(name)
+	if name == "" || strings.HasPrefix(name, "-") || refName.Validate() != nil {
+		return "", fmt.Errorf("%w: %q", ErrInvalidBranchName, name)
+	}
+	return refName, nil
+}
+
+// upstreamRefName maps a branch's configured remote and merge ref to the
+// local reference that tracks it.
+func upstreamRefName(remote string, merge plumbing.ReferenceName) plumbing.ReferenceName {
+	if remote == "." {
+		return merge
+	}
+	return plumbing.NewRemoteReferenceName(remote, merge.Short())
+}
+
+// aheadBehind counts the commits reachable from local but not upstream
+// (ahead) and from upstream but not local (behind).
+func aheadBehind(path string, local, upstream plumbing.Hash) (int, int, error) {
+	if local == upstream {
+		return 0, 0, nil
+	}
+
+	output, err := runGit(path, "rev-list", "--left-right", "--count", local.String()+"..."+upstream.String())
+	if err != nil {
+		return 0, 0, err
+	}
+	counts := strings.Fields(output)
+	if len(counts) != 2 {
+		return 0, 0, fmt.Errorf("unexpected rev-list output: %q", output)
+	}
+	ahead, err := strconv.Atoi(counts[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	behind, err := strconv.Atoi(counts[1])
+	if err != nil {
+		return 0, 0, err
+	}
+	return ahead, behind, nil
+}
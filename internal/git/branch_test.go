@@ -0,0 +1,120 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func findBranch(branches []Branch, name string) *Branch {
+	for i := range branches {
+		if branches[i].Name == name {
+			return &branches[i]
+		}
+	}
+	return nil
+}
+
+func TestBranchLifecycle(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	defer os.RemoveAll(repoPath)
+
+	if err := CreateBranch(repoPath, "feature/x", "", false); err != nil {
+		t.Fatalf("CreateBranch failed: %v", err)
+	}
+	if err := CreateBranch(repoPath, "feature/x", "", false); !errors.Is(err, ErrBranchExists) {
+		t.Errorf("Expected ErrBranchExists, got %v", err)
+	}
+	if err := CreateBranch(repoPath, "bad..name", "", false); !errors.Is(err, ErrInvalidBranchName) {
+		t.Errorf("Expected ErrInvalidBranchName, got %v", err)
+	}
+
+	if err := CheckoutBranch(repoPath, "feature/x"); err != nil {
+		t.Fatalf("CheckoutBranch failed: %v", err)
+	}
+	status, _ := GetStatus(repoPath)
+	if status.Branch != "feature/x" {
+		t.Errorf("Expected current branch feature/x, got %s", status.Branch)
+	}
+
+	if err := DeleteBranch(repoPath, "feature/x", false); !errors.Is(err, ErrBranchCheckedOut) {
+		t.Errorf("Expected ErrBranchCheckedOut, got %v", err)
+	}
+
+	if err := RenameBranch(repoPath, "feature/x", "feature/y"); err != nil {
+		t.Fatalf("RenameBranch failed: %v", err)
+	}
+	branches, err := ListBranches(repoPath)
+	if err != nil {
+		t.Fatalf("ListBranches failed: %v", err)
+	}
+	if b := findBranch(branches, "feature/y"); b == nil || !b.IsHead {
+		t.Errorf("Expected renamed branch feature/y to be HEAD, got %+v", branches)
+	}
+
+	if err := CheckoutBranch(repoPath, "master"); err != nil {
+		t.Fatalf("CheckoutBranch failed: %v", err)
+	}
+	commitFile(t, repoPath, "other.txt", "x", "Diverge", "alice")
+	if err := CheckoutBranch(repoPath, "feature/y"); err != nil {
+		t.Fatalf("CheckoutBranch failed: %v", err)
+	}
+	commitFile(t, repoPath, "unmerged.txt", "x", "Unmerged work", "alice")
+	if err := CheckoutBranch(repoPath, "master"); err != nil {
+		t.Fatalf("CheckoutBranch failed: %v", err)
+	}
+
+	if err := DeleteBranch(repoPath, "feature/y", false); !errors.Is(err, ErrBranchNotMerged) {
+		t.Errorf("Expected ErrBranchNotMerged, got %v", err)
+	}
+	if err := DeleteBranch(repoPath, "feature/y", true); err != nil {
+		t.Fatalf("DeleteBranch failed: %v", err)
+	}
+	if err := DeleteBranch(repoPath, "feature/y", true); !errors.Is(err, ErrBranchNotFound) {
+		t.Errorf("Expected ErrBranchNotFound, got %v", err)
+	}
+}
+
+func TestListBranchesUpstream(t *testing.T) {
+	local, remote := setupTestRepoWithRemote(t)
+	defer os.RemoveAll(local)
+	defer os.RemoveAll(remote)
+
+	commitFile(t, local, "a.txt", "a", "Local 1", "alice")
+	commitFile(t, local, "b.txt", "b", "Local 2", "alice")
+
+	branches, err := ListBranches(local)
+	if err != nil {
+		t.Fatalf("ListBranches failed: %v", err)
+	}
+	b := findBranch(branches, "master")
+	if b == nil {
+		t.Fatalf("Expected master branch, got %+v", branches)
+	}
+	if b.Upstream != "origin/master" {
+		t.Errorf("Expected upstream origin/master, got %q", b.Upstream)
+	}
+	if b.Ahead != 2 || b.Behind != 0 {
+		t.Errorf("Expected ahead 2 behind 0, got ahead %d behind %d", b.Ahead, b.Behind)
+	}
+	if b.Summary != "Local 2" {
+		t.Errorf("Expected summary of tip commit, got %q", b.Summary)
+	}
+
+	// Move the upstream on by a commit of its own so the branches diverge
+	if err := CreateBranch(local, "upstream-work", "origin/master", true); err != nil {
+		t.Fatalf("CreateBranch failed: %v", err)
+	}
+	upstream := commitFile(t, local, "c.txt", "c", "Remote work", "bob")
+	if _, err := runGit(local, "update-ref", "refs/remotes/origin/master", upstream); err != nil {
+		t.Fatal(err)
+	}
+
+	branches, err = ListBranches(local)
+	if err != nil {
+		t.Fatalf("ListBranches failed: %v", err)
+	}
+	if b = findBranch(branches, "master"); b.Ahead != 2 || b.Behind != 1 {
+		t.Errorf("Expected ahead 2 behind 1, got ahead %d behind %d", b.Ahead, b.Behind)
+	}
+}
@@ -0,0 +1,190 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// coAuthorTrailer is the trailer crediting additional authors of a commit.
+const coAuthorTrailer = "Co-authored-by"
+
+var (
+	// ErrNothingToCommit is returned when a commit would not change anything
+	// and empty commits are not allowed, or when there is no commit to amend.
+	ErrNothingToCommit = errors.New("nothing to commit")
+	// ErrMissingIdentity is returned when no author is given and none is
+	// configured in git.
+	ErrMissingIdentity = errors.New("no author identity configured")
+	// ErrInvalidIdentity is returned for missing names or emails, or ones git
+	// cannot store.
+	ErrInvalidIdentity = errors.New("invalid identity")
+)
+
+// Identity is the name and email of a commit author or committer.
+type Identity struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// CommitOptions configures Commit.
+type CommitOptions struct {
+	Author     *Identity  // Defaults to the git configuration; when amending, to the amended commit's author
+	Committer  *Identity  // Defaults to the author, or the git configuration when amending
+	Amend      bool       // Replace the last commit, keeping its message when none is given
+	AllowEmpty bool       // Commit even if nothing changed
+	CoAuthors  []Identity // Credited with Co-authored-by trailers
+}
+
+// Commit commits the staged changes with the given message and returns the
+// hash of the new commit.
+func Commit(path string, msg string, opts CommitOptions) (string, error) {
+	r, err := git.PlainOpen(path)
+	if err != nil {
+		return "", err
+	}
+	w, err := r.Worktree()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	commitOpts := &git.CommitOptions{AllowEmptyCommits: opts.AllowEmpty}
+	if err := validateIdentity(opts.Author); err != nil {
+		return "", err
+	}
+	if err := validateIdentity(opts.Committer); err != nil {
+		return "", err
+	}
+	for i := range opts.CoAuthors {
+		if err := validateIdentity(&opts.CoAuthors[i]); err != nil {
+			return "", err
+		}
+	}
+	if opts.Author != nil {
+		commitOpts.Author = signature(*opts.Author, now)
+	}
+	if opts.Committer != nil {
+		commitOpts.Committer = signature(*opts.Committer, now)
+	}
+
+	if opts.Amend {
+		head, err := r.Head()
+		if err != nil {
+			if errors.Is(err, plumbing.ErrReferenceNotFound) {
+				return "", fmt.Errorf("%w: no commit to amend", ErrNothingToCommit)
+			}
+			return "", err
+		}
+		last, err := r.CommitObject(head.Hash())
+		if err != nil {
+			return "", err
+		}
+
+		if strings.TrimSpace(msg) == "" {
+			msg = last.Message
+		}
+		if commitOpts.Author == nil {
+			// Like git commit --amend, keep the authorship of the amended commit
+			author := last.Author
+			commitOpts.Author = &author
+			if commitOpts.Committer == nil {
+				commitOpts.Committer, err = configSignature(r, now)
+				if err != nil {
+					return "", err
+				}
+			}
+		}
+
+		// go-git keeps only the first parent when amending, so merges are
+		// recommitted on top of all of their parents instead
+		if len(last.ParentHashes) > 1 {
+			commitOpts.Parents = last.ParentHashes
+		} else {
+			commitOpts.Amend = true
+		}
+	}
+
+	hash, err := w.Commit(withCoAuthors(msg, opts.CoAuthors), commitOpts)
+	if err != nil {
+		switch {
+		case errors.Is(err, git.ErrEmptyCommit):
+			return "", fmt.Errorf("%w: no changes staged", ErrNothingToCommit)
+		case errors.Is(err, git.ErrMissingAuthor):
+			return "", fmt.Errorf("%w: set user.name and user.email or pass an author", ErrMissingIdentity)
+		}
+		return "", err
+	}
+	return hash.String(), nil
+}
+
+// withCoAuthors appends a Co-authored-by trailer for each co-author not yet
+// credited in msg, joining an existing trailer block at the end of msg.
+func withCoAuthors(msg string, coAuthors []Identity) string {
+	var trailers []string
+	for _, id := range coAuthors {
+		trailer := fmt.Sprintf("%s: %s <%s>", coAuthorTrailer, strings.TrimSpace(id.Name), strings.TrimSpace(id.Email))
+		if !strings.Contains(msg, trailer) && !slices.Contains(trailers, trailer) {
+			trailers = append(trailers, trailer)
+		}
+	}
+	if len(trailers) == 0 {
+		return msg
+	}
+	msg = strings.TrimRight(msg, "\n")
+
+	separator := "\n\n"
+	if lines := strings.Split(msg, "\n"); len(lines) > 1 && isTrailer(lines[len(lines)-1]) {
+		separator = "\n"
+	}
+	return msg + separator + strings.Join(trailers, "\n") + "\n"
+}
+
+// isTrailer reports whether a message line looks like a "Key: value" trailer.
+func isTrailer(line string) bool {
+	key, value, ok := strings.Cut(line, ": ")
+	return ok && key != "" && value != "" && !strings.ContainsAny(key, " \t")
+}
+
+// configSignature returns the identity configured in git, as go-git would
+// use for the author of a commit.
+func configSignature(r *git.Repository, when time.Time) (*object.Signature, error) {
+	opts := &git.CommitOptions{}
+	if err := opts.Validate(r); err != nil {
+		if errors.Is(err, git.ErrMissingAuthor) {
+			return nil, fmt.Errorf("%w: set user.name and user.email or pass a committer", ErrMissingIdentity)
+		}
+		return nil, err
+	}
+	sig := *opts.Author
+	sig.When = when
+	return &sig, nil
+}
+
+// validateIdentity checks that an optional identity can be stored in a commit.
+func validateIdentity(id *Identity) error {
+	if id == nil {
+		return nil
+	}
+	if strings.TrimSpace(id.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidIdentity)
+	}
+	if strings.TrimSpace(id.Email) == "" {
+		return fmt.Errorf("%w: email is required", ErrInvalidIdentity)
+	}
+	if strings.ContainsAny(id.Name+id.Email, "<>\n") {
+		return fmt.Errorf("%w: %q <%s>", ErrInvalidIdentity, id.Name, id.Email)
+	}
+	return nil
+}
+
+// signature converts an identity to a go-git signature.
+func signature(id Identity, when time.Time) *object.Signature {
+	return &object.Signature{Name: strings.TrimSpace(id.Name), Email: strings.TrimSpace(id.Email), When: when}
+}
@@ -0,0 +1,81 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// ErrInvalidParent is returned when a requested parent index does not exist.
+var ErrInvalidParent = errors.New("invalid parent index")
+
+// CommitDetail is a commit together with the changes it introduced.
+type CommitDetail struct {
+	CommitInfo
+	DiffParent string       `json:"diff_parent,omitempty"`
+	Additions  int          `json:"additions"`
+	Deletions  int          `json:"deletions"`
+	Files      []FileChange `json:"files"`
+}
+
+// GetCommit returns the metadata and per-file diff of a single commit.
+// The diff is computed against the parent at index parent (0 for the first
+// parent); root commits are diffed against an empty tree.
+func GetCommit(path string, rev string, parent int) (*CommitDetail, error) {
+	r, err := git.PlainOpen(path)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := resolveRef(r, rev)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := r.CommitObject(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &CommitDetail{CommitInfo: newCommitInfo(c)}
+
+	var parentTree *object.Tree
+	if c.NumParents() > 0 {
+		if parent < 0 || parent >= c.NumParents() {
+			return nil, fmt.Errorf("%w: commit has %d parent(s)", ErrInvalidParent, c.NumParents())
+		}
+		p, err := c.Parent(parent)
+		if err != nil {
+			return nil, err
+		}
+		if parentTree, err = p.Tree(); err != nil {
+			return nil, err
+		}
+		detail.DiffParent = p.Hash.String()
+	} else if parent != 0 {
+		return nil, fmt.Errorf("%w: commit has no parents", ErrInvalidParent)
+	}
+
+	// A nil tree compares as empty, which covers root commits
+	patch, err := parentTree.Patch(tree)
+	if err != nil {
+		return nil, err
+	}
+
+	if detail.Files, err = fileChangesFromPatch(patch, true); err != nil {
+		return nil, err
+	}
+	for _, f := range detail.Files {
+		detail.Additions += f.Additions
+		detail.Deletions += f.Deletions
+	}
+
+	return detail, nil
+}
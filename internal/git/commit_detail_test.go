@@ -0,0 +1,52 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestGetCommit(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	defer os.RemoveAll(repoPath)
+
+	hash := commitFile(t, repoPath, "README.md", "Hello\nWorld\n", "Update readme", "alice")
+
+	detail, err := GetCommit(repoPath, hash, 0)
+	if err != nil {
+		t.Fatalf("GetCommit failed: %v", err)
+	}
+	if detail.Hash != hash || detail.Summary != "Update readme" {
+		t.Errorf("Unexpected commit metadata: %+v", detail.CommitInfo)
+	}
+	if len(detail.Files) != 1 {
+		t.Fatalf("Expected 1 changed file, got %d", len(detail.Files))
+	}
+	f := detail.Files[0]
+	if f.Path != "README.md" || f.Status != ChangeModified {
+		t.Errorf("Unexpected file change: %+v", f)
+	}
+	if f.Additions != 2 || f.Deletions != 1 {
+		t.Errorf("Expected +2/-1, got +%d/-%d", f.Additions, f.Deletions)
+	}
+	if !strings.Contains(f.Patch, "+World") {
+		t.Errorf("Expected patch to contain added line, got:\n%s", f.Patch)
+	}
+
+	// Root commit is diffed against an empty tree
+	root, err := GetCommit(repoPath, detail.Parents[0], 0)
+	if err != nil {
+		t.Fatalf("GetCommit on root failed: %v", err)
+	}
+	if len(root.Files) != 1 || root.Files[0].Status != ChangeAdded {
+		t.Errorf("Expected root commit to add README.md, got %+v", root.Files)
+	}
+
+	if _, err := GetCommit(repoPath, hash, 1); !errors.Is(err, ErrInvalidParent) {
+		t.Errorf("Expected ErrInvalidParent, got %v", err)
+	}
+	if _, err := GetCommit(repoPath, "deadbeef", 0); !errors.Is(err, ErrRefNotFound) {
+		t.Errorf("Expected ErrRefNotFound, got %v", err)
+	}
+}
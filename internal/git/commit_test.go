@@ -0,0 +1,163 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func TestCommitIdentity(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	defer os.RemoveAll(repoPath)
+
+	os.WriteFile(filepath.Join(repoPath, "a.txt"), []byte("a\n"), 0644)
+	StageFile(repoPath, "a.txt")
+
+	author := &Identity{Name: "Ada", Email: "ada@example.com"}
+	committer := &Identity{Name: "Bot", Email: "bot@example.com"}
+	hash, err := Commit(repoPath, "Add a", CommitOptions{Author: author, Committer: committer})
+	if err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	r, _ := git.PlainOpen(repoPath)
+	c, err := r.CommitObject(plumbing.NewHash(hash))
+	if err != nil {
+		t.Fatalf("Commit %s not found: %v", hash, err)
+	}
+	if c.Author.Name != "Ada" || c.Author.Email != "ada@example.com" {
+		t.Errorf("Unexpected author %s", c.Author)
+	}
+	if c.Committer.Name != "Bot" || c.Committer.Email != "bot@example.com" {
+		t.Errorf("Unexpected committer %s", c.Committer)
+	}
+
+	// Nothing is staged any more
+	if _, err := Commit(repoPath, "Again", CommitOptions{Author: author}); !errors.Is(err, ErrNothingToCommit) {
+		t.Errorf("Expected ErrNothingToCommit, got %v", err)
+	}
+	if _, err := Commit(repoPath, "Again", CommitOptions{Author: author, AllowEmpty: true}); err != nil {
+		t.Errorf("Expected empty commit to be allowed, got %v", err)
+	}
+
+	for _, id := range []Identity{{Name: " ", Email: "x@example.com"}, {Name: "Ada"}, {Name: "Eve <eve>", Email: "eve@example.com"}} {
+		if _, err := Commit(repoPath, "Bad", CommitOptions{Author: &id, AllowEmpty: true}); !errors.Is(err, ErrInvalidIdentity) {
+			t.Errorf("Expected ErrInvalidIdentity for %+v, got %v", id, err)
+		}
+	}
+}
+
+func TestCommitAmend(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	defer os.RemoveAll(repoPath)
+	setIdentity(t, repoPath)
+
+	first := commitFile(t, repoPath, "a.txt", "a\n", "Add a\n", "alice")
+	r, _ := git.PlainOpen(repoPath)
+	original, _ := r.CommitObject(plumbing.NewHash(first))
+
+	os.WriteFile(filepath.Join(repoPath, "b.txt"), []byte("b\n"), 0644)
+	StageFile(repoPath, "b.txt")
+
+	// Without a message or author, both are kept and the configured
+	// identity becomes the committer
+	hash, err := Commit(repoPath, "", CommitOptions{Amend: true})
+	if err != nil {
+		t.Fatalf("Amend failed: %v", err)
+	}
+	c, _ := r.CommitObject(plumbing.NewHash(hash))
+	if hash == first || c.Message != "Add a\n" {
+		t.Errorf("Expected amended commit to keep the message, got %q", c.Message)
+	}
+	if c.Author.Name != "alice" || !c.Author.When.Equal(original.Author.When) {
+		t.Errorf("Expected author to be kept, got %s", c.Author)
+	}
+	if c.Committer.Name != "Test" {
+		t.Errorf("Expected configured committer, got %s", c.Committer)
+	}
+	if len(c.ParentHashes) != 1 || c.ParentHashes[0] != original.ParentHashes[0] {
+		t.Errorf("Expected amended commit to replace the original, got parents %v", c.ParentHashes)
+	}
+	if _, err := c.File("b.txt"); err != nil {
+		t.Errorf("Expected b.txt in amended commit: %v", err)
+	}
+
+	// An explicit author and message replace the old ones
+	hash, err = Commit(repoPath, "Add a and b", CommitOptions{Amend: true, Author: &Identity{Name: "Bob", Email: "bob@example.com"}})
+	if err != nil {
+		t.Fatalf("Amend failed: %v", err)
+	}
+	c, _ = r.CommitObject(plumbing.NewHash(hash))
+	if c.Message != "Add a and b" || c.Author.Name != "Bob" || c.Committer.Name != "Bob" {
+		t.Errorf("Unexpected amended commit: %q by %s, committed by %s", c.Message, c.Author, c.Committer)
+	}
+}
+
+func TestCommitAmendMerge(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	defer os.RemoveAll(repoPath)
+	setIdentity(t, repoPath)
+
+	main, _ := runGit(repoPath, "rev-parse", "--abbrev-ref", "HEAD")
+	main = strings.TrimSpace(main)
+	if err := CreateBranch(repoPath, "feature", "", true); err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, repoPath, "feature.txt", "feature\n", "Add feature", "bob")
+	if err := CheckoutBranch(repoPath, main); err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, repoPath, "main.txt", "main\n", "Work on main", "alice")
+	if _, err := runGit(repoPath, "merge", "--no-edit", "feature"); err != nil {
+		t.Fatal(err)
+	}
+
+	r, _ := git.PlainOpen(repoPath)
+	head, _ := r.Head()
+	merge, _ := r.CommitObject(head.Hash())
+
+	hash, err := Commit(repoPath, "Merge feature", CommitOptions{Amend: true, AllowEmpty: true})
+	if err != nil {
+		t.Fatalf("Amend failed: %v", err)
+	}
+	c, _ := r.CommitObject(plumbing.NewHash(hash))
+	if len(c.ParentHashes) != 2 || c.ParentHashes[0] != merge.ParentHashes[0] || c.ParentHashes[1] != merge.ParentHashes[1] {
+		t.Errorf("Expected merge parents %v to be kept, got %v", merge.ParentHashes, c.ParentHashes)
+	}
+	if c.Message != "Merge feature" {
+		t.Errorf("Unexpected message %q", c.Message)
+	}
+
+	head, _ = r.Head()
+	if head.Name().Short() != main || head.Hash().String() != hash {
+		t.Errorf("Expected %s to point at the amended merge, got %s", main, head)
+	}
+}
+
+func TestWithCoAuthors(t *testing.T) {
+	ada := Identity{Name: "Ada", Email: "ada@example.com"}
+	bob := Identity{Name: "Bob", Email: "bob@example.com"}
+
+	tests := []struct {
+		msg       string
+		coAuthors []Identity
+		want      string
+	}{
+		{"Fix bug", nil, "Fix bug"},
+		{"Fix bug\n", []Identity{ada}, "Fix bug\n\nCo-authored-by: Ada <ada@example.com>\n"},
+		{"Fix bug", []Identity{ada, bob, ada}, "Fix bug\n\nCo-authored-by: Ada <ada@example.com>\nCo-authored-by: Bob <bob@example.com>\n"},
+		{"Fix bug\n\nSigned-off-by: Ada <ada@example.com>\n", []Identity{bob},
+			"Fix bug\n\nSigned-off-by: Ada <ada@example.com>\nCo-authored-by: Bob <bob@example.com>\n"},
+		{"Fix bug\n\nCo-authored-by: Ada <ada@example.com>\n", []Identity{ada}, "Fix bug\n\nCo-authored-by: Ada <ada@example.com>\n"},
+	}
+	for _, tt := range tests {
+		if got := withCoAuthors(tt.msg, tt.coAuthors); got != tt.want {
+			t.Errorf("withCoAuthors(%q) = %q, want %q", tt.msg, got, tt.want)
+		}
+	}
+}
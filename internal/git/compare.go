@@ -0,0 +1,109 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// ErrNoMergeBase is returned when two refs have no common history.
+var ErrNoMergeBase = errors.New("no common ancestor")
+
+// Comparison describes how head differs from base, as reviewed before
+// merging head into base: the commits on head since the two diverged and
+// the changes between their merge base and head.
+type Comparison struct {
+	Base      string       `json:"base"`
+	Head      string       `json:"head"`
+	MergeBase string       `json:"merge_base"`
+	Ahead     int          `json:"ahead"`  // Commits on head missing from base
+	Behind    int          `json:"behind"` // Commits on base missing from head
+	Commits   []CommitInfo `json:"commits"`
+	Truncated bool         `json:"truncated"` // Commits holds only the newest MaxLogLimit commits
+	Additions int          `json:"additions"`
+	Deletions int          `json:"deletions"`
+	Files     []FileChange `json:"files"`
+}
+
+// Compare compares two branches, tags or revisions. Commits are listed
+// newest first; an empty head compares HEAD.
+func Compare(path string, base string, head string) (*Comparison, error) {
+	r, err := git.PlainOpen(path)
+	if err != nil {
+		return nil, err
+	}
+
+	baseHash, err := resolveRef(r, base)
+	if err != nil {
+		return nil, err
+	}
+	headHash, err := resolveRef(r, head)
+	if err != nil {
+		return nil, err
+	}
+
+	baseCommit, err := r.CommitObject(baseHash)
+	if err != nil {
+		return nil, err
+	}
+	headCommit, err := r.CommitObject(headHash)
+	if err != nil {
+		return nil, err
+	}
+
+	bases, err := baseCommit.MergeBase(headCommit)
+	if err != nil {
+		return nil, err
+	}
+	if len(bases) == 0 {
+		return nil, fmt.Errorf("%w: %s and %s", ErrNoMergeBase, base, head)
+	}
+	mergeBase := bases[0]
+
+	cmp := &Comparison{
+		Base:      baseHash.String(),
+		Head:      headHash.String(),
+		MergeBase: mergeBase.Hash.String(),
+		Commits:   []CommitInfo{},
+	}
+
+	hashes, err := runGit(path, "rev-list", baseHash.String()+".."+headHash.String())
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Fields(hashes)
+	cmp.Ahead = len(lines)
+	if len(lines) > MaxLogLimit {
+		lines = lines[:MaxLogLimit]
+		cmp.Truncated = true
+	}
+	for _, h := range lines {
+		c, err := r.CommitObject(plumbing.NewHash(h))
+		if err != nil {
+			return nil, err
+		}
+		cmp.Commits = append(cmp.Commits, newCommitInfo(c))
+	}
+
+	behind, err := runGit(path, "rev-list", "--count", headHash.String()+".."+baseHash.String())
+	if err != nil {
+		return nil, err
+	}
+	if cmp.Behind, err = strconv.Atoi(strings.TrimSpace(behind)); err != nil {
+		return nil, err
+	}
+
+	if cmp.Files, err = treeChanges(mergeBase, headCommit); err != nil {
+		return nil, err
+	}
+	for _, f := range cmp.Files {
+		cmp.Additions += f.Additions
+		cmp.Deletions += f.Deletions
+	}
+
+	return cmp, nil
+}
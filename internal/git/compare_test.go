@@ -0,0 +1,87 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	defer os.RemoveAll(repoPath)
+
+	main, _ := runGit(repoPath, "rev-parse", "--abbrev-ref", "HEAD")
+	main = strings.TrimSpace(main)
+	base := commitFile(t, repoPath, "shared.txt", "shared\n", "Add shared", "alice")
+
+	// The feature branch adds two commits, main moves on by one
+	if err := CreateBranch(repoPath, "feature", "", true); err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, repoPath, "feature.txt", "one\n", "Start feature", "bob")
+	tip := commitFile(t, repoPath, "feature.txt", "one\ntwo\n", "Finish feature", "bob")
+	if err := CheckoutBranch(repoPath, main); err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, repoPath, "main.txt", "main\n", "Work on main", "alice")
+
+	cmp, err := Compare(repoPath, main, "feature")
+	if err != nil {
+		t.Fatalf("Compare failed: %v", err)
+	}
+	if cmp.MergeBase != base || cmp.Head != tip {
+		t.Errorf("Unexpected merge base %s or head %s", cmp.MergeBase, cmp.Head)
+	}
+	if cmp.Ahead != 2 || cmp.Behind != 1 || cmp.Truncated {
+		t.Errorf("Expected 2 ahead and 1 behind, got %d and %d", cmp.Ahead, cmp.Behind)
+	}
+	if len(cmp.Commits) != 2 || cmp.Commits[0].Summary != "Finish feature" {
+		t.Errorf("Expected feature commits newest first, got %+v", cmp.Commits)
+	}
+
+	// Changes on main since the merge base are not part of the diff
+	if len(cmp.Files) != 1 || cmp.Files[0].Path != "feature.txt" || cmp.Files[0].Status != ChangeAdded {
+		t.Fatalf("Expected only feature.txt added, got %+v", cmp.Files)
+	}
+	if cmp.Additions != 2 || !strings.Contains(cmp.Files[0].Patch, "+two") {
+		t.Errorf("Unexpected changes: +%d\n%s", cmp.Additions, cmp.Files[0].Patch)
+	}
+
+	// Comparing the other way round shows main's work
+	cmp, _ = Compare(repoPath, "feature", main)
+	if cmp.Ahead != 1 || cmp.Behind != 2 || len(cmp.Files) != 1 || cmp.Files[0].Path != "main.txt" {
+		t.Errorf("Unexpected reverse comparison: %+v", cmp)
+	}
+
+	// An empty head compares HEAD
+	if cmp, _ = Compare(repoPath, "feature", ""); cmp.Ahead != 1 {
+		t.Errorf("Expected HEAD to be compared, got %+v", cmp)
+	}
+
+	if _, err := Compare(repoPath, "no-such-branch", "feature"); !errors.Is(err, ErrRefNotFound) {
+		t.Errorf("Expected ErrRefNotFound, got %v", err)
+	}
+}
+
+func TestCompareUnrelated(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	defer os.RemoveAll(repoPath)
+	setIdentity(t, repoPath)
+
+	if _, err := runGit(repoPath, "checkout", "--orphan", "other"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runGit(repoPath, "commit", "-m", "Unrelated root"); err != nil {
+		t.Fatal(err)
+	}
+
+	out, _ := runGit(repoPath, "rev-list", "--max-parents=0", "--all")
+	roots := strings.Fields(out)
+	if len(roots) != 2 {
+		t.Fatalf("Expected 2 root commits, got %v", roots)
+	}
+	if _, err := Compare(repoPath, roots[0], roots[1]); !errors.Is(err, ErrNoMergeBase) {
+		t.Errorf("Expected ErrNoMergeBase, got %v", err)
+	}
+}
@@ -0,0 +1,255 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// Sides of a conflict accepted by TakeSide.
+const (
+	SideOurs   = "ours"
+	SideTheirs = "theirs"
+)
+
+var (
+	// ErrNotConflicted is returned when a path has no unresolved conflict.
+	ErrNotConflicted = errors.New("path is not conflicted")
+	// ErrInvalidSide is returned for a side other than SideOurs or SideTheirs.
+	ErrInvalidSide = errors.New("invalid side")
+	// ErrNoOperation is returned when aborting or continuing while no
+	// merge or similar operation is in progress.
+	ErrNoOperation = errors.New("no operation in progress")
+	// ErrUnresolvedConflicts is returned when continuing an operation while
+	// conflicts remain.
+	ErrUnresolvedConflicts = errors.New("unresolved conflicts remain")
+)
+
+// ConflictVersions holds the contents of the three versions of a
+// conflicted file. A version is nil when the file does not exist on that
+// side. Binary files are reported without content.
+type ConflictVersions struct {
+	Path   string  `json:"path"`
+	Binary bool    `json:"binary"`
+	Base   *string `json:"base"`
+	Ours   *string `json:"ours"`
+	Theirs *string `json:"theirs"`
+}
+
+// GetConflictVersions returns the base, ours and theirs versions of a conflicted file.
+func GetConflictVersions(path string, file string) (*ConflictVersions, error) {
+	r, err := git.PlainOpen(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := findConflict(path, file)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := &ConflictVersions{Path: c.Path}
+	for _, v := range []struct {
+		blob string
+		dst  **string
+	}{
+		{c.Base, &versions.Base},
+		{c.Ours, &versions.Ours},
+		{c.Theirs, &versions.Theirs},
+	} {
+		if v.blob == "" {
+			continue
+		}
+		content, err := readBlob(r, v.blob)
+		if err != nil {
+			return nil, err
+		}
+		if isBinary(content) {
+			versions.Binary = true
+			versions.Base, versions.Ours, versions.Theirs = nil, nil, nil
+			return versions, nil
+		}
+		s := string(content)
+		*v.dst = &s
+	}
+	return versions, nil
+}
+
+// WriteResolution writes the resolved content of a conflicted file to the
+// working tree. The file stays conflicted until MarkResolved is called.
+func WriteResolution(path string, file string, content []byte) error {
+	c, err := findConflict(path, file)
+	if err != nil {
+		return err
+	}
+
+	full := filepath.Join(path, filepath.FromSlash(c.Path))
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(full); err == nil {
+		mode = info.Mode().Perm()
+	}
+	return os.WriteFile(full, content, mode)
+}
+
+// MarkResolved stages the working tree version of a conflicted file,
+// marking its conflict as resolved. A file removed from the working tree is
+// resolved as deleted.
+func MarkResolved(path string, file string) error {
+	c, err := findConflict(path, file)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Lstat(filepath.Join(path, filepath.FromSlash(c.Path))); os.IsNotExist(err) {
+		_, err := runGit(path, "rm", "--cached", "--quiet", "--", c.Path)
+		return err
+	}
+	_, err = runGit(path, "add", "--", c.Path)
+	return err
+}
+
+// TakeSide resolves a conflicted file by taking the whole file from one
+// side and staging it. If the file was deleted on that side it is deleted.
+func TakeSide(path string, file string, side string) error {
+	c, err := findConflict(path, file)
+	if err != nil {
+		return err
+	}
+
+	blob := ""
+	switch side {
+	case SideOurs:
+		blob = c.Ours
+	case SideTheirs:
+		blob = c.Theirs
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidSide, side)
+	}
+
+	if blob == "" {
+		_, err := runGit(path, "rm", "--quiet", "--force", "--", c.Path)
+		return err
+	}
+	if _, err := runGit(path, "checkout", "--"+side, "--", c.Path); err != nil {
+		return err
+	}
+	_, err = runGit(path, "add", "--", c.Path)
+	return err
+}
+
+// AbortOperation aborts the operation in progress, restoring the
+// repository to its state before the operation started. The conflicts of
+// a squash merge, which leaves no operation in progress, are discarded.
+func AbortOperation(path string) error {
+	r, err := git.PlainOpen(path)
+	if err != nil {
+		return err
+	}
+
+	switch repoState(r) {
+	case StateMerging:
+		_, err = runGit(path, "merge", "--abort")
+		return err
+	case StateRebasing:
+		_, err = runGit(path, "rebase", "--abort")
+		return err
+	}
+
+	conflicts, err := Conflicts(path)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) == 0 {
+		return ErrNoOperation
+	}
+	if _, err := runGit(path, "reset", "--merge"); err != nil {
+		return err
+	}
+	// Keep the squash message from prefilling the next commit
+	if dir := gitDir(r); dir != "" {
+		if err := os.Remove(filepath.Join(dir, "SQUASH_MSG")); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// ContinueOperation concludes the operation in progress once all conflicts
+// are resolved, e.g. by creating the merge commit. A rebase that stops on
+// conflicts in a later commit returns ErrRebaseConflict. A resolved squash
+// merge has nothing to continue and is committed like any other change.
+func ContinueOperation(path string) error {
+	r, err := git.PlainOpen(path)
+	if err != nil {
+		return err
+	}
+
+	conflicts, err := Conflicts(path)
+	if err != nil {
+		return err
+	}
+	state := repoState(r)
+	if state == "" && len(conflicts) == 0 {
+		return ErrNoOperation
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("%w: %d file(s)", ErrUnresolvedConflicts, len(conflicts))
+	}
+
+	switch state {
+	case StateMerging:
+		_, err = runGit(path, "commit", "--no-edit")
+		return err
+	case StateRebasing:
+		result, err := RebaseContinue(path)
+		if err != nil {
+			return err
+		}
+		if result.Status == RebaseConflicted {
+			return fmt.Errorf("%w: %d file(s)", ErrRebaseConflict, len(result.Conflicts))
+		}
+		return nil
+	}
+	return ErrNoOperation
+}
+
+// findConflict returns the conflict of a single path.
+func findConflict(path string, file string) (*Conflict, error) {
+	conflicts, err := Conflicts(path)
+	if err != nil {
+		return nil, err
+	}
+	file = filepath.ToSlash(filepath.Clean(file))
+	for _, c := range conflicts {
+		if c.Path == file {
+			return &c, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNotConflicted, file)
+}
+
+// readBlob returns the content of a blob.
+func readBlob(r *git.Repository, id string) ([]byte, error) {
+	blob, err := r.BlobObject(plumbing.NewHash(id))
+	if err != nil {
+		return nil, err
+	}
+	reader, err := blob.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// isBinary reports whether content looks binary, using git's heuristic of
+// a NUL byte within the first 8000 bytes.
+func isBinary(content []byte) bool {
+	return bytes.IndexByte(content[:min(len(content), 8000)], 0) >= 0
+}
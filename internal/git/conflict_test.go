@@ -0,0 +1,153 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// setupConflict creates a repository in the merging state with file.txt
+// conflicted between master ("ours") and feature ("theirs").
+func setupConflict(t *testing.T) string {
+	t.Helper()
+	repoPath := setupTestRepo(t)
+	setIdentity(t, repoPath)
+
+	commitFile(t, repoPath, "file.txt", "base\n", "Base", "alice")
+	commitFile(t, repoPath, "other.txt", "base\n", "Other base", "alice")
+	CreateBranch(repoPath, "feature", "", true)
+	commitFile(t, repoPath, "file.txt", "theirs\n", "Theirs", "alice")
+	commitFile(t, repoPath, "other.txt", "theirs\n", "Other theirs", "alice")
+	CheckoutBranch(repoPath, "master")
+	commitFile(t, repoPath, "file.txt", "ours\n", "Ours", "bob")
+	commitFile(t, repoPath, "other.txt", "ours\n", "Other ours", "bob")
+
+	result, err := Merge(repoPath, MergeOptions{Ref: "feature"})
+	if err != nil || result.Status != MergeConflicted {
+		t.Fatalf("Expected conflicting merge, got %+v (err %v)", result, err)
+	}
+	return repoPath
+}
+
+func TestConflictResolution(t *testing.T) {
+	repoPath := setupConflict(t)
+	defer os.RemoveAll(repoPath)
+
+	versions, err := GetConflictVersions(repoPath, "file.txt")
+	if err != nil {
+		t.Fatalf("GetConflictVersions failed: %v", err)
+	}
+	if versions.Base == nil || *versions.Base != "base\n" || *versions.Ours != "ours\n" || *versions.Theirs != "theirs\n" {
+		t.Errorf("Unexpected versions: %+v", versions)
+	}
+
+	if _, err := GetConflictVersions(repoPath, "README.md"); !errors.Is(err, ErrNotConflicted) {
+		t.Errorf("Expected ErrNotConflicted, got %v", err)
+	}
+	if err := WriteResolution(repoPath, "../escape.txt", []byte("x")); !errors.Is(err, ErrNotConflicted) {
+		t.Errorf("Expected ErrNotConflicted for path outside conflicts, got %v", err)
+	}
+
+	// Continuing is refused while conflicts remain
+	if err := ContinueOperation(repoPath); !errors.Is(err, ErrUnresolvedConflicts) {
+		t.Errorf("Expected ErrUnresolvedConflicts, got %v", err)
+	}
+
+	if err := WriteResolution(repoPath, "file.txt", []byte("merged\n")); err != nil {
+		t.Fatalf("WriteResolution failed: %v", err)
+	}
+	if err := MarkResolved(repoPath, "file.txt"); err != nil {
+		t.Fatalf("MarkResolved failed: %v", err)
+	}
+
+	if err := TakeSide(repoPath, "other.txt", "mine"); !errors.Is(err, ErrInvalidSide) {
+		t.Errorf("Expected ErrInvalidSide, got %v", err)
+	}
+	if err := TakeSide(repoPath, "other.txt", SideTheirs); err != nil {
+		t.Fatalf("TakeSide failed: %v", err)
+	}
+
+	conflicts, _ := Conflicts(repoPath)
+	if len(conflicts) != 0 {
+		t.Fatalf("Expected no conflicts, got %+v", conflicts)
+	}
+
+	if err := ContinueOperation(repoPath); err != nil {
+		t.Fatalf("ContinueOperation failed: %v", err)
+	}
+
+	status, _ := GetStatus(repoPath)
+	if status.State != "" || !status.Clean {
+		t.Errorf("Expected clean repository after merge, got %+v", status)
+	}
+	content, _ := os.ReadFile(filepath.Join(repoPath, "file.txt"))
+	other, _ := os.ReadFile(filepath.Join(repoPath, "other.txt"))
+	if string(content) != "merged\n" || string(other) != "theirs\n" {
+		t.Errorf("Unexpected merged content: %q, %q", content, other)
+	}
+
+	page, _ := Log(repoPath, LogOptions{Limit: 1})
+	if len(page.Commits) != 1 || len(page.Commits[0].Parents) != 2 {
+		t.Errorf("Expected merge commit, got %+v", page.Commits)
+	}
+}
+
+func TestAbortOperation(t *testing.T) {
+	repoPath := setupConflict(t)
+	defer os.RemoveAll(repoPath)
+
+	if err := AbortOperation(repoPath); err != nil {
+		t.Fatalf("AbortOperation failed: %v", err)
+	}
+
+	status, _ := GetStatus(repoPath)
+	if status.State != "" || !status.Clean {
+		t.Errorf("Expected clean repository after abort, got %+v", status)
+	}
+	content, _ := os.ReadFile(filepath.Join(repoPath, "file.txt"))
+	if string(content) != "ours\n" {
+		t.Errorf("Expected our version after abort, got %q", content)
+	}
+
+	if err := AbortOperation(repoPath); !errors.Is(err, ErrNoOperation) {
+		t.Errorf("Expected ErrNoOperation, got %v", err)
+	}
+}
+
+func TestContinueOperationRebaseConflict(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	defer os.RemoveAll(repoPath)
+	setIdentity(t, repoPath)
+
+	// Both feature commits conflict with master
+	CreateBranch(repoPath, "feature", "", true)
+	commitFile(t, repoPath, "README.md", "feature 1", "Feature 1", "alice")
+	commitFile(t, repoPath, "README.md", "feature 2", "Feature 2", "alice")
+	CheckoutBranch(repoPath, "master")
+	commitFile(t, repoPath, "README.md", "main", "Main edit", "bob")
+	CheckoutBranch(repoPath, "feature")
+
+	if result, _ := Rebase(repoPath, RebaseOptions{Onto: "master"}); result.Status != RebaseConflicted {
+		t.Fatalf("Expected conflict, got %+v", result)
+	}
+	WriteResolution(repoPath, "README.md", []byte("resolved 1"))
+	MarkResolved(repoPath, "README.md")
+
+	if err := ContinueOperation(repoPath); !errors.Is(err, ErrRebaseConflict) {
+		t.Fatalf("Expected ErrRebaseConflict on the second commit, got %v", err)
+	}
+	status, _ := GetStatus(repoPath)
+	if status.State != StateRebasing {
+		t.Errorf("Expected rebase to still be in progress, got %q", status.State)
+	}
+
+	WriteResolution(repoPath, "README.md", []byte("resolved 2"))
+	MarkResolved(repoPath, "README.md")
+	if err := ContinueOperation(repoPath); err != nil {
+		t.Fatalf("ContinueOperation failed: %v", err)
+	}
+	if status, _ = GetStatus(repoPath); status.State != "" || status.Branch != "feature" {
+		t.Errorf("Expected finished rebase on feature, got %+v", status)
+	}
+}
@@ -0,0 +1,386 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultDiffContext is the number of context lines git shows around changes.
+const DefaultDiffContext = 3
+
+// maxWordDiffCells bounds the work spent on intra-line highlights of a line
+// pair, measured as the product of their token counts.
+const maxWordDiffCells = 250000
+
+// Line types reported in DiffLine.Type.
+const (
+	LineContext = "context"
+	LineAdded   = "add"
+	LineDeleted = "delete"
+)
+
+// DiffOptions configures Diff.
+type DiffOptions struct {
+	Mode             string // DiffUnstaged, DiffStaged or DiffHead
+	Path             string // Only diff this file or directory
+	Context          int    // Lines of context around changes
+	IgnoreWhitespace bool   // Ignore whitespace when comparing lines
+}
+
+// Diff is the structured diff of a working tree, index or HEAD.
+type Diff struct {
+	Mode  string     `json:"mode"`
+	Files []DiffFile `json:"files"`
+}
+
+// DiffFile is the diff of a single file.
+type DiffFile struct {
+	Path       string     `json:"path"`
+	OldPath    string     `json:"old_path,omitempty"`
+	Status     string     `json:"status"`
+	Similarity int        `json:"similarity,omitempty"` // Percentage, for renames
+	OldMode    string     `json:"old_mode,omitempty"`   // Set when the file mode changed
+	NewMode    string     `json:"new_mode,omitempty"`
+	Binary     bool       `json:"binary"`
+	Additions  int        `json:"additions"`
+	Deletions  int        `json:"deletions"`
+	Hunks      []DiffHunk `json:"hunks"`
+}
+
+// DiffHunk is a block of changes with its surrounding context.
+type DiffHunk struct {
+	Header   string     `json:"header"`
+	OldStart int        `json:"old_start"`
+	OldLines int        `json:"old_lines"`
+	NewStart int        `json:"new_start"`
+	NewLines int        `json:"new_lines"`
+	Lines    []DiffLine `json:"lines"`
+}
+
+// DiffLine is a single line of a hunk. Position is the line number in the
+// file's unified diff text, as used by hunk and line selections when the
+// diff was requested for a single file with the default options.
+type DiffLine struct {
+	Type       string  `json:"type"`
+	Content    string  `json:"content"`
+	OldLine    int     `json:"old_line,omitempty"`
+	NewLine    int     `json:"new_line,omitempty"`
+	Position   int     `json:"position"`
+	NoNewline  bool    `json:"no_newline,omitempty"` // The line has no trailing newline
+	Highlights []Range `json:"highlights,omitempty"` // Changed words within Content
+}
+
+// Range is a half-open range [Start, End) of byte offsets within a line.
+type Range struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// GetDiff returns the structured diff selected by opts, with renames
+// detected and changed words highlighted within modified lines.
+func GetDiff(path string, opts DiffOptions) (*Diff, error) {
+	args, err := diffArgs(path, opts.Mode)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Context < 0 {
+		opts.Context = DefaultDiffContext
+	}
+
+	args = append(args, "--find-renames", "--unified="+strconv.Itoa(opts.Context))
+	if opts.IgnoreWhitespace {
+		args = append(args, "--ignore-all-space")
+	}
+	args = append(args, "--")
+	if opts.Path != "" {
+		args = append(args, opts.Path)
+	}
+
+	output, err := runGit(path, append([]string{"-c", "core.quotePath=false"}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := parseDiff(output)
+	if err != nil {
+		return nil, err
+	}
+	return &Diff{Mode: opts.Mode, Files: files}, nil
+}
+
+// parseDiff parses the output of git diff into files, hunks and lines.
+func parseDiff(output string) ([]DiffFile, error) {
+	files := []DiffFile{}
+	if output == "" {
+		return files, nil
+	}
+
+	var (
+		file     *DiffFile
+		hunk     *DiffHunk
+		position int // Line number within the current file's diff
+		oldLine  int
+		newLine  int
+	)
+	flush := func() {
+		if file != nil {
+			for i := range file.Hunks {
+				highlightWords(file.Hunks[i].Lines)
+			}
+			files = append(files, *file)
+		}
+	}
+
+	for _, line := range strings.Split(strings.TrimSuffix(output, "\n"), "\n") {
+		if rest, ok := strings.CutPrefix(line, "diff --git "); ok {
+			flush()
+			file = &DiffFile{Status: ChangeModified, Hunks: []DiffHunk{}}
+			file.Path = gitHeaderPath(rest)
+			hunk, position = nil, 1
+			continue
+		}
+		if file == nil {
+			return nil, fmt.Errorf("malformed diff: %q", line)
+		}
+		position++
+
+		if hunk == nil {
+			parseFileHeader(file, line)
+			if m := hunkHeader.FindStringSubmatch(line); m != nil {
+				file.Hunks = append(file.Hunks, newDiffHunk(line, m))
+				hunk = &file.Hunks[len(file.Hunks)-1]
+				oldLine, newLine = hunk.OldStart, hunk.NewStart
+			}
+			continue
+		}
+
+		if m := hunkHeader.FindStringSubmatch(line); m != nil {
+			file.Hunks = append(file.Hunks, newDiffHunk(line, m))
+			hunk = &file.Hunks[len(file.Hunks)-1]
+			oldLine, newLine = hunk.OldStart, hunk.NewStart
+			continue
+		}
+		if line == "" {
+			continue
+		}
+
+		dl := DiffLine{Content: line[1:], Position: position}
+		switch line[0] {
+		case ' ':
+			dl.Type, dl.OldLine, dl.NewLine = LineContext, oldLine, newLine
+			oldLine++
+			newLine++
+		case '+':
+			dl.Type, dl.NewLine = LineAdded, newLine
+			newLine++
+			file.Additions++
+		case '-':
+			dl.Type, dl.OldLine = LineDeleted, oldLine
+			oldLine++
+			file.Deletions++
+		case '\\':
+			if n := len(hunk.Lines); n > 0 {
+				hunk.Lines[n-1].NoNewline = true
+			}
+			continue
+		default:
+			continue
+		}
+		hunk.Lines = append(hunk.Lines, dl)
+	}
+	flush()
+	return files, nil
+}
+
+// parseFileHeader applies an extended header line of a file's diff.
+func parseFileHeader(file *DiffFile, line string) {
+	switch {
+	case strings.HasPrefix(line, "new file mode "):
+		file.Status = ChangeAdded
+	case strings.HasPrefix(line, "deleted file mode "):
+		file.Status = ChangeDeleted
+	case strings.HasPrefix(line, "old mode "):
+		file.OldMode = strings.TrimPrefix(line, "old mode ")
+	case strings.HasPrefix(line, "new mode "):
+		file.NewMode = strings.TrimPrefix(line, "new mode ")
+	case strings.HasPrefix(line, "similarity index "):
+		file.Similarity, _ = strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(line, "similarity index "), "%"))
+	case strings.HasPrefix(line, "rename from "):
+		file.Status = ChangeRenamed
+		file.OldPath = unquotePath(strings.TrimPrefix(line, "rename from "))
+	case strings.HasPrefix(line, "rename to "):
+		file.Path = unquotePath(strings.TrimPrefix(line, "rename to "))
+	case strings.HasPrefix(line, "--- "):
+		if p := patchPath(line); p != "/dev/null" && file.Status != ChangeRenamed {
+			file.Path = strings.TrimPrefix(p, "a/")
+		}
+	case strings.HasPrefix(line, "+++ "):
+		if p := patchPath(line); p != "/dev/null" {
+			file.Path = strings.TrimPrefix(p, "b/")
+		}
+	case strings.HasPrefix(line, "Binary files "):
+		file.Binary = true
+	}
+}
+
+// newDiffHunk creates a hunk from its header line and hunkHeader match.
+func newDiffHunk(line string, m []string) DiffHunk {
+	h := DiffHunk{Header: line, OldLines: 1, NewLines: 1, Lines: []DiffLine{}}
+	h.OldStart, _ = strconv.Atoi(m[1])
+	if m[2] != "" {
+		h.OldLines, _ = strconv.Atoi(m[2])
+	}
+	h.NewStart, _ = strconv.Atoi(m[3])
+	if m[4] != "" {
+		h.NewLines, _ = strconv.Atoi(m[4])
+	}
+	return h
+}
+
+// gitHeaderPath extracts the path from the "a/<path> b/<path>" part of a
+// "diff --git" line. Renames are resolved later from the extended headers,
+// so both paths are assumed to be equal here.
+func gitHeaderPath(rest string) string {
+	if strings.HasPrefix(rest, `"`) {
+		if i := strings.Index(rest[1:], `" `); i >= 0 {
+			return strings.TrimPrefix(unquotePath(rest[:i+2]), "a/")
+		}
+	}
+	if len(rest) >= 5 && len(rest)%2 == 1 {
+		return rest[2 : (len(rest)-1)/2]
+	}
+	return rest
+}
+
+// patchPath returns the path of a "---" or "+++" line. Git terminates
+// paths containing spaces with a tab.
+func patchPath(line string) string {
+	return unquotePath(strings.TrimSuffix(line[4:], "\t"))
+}
+
+// unquotePath decodes a path that git quoted because of special characters.
+func unquotePath(p string) string {
+	if strings.HasPrefix(p, `"`) {
+		if s, err := strconv.Unquote(p); err == nil {
+			return s
+		}
+	}
+	return p
+}
+
+// highlightWords marks the changed words of modified lines: each run of
+// deleted lines directly followed by added lines is compared pairwise.
+func highlightWords(lines []DiffLine) {
+	for i := 0; i < len(lines); {
+		if lines[i].Type != LineDeleted {
+			i++
+			continue
+		}
+		delStart := i
+		for i < len(lines) && lines[i].Type == LineDeleted {
+			i++
+		}
+		addStart := i
+		for i < len(lines) && lines[i].Type == LineAdded {
+			i++
+		}
+
+		for k := 0; delStart+k < addStart && addStart+k < i; k++ {
+			old, new := &lines[delStart+k], &lines[addStart+k]
+			old.Highlights, new.Highlights = wordDiff(old.Content, new.Content)
+		}
+	}
+}
+
+// wordDiff returns the byte ranges of the words that differ between two
+// lines, based on their longest common subsequence of words. Lines without
+// any word in common are left unhighlighted, as the whole line changed.
+func wordDiff(a, b string) ([]Range, []Range) {
+	ta, tb := tokenize(a), tokenize(b)
+	if len(ta) == 0 || len(tb) == 0 || len(ta)*len(tb) > maxWordDiffCells {
+		return nil, nil
+	}
+
+	// lcs[i][j] is the LCS length of ta[i:] and tb[j:]
+	lcs := make([][]int, len(ta)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(tb)+1)
+	}
+	for i := len(ta) - 1; i >= 0; i-- {
+		for j := len(tb) - 1; j >= 0; j-- {
+			if a[ta[i].Start:ta[i].End] == b[tb[j].Start:tb[j].End] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	if lcs[0][0] == 0 {
+		return nil, nil
+	}
+
+	var ra, rb []Range
+	i, j := 0, 0
+	for i < len(ta) || j < len(tb) {
+		switch {
+		case i < len(ta) && j < len(tb) && a[ta[i].Start:ta[i].End] == b[tb[j].Start:tb[j].End]:
+			i++
+			j++
+		case j == len(tb) || (i < len(ta) && lcs[i+1][j] >= lcs[i][j+1]):
+			ra = appendRange(ra, ta[i])
+			i++
+		default:
+			rb = appendRange(rb, tb[j])
+			j++
+		}
+	}
+	return ra, rb
+}
+
+// appendRange appends r to ranges, merging it with an adjacent last range.
+func appendRange(ranges []Range, r Range) []Range {
+	if n := len(ranges); n > 0 && ranges[n-1].End == r.Start {
+		ranges[n-1].End = r.End
+		return ranges
+	}
+	return append(ranges, r)
+}
+
+// tokenize splits a line into words, runs of whitespace and single other
+// characters, returned as byte ranges.
+func tokenize(s string) []Range {
+	var tokens []Range
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		start := i
+		i += size
+		switch {
+		case isWordRune(r):
+			for i < len(s) {
+				r, size := utf8.DecodeRuneInString(s[i:])
+				if !isWordRune(r) {
+					break
+				}
+				i += size
+			}
+		case unicode.IsSpace(r):
+			for i < len(s) {
+				r, size := utf8.DecodeRuneInString(s[i:])
+				if !unicode.IsSpace(r) {
+					break
+				}
+				i += size
+			}
+		}
+		tokens = append(tokens, Range{Start: start, End: i})
+	}
+	return tokens
+}
+
+// isWordRune reports whether r is part of a word.
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,196 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// findDiffFile returns the file with the given path from a diff.
+func findDiffFile(t *testing.T, diff *Diff, path string) DiffFile {
+	t.Helper()
+	for _, f := range diff.Files {
+		if f.Path == path {
+			return f
+		}
+	}
+	t.Fatalf("File %s not found in diff: %+v", path, diff.Files)
+	return DiffFile{}
+}
+
+func TestGetDiffModes(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	defer os.RemoveAll(repoPath)
+
+	commitFile(t, repoPath, "file.txt", numberedLines(20, nil), "Add file", "alice")
+	full := filepath.Join(repoPath, "file.txt")
+
+	// Stage a change to line 2, then change line 15 in the working tree only
+	os.WriteFile(full, []byte(numberedLines(20, map[int]string{2: "two"})), 0644)
+	if _, err := runGit(repoPath, "add", "file.txt"); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(full, []byte(numberedLines(20, map[int]string{2: "two", 15: "fifteen"})), 0644)
+
+	staged, err := GetDiff(repoPath, DiffOptions{Mode: DiffStaged, Context: DefaultDiffContext})
+	if err != nil {
+		t.Fatalf("GetDiff failed: %v", err)
+	}
+	f := findDiffFile(t, staged, "file.txt")
+	if f.Status != ChangeModified || f.Additions != 1 || f.Deletions != 1 || len(f.Hunks) != 1 {
+		t.Fatalf("Unexpected staged diff: %+v", f)
+	}
+	h := f.Hunks[0]
+	if h.OldStart != 1 || h.OldLines != 5 || h.NewStart != 1 || h.NewLines != 5 {
+		t.Errorf("Unexpected hunk range: %+v", h)
+	}
+	del, add := h.Lines[1], h.Lines[2]
+	if del.Type != LineDeleted || del.Content != "line 2" || del.OldLine != 2 || del.NewLine != 0 {
+		t.Errorf("Unexpected deleted line: %+v", del)
+	}
+	if add.Type != LineAdded || add.Content != "two" || add.NewLine != 2 || add.OldLine != 0 {
+		t.Errorf("Unexpected added line: %+v", add)
+	}
+	if ctx := h.Lines[3]; ctx.Type != LineContext || ctx.OldLine != 3 || ctx.NewLine != 3 {
+		t.Errorf("Unexpected context line: %+v", ctx)
+	}
+
+	unstaged, _ := GetDiff(repoPath, DiffOptions{Mode: DiffUnstaged, Context: DefaultDiffContext})
+	f = findDiffFile(t, unstaged, "file.txt")
+	if len(f.Hunks) != 1 || f.Hunks[0].Lines[4].Content != "fifteen" {
+		t.Errorf("Expected only the line 15 change unstaged: %+v", f.Hunks)
+	}
+
+	head, _ := GetDiff(repoPath, DiffOptions{Mode: DiffHead, Context: DefaultDiffContext})
+	if f = findDiffFile(t, head, "file.txt"); len(f.Hunks) != 2 {
+		t.Errorf("Expected both changes against HEAD, got %d hunks", len(f.Hunks))
+	}
+
+	// Positions match the line numbers used for line selections
+	text, _ := FileDiff(repoPath, "file.txt", DiffUnstaged)
+	for _, l := range findDiffFile(t, unstaged, "file.txt").Hunks[0].Lines {
+		if l.Type == LineAdded && l.Position != diffLine(t, text, "+fifteen") {
+			t.Errorf("Expected position %d, got %d", diffLine(t, text, "+fifteen"), l.Position)
+		}
+	}
+
+	// Without context only the changed lines remain
+	noContext, _ := GetDiff(repoPath, DiffOptions{Mode: DiffHead})
+	if f = findDiffFile(t, noContext, "file.txt"); len(f.Hunks[0].Lines) != 2 {
+		t.Errorf("Expected no context lines, got %+v", f.Hunks[0].Lines)
+	}
+
+	if _, err := GetDiff(repoPath, DiffOptions{Mode: "bogus"}); !errors.Is(err, ErrInvalidDiffMode) {
+		t.Errorf("Expected ErrInvalidDiffMode, got %v", err)
+	}
+}
+
+func TestGetDiffRenameAndBinary(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	defer os.RemoveAll(repoPath)
+
+	commitFile(t, repoPath, "old.txt", numberedLines(10, nil), "Add file", "alice")
+	commitFile(t, repoPath, "image.bin", "\x00\x01\x02", "Add binary", "alice")
+
+	if _, err := runGit(repoPath, "mv", "old.txt", "new name.txt"); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(repoPath, "new name.txt"), []byte(numberedLines(10, map[int]string{10: "ten"})), 0644)
+	os.WriteFile(filepath.Join(repoPath, "image.bin"), []byte("\x00\x03"), 0644)
+	if _, err := runGit(repoPath, "add", "-A"); err != nil {
+		t.Fatal(err)
+	}
+
+	diff, err := GetDiff(repoPath, DiffOptions{Mode: DiffStaged, Context: DefaultDiffContext})
+	if err != nil {
+		t.Fatalf("GetDiff failed: %v", err)
+	}
+	if len(diff.Files) != 2 {
+		t.Fatalf("Expected 2 files, got %+v", diff.Files)
+	}
+
+	renamed := findDiffFile(t, diff, "new name.txt")
+	if renamed.Status != ChangeRenamed || renamed.OldPath != "old.txt" || renamed.Similarity < 50 {
+		t.Errorf("Unexpected rename: %+v", renamed)
+	}
+	if renamed.Additions != 1 || renamed.Deletions != 1 {
+		t.Errorf("Expected one line changed in the renamed file, got +%d -%d", renamed.Additions, renamed.Deletions)
+	}
+
+	binary := findDiffFile(t, diff, "image.bin")
+	if !binary.Binary || len(binary.Hunks) != 0 {
+		t.Errorf("Expected a binary file without hunks: %+v", binary)
+	}
+
+	// Limiting to a path
+	diff, _ = GetDiff(repoPath, DiffOptions{Mode: DiffStaged, Path: "image.bin"})
+	if len(diff.Files) != 1 || diff.Files[0].Path != "image.bin" {
+		t.Errorf("Expected only image.bin, got %+v", diff.Files)
+	}
+}
+
+func TestGetDiffWhitespaceAndHighlights(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	defer os.RemoveAll(repoPath)
+
+	commitFile(t, repoPath, "code.go", "x := compute(a, b)\nreturn x\n", "Add code", "alice")
+	os.WriteFile(filepath.Join(repoPath, "code.go"), []byte("x := compute(a, c)\n  return x\n"), 0644)
+
+	diff, err := GetDiff(repoPath, DiffOptions{Mode: DiffUnstaged, Context: DefaultDiffContext})
+	if err != nil {
+		t.Fatalf("GetDiff failed: %v", err)
+	}
+	lines := findDiffFile(t, diff, "code.go").Hunks[0].Lines
+	if len(lines) != 4 {
+		t.Fatalf("Expected 4 lines, got %+v", lines)
+	}
+	del, add := lines[0], lines[2]
+	if len(del.Highlights) != 1 || del.Content[del.Highlights[0].Start:del.Highlights[0].End] != "b" {
+		t.Errorf("Expected %q highlighted in %q, got %+v", "b", del.Content, del.Highlights)
+	}
+	if len(add.Highlights) != 1 || add.Content[add.Highlights[0].Start:add.Highlights[0].End] != "c" {
+		t.Errorf("Expected %q highlighted in %q, got %+v", "c", add.Content, add.Highlights)
+	}
+
+	// Ignoring whitespace leaves only the real change
+	diff, _ = GetDiff(repoPath, DiffOptions{Mode: DiffUnstaged, Context: 0, IgnoreWhitespace: true})
+	f := findDiffFile(t, diff, "code.go")
+	if f.Additions != 1 || f.Deletions != 1 {
+		t.Errorf("Expected whitespace change to be ignored, got +%d -%d", f.Additions, f.Deletions)
+	}
+
+	// Lines without words in common are not highlighted
+	if a, b := wordDiff("foo bar", "baz"); a != nil || b != nil {
+		t.Errorf("Expected no highlights, got %v %v", a, b)
+	}
+}
+
+func TestGetDiffWithoutHead(t *testing.T) {
+	repoPath, err := os.MkdirTemp("", "gitwapp-git-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(repoPath)
+	if _, err := runGit(repoPath, "init"); err != nil {
+		t.Fatal(err)
+	}
+
+	os.WriteFile(filepath.Join(repoPath, "first.txt"), []byte("first\n"), 0644)
+	if _, err := runGit(repoPath, "add", "first.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	diff, err := GetDiff(repoPath, DiffOptions{Mode: DiffHead, Context: DefaultDiffContext})
+	if err != nil {
+		t.Fatalf("GetDiff failed without HEAD: %v", err)
+	}
+	f := findDiffFile(t, diff, "first.txt")
+	if f.Status != ChangeAdded || f.Additions != 1 || f.Hunks[0].Lines[0].NewLine != 1 {
+		t.Errorf("Unexpected diff of new file: %+v", f)
+	}
+
+	if _, err := GetFileDiff(repoPath, "first.txt"); err != nil {
+		t.Errorf("GetFileDiff failed without HEAD: %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// Sources a file can be restored from by DiscardFile.
+const (
+	DiscardFromIndex = "index" // Discard unstaged changes, keeping staged ones
+	DiscardFromHead  = "head"  // Discard staged and unstaged changes
+)
+
+// ErrInvalidSource is returned for an unknown DiscardFile source.
+var ErrInvalidSource = errors.New("invalid source")
+
+// DiscardFile reverts the working tree copy of a file to the index or, with
+// DiscardFromHead, reverts both the index and the working tree to HEAD.
+func DiscardFile(path string, file string, source string) error {
+	if _, err := git.PlainOpen(path); err != nil {
+		return err
+	}
+
+	switch source {
+	case DiscardFromIndex:
+		_, err := runGit(path, "checkout", "--", file)
+		return err
+	case DiscardFromHead:
+		_, err := runGit(path, "checkout", "HEAD", "--", file)
+		return err
+	}
+	return fmt.Errorf("%w: %q", ErrInvalidSource, source)
+}
+
+// DiscardHunks reverts the selected changes of a file in the working tree.
+// The selection refers to the unstaged diff of the file (FileDiff with
+// DiffUnstaged); staged changes are kept.
+func DiscardHunks(path string, file string, sel HunkSelection) error {
+	diff, err := FileDiff(path, file, DiffUnstaged)
+	if err != nil {
+		return err
+	}
+	patch, err := selectPatch(diff, sel, true)
+	if err != nil {
+		return err
+	}
+	return applyPatch(path, patch, false, true)
+}
+
+// CleanUntracked removes untracked files and directories, limited to the
+// given paths when any are given. Ignored files are kept. With dryRun
+// nothing is removed. It returns the paths that were, or would be, removed.
+func CleanUntracked(path string, paths []string, dryRun bool) ([]string, error) {
+	if _, err := git.PlainOpen(path); err != nil {
+		return nil, err
+	}
+
+	args := []string{"clean", "-d", "--force"}
+	if dryRun {
+		args = append(args, "--dry-run")
+	}
+	output, err := runGit(path, append(append(args, "--"), paths...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	removed := []string{}
+	for _, line := range strings.Split(output, "\n") {
+		if p, ok := strings.CutPrefix(line, "Would remove "); ok {
+			removed = append(removed, p)
+		} else if p, ok := strings.CutPrefix(line, "Removing "); ok {
+			removed = append(removed, p)
+		}
+	}
+	return removed, nil
+}
+
+// ResetHard discards all staged and unstaged changes to tracked files,
+// resetting the index and working tree to HEAD. Untracked files are kept.
+func ResetHard(path string) error {
+	if _, err := git.PlainOpen(path); err != nil {
+		return err
+	}
+	_, err := runGit(path, "reset", "--hard", "HEAD")
+	return err
+}
+
+// ChangedFiles returns the tracked files with staged or unstaged changes,
+// which ResetHard would revert.
+func ChangedFiles(path string) ([]string, error) {
+	if _, err := git.PlainOpen(path); err != nil {
+		return nil, err
+	}
+
+	output, err := runGit(path, "diff", "HEAD", "--name-only", "-z")
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{}
+	for _, f := range strings.Split(output, "\x00") {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
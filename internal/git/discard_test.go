@@ -0,0 +1,121 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDiscardFile(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	defer os.RemoveAll(repoPath)
+
+	full := filepath.Join(repoPath, "README.md")
+	os.WriteFile(full, []byte("staged"), 0644)
+	StageFile(repoPath, "README.md")
+	os.WriteFile(full, []byte("unstaged"), 0644)
+
+	// Restoring from the index keeps the staged change
+	if err := DiscardFile(repoPath, "README.md", DiscardFromIndex); err != nil {
+		t.Fatalf("DiscardFile from index failed: %v", err)
+	}
+	if data, _ := os.ReadFile(full); string(data) != "staged" {
+		t.Errorf("Expected staged content, got %q", data)
+	}
+
+	if err := DiscardFile(repoPath, "README.md", DiscardFromHead); err != nil {
+		t.Fatalf("DiscardFile from HEAD failed: %v", err)
+	}
+	if data, _ := os.ReadFile(full); string(data) != "Hello" {
+		t.Errorf("Expected committed content, got %q", data)
+	}
+	if status, _ := GetStatus(repoPath); !status.Clean {
+		t.Errorf("Expected clean repository, got %+v", status.Worktree)
+	}
+
+	if err := DiscardFile(repoPath, "README.md", "stash"); !errors.Is(err, ErrInvalidSource) {
+		t.Errorf("Expected ErrInvalidSource, got %v", err)
+	}
+}
+
+func TestDiscardHunks(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	defer os.RemoveAll(repoPath)
+
+	commitFile(t, repoPath, "file.txt", numberedLines(20, nil), "Add file", "alice")
+	full := filepath.Join(repoPath, "file.txt")
+	os.WriteFile(full, []byte(numberedLines(20, map[int]string{2: "two", 15: "fifteen"})), 0644)
+
+	if err := DiscardHunks(repoPath, "file.txt", HunkSelection{Hunks: []int{0}}); err != nil {
+		t.Fatalf("DiscardHunks failed: %v", err)
+	}
+	data, _ := os.ReadFile(full)
+	if want := numberedLines(20, map[int]string{15: "fifteen"}); string(data) != want {
+		t.Errorf("Unexpected content after discarding the first hunk:\n%s", data)
+	}
+
+	// Line-level: discard the appended line but keep the edit
+	os.WriteFile(full, []byte(numberedLines(20, map[int]string{15: "fifteen"})+"extra\n"), 0644)
+	diff, _ := FileDiff(repoPath, "file.txt", DiffUnstaged)
+	line := diffLine(t, diff, "+extra")
+	if err := DiscardHunks(repoPath, "file.txt", HunkSelection{Lines: []LineRange{{Start: line, End: line}}}); err != nil {
+		t.Fatalf("DiscardHunks failed: %v", err)
+	}
+	data, _ = os.ReadFile(full)
+	if want := numberedLines(20, map[int]string{15: "fifteen"}); string(data) != want {
+		t.Errorf("Unexpected content after discarding a line:\n%s", data)
+	}
+}
+
+func TestCleanUntrackedAndResetHard(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	defer os.RemoveAll(repoPath)
+
+	commitFile(t, repoPath, ".gitignore", "*.log\n", "Ignore logs", "alice")
+	os.WriteFile(filepath.Join(repoPath, "a.txt"), []byte("a"), 0644)
+	os.MkdirAll(filepath.Join(repoPath, "dir"), 0755)
+	os.WriteFile(filepath.Join(repoPath, "dir", "b.txt"), []byte("b"), 0644)
+	os.WriteFile(filepath.Join(repoPath, "debug.log"), []byte("log"), 0644)
+
+	files, err := CleanUntracked(repoPath, nil, true)
+	if err != nil {
+		t.Fatalf("CleanUntracked dry run failed: %v", err)
+	}
+	if want := []string{"a.txt", "dir/"}; !reflect.DeepEqual(files, want) {
+		t.Errorf("Expected %v, got %v", want, files)
+	}
+	if _, err := os.Stat(filepath.Join(repoPath, "a.txt")); err != nil {
+		t.Error("Expected dry run to keep files")
+	}
+
+	if files, err = CleanUntracked(repoPath, []string{"dir"}, false); err != nil || len(files) != 1 {
+		t.Fatalf("CleanUntracked failed: %v (%v)", files, err)
+	}
+	if _, err := os.Stat(filepath.Join(repoPath, "dir")); !os.IsNotExist(err) {
+		t.Error("Expected dir to be removed")
+	}
+	if _, err := os.Stat(filepath.Join(repoPath, "a.txt")); err != nil {
+		t.Error("Expected a.txt outside the given paths to be kept")
+	}
+	if _, err := os.Stat(filepath.Join(repoPath, "debug.log")); err != nil {
+		t.Error("Expected ignored file to be kept")
+	}
+
+	// Hard reset reverts tracked files and keeps untracked ones
+	os.WriteFile(filepath.Join(repoPath, "README.md"), []byte("changed"), 0644)
+	StageFile(repoPath, "README.md")
+	if files, err := ChangedFiles(repoPath); err != nil || !reflect.DeepEqual(files, []string{"README.md"}) {
+		t.Errorf("Expected README.md to be changed, got %v (err %v)", files, err)
+	}
+	if err := ResetHard(repoPath); err != nil {
+		t.Fatalf("ResetHard failed: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(repoPath, "README.md")); string(data) != "Hello" {
+		t.Errorf("Expected committed content after reset, got %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(repoPath, "a.txt")); err != nil {
+		t.Error("Expected untracked file to survive reset")
+	}
+}
@@ -3,20 +3,17 @@
 package git
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"strings"
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
-	"github.com/go-git/go-git/v5/plumbing/object"
-	"github.com/go-git/go-git/v5/plumbing/storer"
 	"github.com/go-git/go-git/v5/plumbing/transport"
-	"github.com/go-git/go-git/v5/plumbing/transport/http"
-	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
 )
 
 // Status represents the high-level status of a repository.
@@ -25,6 +22,8 @@ type Status struct {
 	Ahead    int
 	Behind   int
 	Branch   string
+	Upstream string // Configured upstream, e.g. "origin/main"; empty if none
+	State    string // Operation in progress, e.g. StateMerging; empty if none
 	Worktree git.Status
 }
 
@@ -64,32 +63,27 @@ func GetStatus(path string) (*Status, error) {
 
 	branchName := head.Name().Short()
 
-	// Calculate ahead/behind by comparing with remote tracking branch
+	// Calculate ahead/behind against the branch's configured upstream
 	ahead, behind := 0, 0
+	upstream := ""
 
-	// Get the remote tracking branch
-	remoteBranchName := "refs/remotes/origin/" + branchName
-	remoteRef, err := r.Reference(plumbing.ReferenceName(remoteBranchName), true)
-	if err == nil {
-		// Both local and remote refs exist, compare them
-		localCommit, err := r.CommitObject(head.Hash())
-		if err == nil {
-			remoteCommit, err := r.CommitObject(remoteRef.Hash())
-			if err == nil {
-				// Count commits ahead (local commits not in remote)
-				ahead, _ = countCommitsBetween(r, localCommit.Hash, remoteCommit.Hash)
-				// Count commits behind (remote commits not in local)
-				behind, _ = countCommitsBetween(r, remoteCommit.Hash, localCommit.Hash)
+	if upstreamRef, ok := trackingRef(r, branchName); ok {
+		upstream = upstreamRef.Short()
+		if remoteRef, err := r.Reference(upstreamRef, true); err == nil {
+			if ahead, behind, err = aheadBehind(path, head.Hash(), remoteRef.Hash()); err != nil {
+				return nil, err
 			}
 		}
 	}
-	// If remote branch doesn't exist, ahead/behind stay at 0
+	// If no upstream is configured or it has not been fetched, ahead/behind stay at 0
 
 	return &Status{
 		Clean:    status.IsClean(),
 		Ahead:    ahead,
 		Behind:   behind,
 		Branch:   branchName,
+		Upstream: upstream,
+		State:    repoState(r),
 		Worktree: status,
 	}, nil
 }
@@ -132,34 +126,69 @@ func UnstageAll(path string) error {
 	return cmd.Run()
 }
 
+// Diff modes selecting the two versions of a file compared by FileDiff.
+const (
+	DiffHead     = "head"     // HEAD against the working tree
+	DiffStaged   = "staged"   // HEAD against the index
+	DiffUnstaged = "unstaged" // The index against the working tree
+)
+
+// ErrInvalidDiffMode is returned for an unknown diff mode.
+var ErrInvalidDiffMode = errors.New("invalid diff mode")
+
 // GetFileDiff gets the diff for a single file.
 func GetFileDiff(path string, file string) (string, error) {
-	// Get unified diff for the file
-	cmd := exec.Command("git", "diff", "HEAD", "--", file)
-	cmd.Dir = path
-	output, err := cmd.Output()
+	return FileDiff(path, file, DiffHead)
+}
+
+// FileDiff returns the unified diff of a single file in the given mode.
+func FileDiff(path string, file string, mode string) (string, error) {
+	args, err := diffArgs(path, mode)
 	if err != nil {
 		return "", err
 	}
-	return string(output), nil
+	return runGit(path, append(args, "--", file)...)
 }
 
-// Commit commits the staged changes with the given message.
-func Commit(path string, msg string) error {
-	r, err := git.PlainOpen(path)
-	if err != nil {
-		return err
+// diffFlags make git print plain patches with a/ and b/ prefixes whatever
+// the user's configuration (color.ui, diff.noprefix, diff.external or
+// textconv drivers), so they can be parsed and applied with git apply.
+var diffFlags = []string{"--no-color", "--no-ext-diff", "--no-textconv", "--src-prefix=a/", "--dst-prefix=b/"}
+
+// diffArgs returns the git diff command comparing the versions selected by
+// mode. Before the first commit HEAD is compared as an empty tree.
+func diffArgs(path string, mode string) ([]string, error) {
+	args := append([]string{"diff"}, diffFlags...)
+	switch mode {
+	case DiffStaged:
+		return append(args, "--cached"), nil
+	case DiffUnstaged:
+		return args, nil
+	case DiffHead:
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrInvalidDiffMode, mode)
 	}
-	w, err := r.Worktree()
+
+	if _, err := runGit(path, "rev-parse", "--verify", "--quiet", "HEAD"); err == nil {
+		return append(args, "HEAD"), nil
+	}
+	emptyTree, err := runGit(path, "hash-object", "-t", "tree", "--stdin")
 	if err != nil {
-		return err
+		return nil, err
 	}
-	_, err = w.Commit(msg, &git.CommitOptions{})
-	return err
+	return append(args, strings.TrimSpace(emptyTree)), nil
 }
 
-// Push pushes the commits to the remote repository.
-func Push(path string) error {
+// PushOptions configures Push.
+type PushOptions struct {
+	Remote string       // Remote to push to; the branch's upstream (or "origin") when empty
+	Tags   bool         // Also push all tags
+	Auth   *Credentials // Explicit credentials; server defaults when nil
+}
+
+// Push pushes the current branch, and all tags with opts.Tags, to a remote
+// repository. Being already up to date is not an error.
+func Push(path string, opts PushOptions) error {
 	r, err := git.PlainOpen(path)
 	if err != nil {
 		return err
@@ -171,22 +200,55 @@ func Push(path string) error {
 	}
 	branchName := head.Name().Short()
 
-	auth, err := getAuth(path)
+	remote, mergeRef, err := upstreamFor(r, branchName)
+	if err != nil {
+		return err
+	}
+	if opts.Remote != "" && opts.Remote != remote {
+		// Pushing somewhere other than the upstream keeps the branch name
+		remote = opts.Remote
+		mergeRef = head.Name()
+	}
+
+	if _, err := r.Remote(remote); err != nil {
+		return fmt.Errorf("%w: %s", ErrRemoteNotFound, remote)
+	}
+
+	auth, cred, err := getAuth(path, remote, opts.Auth)
 	if err != nil {
 		return fmt.Errorf("failed to get auth: %w", err)
 	}
 
-	// Explicitly push only the current branch
-	refSpec := fmt.Sprintf("refs/heads/%s:refs/heads/%s", branchName, branchName)
+	// Explicitly push only the current branch, and the tags if requested
+	refSpecs := []config.RefSpec{config.RefSpec(fmt.Sprintf("%s:%s", head.Name(), mergeRef))}
+	if opts.Tags {
+		refSpecs = append(refSpecs, config.RefSpec("refs/tags/*:refs/tags/*"))
+	}
 
-	return r.Push(&git.PushOptions{
-		Auth:     auth,
-		RefSpecs: []config.RefSpec{config.RefSpec(refSpec)},
+	err = r.Push(&git.PushOptions{
+		RemoteName: remote,
+		Auth:       auth,
+		RefSpecs:   refSpecs,
 	})
+	settleCredential(cred, err)
+	if err == git.NoErrAlreadyUpToDate {
+		return nil
+	}
+	return err
+}
+
+// PullOptions configures Pull.
+type PullOptions struct {
+	Remote string       // Remote to pull from; the branch's upstream (or "origin") when empty
+	Rebase bool         // Rebase local commits onto the upstream instead of merging it
+	Auth   *Credentials // Explicit credentials; server defaults when nil
 }
 
-// Pull pulls the latest changes from the remote repository.
-func Pull(path string) error {
+// Pull fetches from a remote repository and merges the current branch's
+// upstream, or rebases onto it with opts.Rebase. Being already up to date
+// is not an error. A rebase that stops on conflicts returns
+// ErrRebaseConflict and leaves the repository in the rebasing state.
+func Pull(path string, opts PullOptions) error {
 	r, err := git.PlainOpen(path)
 	if err != nil {
 		return err
@@ -197,137 +259,135 @@ func Pull(path string) error {
 		return err
 	}
 
-	auth, err := getAuth(path)
+	head, err := r.Head()
 	if err != nil {
-		return fmt.Errorf("failed to get auth: %w", err)
+		return fmt.Errorf("failed to get HEAD: %w", err)
 	}
 
-	return w.Pull(&git.PullOptions{
-		RemoteName: "origin",
-		Auth:       auth,
-	})
-}
+	remote, mergeRef, err := upstreamFor(r, head.Name().Short())
+	if err != nil {
+		return err
+	}
+	if opts.Remote != "" && opts.Remote != remote {
+		remote = opts.Remote
+		mergeRef = head.Name()
+	}
 
-// getSSHAuth attempts to get SSH authentication using the ssh-agent or
-// common SSH key file locations.
-func getSSHAuth() (transport.AuthMethod, error) {
-	// Try ssh-agent first (most common and secure)
-	auth, err := ssh.NewSSHAgentAuth("git")
-	if err == nil {
-		return auth, nil
+	if _, err := r.Remote(remote); err != nil {
+		return fmt.Errorf("%w: %s", ErrRemoteNotFound, remote)
 	}
 
-	// Fallback: try common SSH key locations
-	homeDir, err := os.UserHomeDir()
+	auth, cred, err := getAuth(path, remote, opts.Auth)
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("failed to get auth: %w", err)
 	}
 
-	keyPaths := []string{
-		filepath.Join(homeDir, ".ssh", "id_ed25519"),
-		filepath.Join(homeDir, ".ssh", "id_rsa"),
-		filepath.Join(homeDir, ".ssh", "id_ecdsa"),
+	if opts.Rebase {
+		return pullRebase(r, path, remote, mergeRef, auth, cred)
 	}
 
-	for _, keyPath := range keyPaths {
-		auth, err := ssh.NewPublicKeysFromFile("git", keyPath, "")
-		if err == nil {
-			return auth, nil
-		}
+	err = w.Pull(&git.PullOptions{
+		RemoteName:    remote,
+		ReferenceName: mergeRef,
+		Auth:          auth,
+	})
+	settleCredential(cred, err)
+	if err == git.NoErrAlreadyUpToDate {
+		return nil
 	}
-
-	return nil, fmt.Errorf("no SSH authentication method available")
+	return err
 }
 
-// getAuth determines the appropriate authentication method (SSH or HTTPS)
-// based on the remote URL of the repository.
-func getAuth(repoPath string) (transport.AuthMethod, error) {
-	r, err := git.PlainOpen(repoPath)
-	if err != nil {
-		return nil, err
+// pullRebase fetches the remote and rebases the current branch onto the
+// remote-tracking ref of mergeRef.
+func pullRebase(r *git.Repository, path string, remote string, mergeRef plumbing.ReferenceName, auth transport.AuthMethod, cred *credential) error {
+	err := r.Fetch(&git.FetchOptions{RemoteName: remote, Auth: auth})
+	settleCredential(cred, err)
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return err
 	}
 
-	// Get the remote URL to determine auth type
-	remote, err := r.Remote("origin")
+	onto := plumbing.NewRemoteReferenceName(remote, mergeRef.Short())
+	result, err := Rebase(path, RebaseOptions{Onto: onto.String()})
 	if err != nil {
-		return nil, err
+		return err
 	}
-
-	if len(remote.Config().URLs) == 0 {
-		return nil, fmt.Errorf("no remote URL configured")
-	}
-
-	remoteURL := remote.Config().URLs[0]
-
-	// Check if it's SSH or HTTPS
-	if strings.HasPrefix(remoteURL, "git@") || strings.HasPrefix(remoteURL, "ssh://") {
-		return getSSHAuth()
+	if result.Status == RebaseConflicted {
+		return fmt.Errorf("%w: %d file(s)", ErrRebaseConflict, len(result.Conflicts))
 	}
+	return nil
+}
 
-	// For HTTPS, use git credential helper
-	return getHTTPSAuth(remoteURL)
+// FetchOptions configures Fetch.
+type FetchOptions struct {
+	Remote string       // Only fetch this remote; all remotes when empty
+	Prune  bool         // Remove remote-tracking refs that no longer exist on the remote
+	Tags   bool         // Fetch all tags, not only those pointing at fetched commits
+	Auth   *Credentials // Explicit credentials; server defaults when nil
 }
 
-// getHTTPSAuth retrieves HTTPS credentials using the git credential helper.
-func getHTTPSAuth(remoteURL string) (transport.AuthMethod, error) {
-	// Use git credential fill to get credentials
-	cmd := exec.Command("git", "credential", "fill")
-	cmd.Stdin = strings.NewReader(fmt.Sprintf("protocol=https\nhost=github.com\npath=%s\n\n", remoteURL))
+// FetchResult reports the outcome of fetching a single remote.
+type FetchResult struct {
+	Remote   string `json:"remote"`
+	UpToDate bool   `json:"up_to_date"`
+	Error    string `json:"error,omitempty"`
+}
 
-	output, err := cmd.Output()
+// Fetch downloads objects and refs from every configured remote (or only
+// opts.Remote) without touching the working tree. A failure on one remote
+// does not stop the others; it is reported in that remote's FetchResult.
+func Fetch(path string, opts FetchOptions) ([]FetchResult, error) {
+	r, err := git.PlainOpen(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get credentials: %w", err)
+		return nil, err
 	}
 
-	// Parse the output
-	var username, password string
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		if strings.HasPrefix(line, "username=") {
-			username = strings.TrimPrefix(line, "username=")
-		} else if strings.HasPrefix(line, "password=") {
-			password = strings.TrimPrefix(line, "password=")
+	remotes, err := r.Remotes()
+	if err != nil {
+		return nil, err
+	}
+	if opts.Remote != "" {
+		remote, err := r.Remote(opts.Remote)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrRemoteNotFound, opts.Remote)
 		}
+		remotes = []*git.Remote{remote}
 	}
 
-	if username == "" || password == "" {
-		return nil, fmt.Errorf("no credentials found")
+	tags := git.TagFollowing
+	if opts.Tags {
+		tags = git.AllTags
 	}
 
-	return &http.BasicAuth{
-		Username: username,
-		Password: password,
-	}, nil
-}
+	results := []FetchResult{}
+	for _, remote := range remotes {
+		name := remote.Config().Name
+		result := FetchResult{Remote: name}
 
-// countCommitsBetween counts the number of commits between two commit hashes.
-func countCommitsBetween(r *git.Repository, from, to plumbing.Hash) (int, error) {
-	if from == to {
-		return 0, nil
-	}
-
-	// Get commit iterator starting from 'from'
-	commitIter, err := r.Log(&git.LogOptions{From: from})
-	if err != nil {
-		return 0, err
-	}
-	defer commitIter.Close()
-
-	count := 0
-	err = commitIter.ForEach(func(c *object.Commit) error {
-		if c.Hash == to {
-			// Reached the 'to' commit, stop counting
-			return storer.ErrStop
+		auth, cred, err := getAuth(path, name, opts.Auth)
+		if err != nil {
+			result.Error = fmt.Sprintf("failed to get auth: %v", err)
+			results = append(results, result)
+			continue
 		}
-		count++
-		return nil
-	})
 
-	if err != nil && err != storer.ErrStop {
-		return 0, err
+		err = remote.Fetch(&git.FetchOptions{
+			RemoteName: name,
+			Auth:       auth,
+			Prune:      opts.Prune,
+			Tags:       tags,
+		})
+		settleCredential(cred, err)
+		switch {
+		case err == git.NoErrAlreadyUpToDate:
+			result.UpToDate = true
+		case err != nil:
+			result.Error = err.Error()
+		}
+		results = append(results, result)
 	}
 
-	return count, nil
+	return results, nil
 }
 
 // IsRepo checks if a valid Git repository exists at the given path.
@@ -335,3 +395,40 @@ func IsRepo(path string) bool {
 	_, err := git.PlainOpen(path)
 	return err == nil
 }
+
+// runGit runs the git command-line tool in the given repository and returns
+// its standard output. On failure the returned error includes stderr. The
+// server has no terminal, so git never opens an editor or prompts.
+func runGit(path string, args ...string) (string, error) {
+	return runGitContext(context.Background(), path, "", args...)
+}
+
+// runGitInput runs the git command-line tool like runGit, passing input on
+// standard input.
+func runGitInput(path string, input string, args ...string) (string, error) {
+	return runGitContext(context.Background(), path, input, args...)
+}
+
+// runGitContext runs the git command-line tool like runGitInput, killing it
+// when ctx is done. The error then wraps ctx.Err().
+func runGitContext(ctx context.Context, path string, input string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = path
+	cmd.Env = append(os.Environ(), "GIT_EDITOR=true", "GIT_TERMINAL_PROMPT=0")
+	if input != "" {
+		cmd.Stdin = strings.NewReader(input)
+	}
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return string(output), fmt.Errorf("git %s: %w", args[0], ctxErr)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return string(output), fmt.Errorf("git %s: %w: %s", args[0], err, msg)
+		}
+		return string(output), fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return string(output), nil
+}
@@ -174,7 +174,8 @@ func TestStageAndCommit(t *testing.T) {
 	}
 
 	// Commit
-	if err := Commit(repoPath, "Add test file"); err != nil {
+	author := &Identity{Name: "Test", Email: "test@example.com"}
+	if _, err := Commit(repoPath, "Add test file", CommitOptions{Author: author}); err != nil {
 		t.Fatalf("Commit failed: %v", err)
 	}
 
@@ -205,7 +206,7 @@ func TestPush(t *testing.T) {
 		},
 	})
 
-	if err := Push(local); err != nil {
+	if err := Push(local, PushOptions{}); err != nil {
 		t.Fatalf("Push failed: %v", err)
 	}
 
@@ -259,7 +260,7 @@ func TestPull(t *testing.T) {
 	rA.Push(&git.PushOptions{})
 
 	// User B pulls
-	if err := Pull(localB); err != nil {
+	if err := Pull(localB, PullOptions{}); err != nil {
 		t.Fatalf("Pull failed: %v", err)
 	}
 
@@ -268,3 +269,97 @@ func TestPull(t *testing.T) {
 		t.Error("Expected pull.txt to exist in localB after pull")
 	}
 }
+
+// commitFile writes content to name inside repoPath, stages it and commits it
+// with the given message and author name, returning the new commit hash.
+func commitFile(t *testing.T, repoPath, name, content, msg, author string) string {
+	t.Helper()
+
+	full := filepath.Join(repoPath, name)
+	if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	r, err := git.PlainOpen(repoPath)
+	if err != nil {
+		t.Fatalf("Failed to open repo: %v", err)
+	}
+	w, _ := r.Worktree()
+	if _, err := w.Add(name); err != nil {
+		t.Fatalf("Failed to add %s: %v", name, err)
+	}
+	hash, err := w.Commit(msg, &git.CommitOptions{
+		Author: &object.Signature{
+			Name:  author,
+			Email: author + "@example.com",
+			When:  time.Now(),
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+	return hash.String()
+}
+
+func TestFetch(t *testing.T) {
+	localA, remote := setupTestRepoWithRemote(t)
+	defer os.RemoveAll(localA)
+	defer os.RemoveAll(remote)
+
+	localB, err := os.MkdirTemp("", "gitwapp-local-b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(localB)
+	if _, err := git.PlainClone(localB, false, &git.CloneOptions{URL: remote}); err != nil {
+		t.Fatal(err)
+	}
+
+	// User A pushes a change
+	commitFile(t, localA, "fetch.txt", "fetch", "Fetch commit", "alice")
+	rA, _ := git.PlainOpen(localA)
+	if err := rA.Push(&git.PushOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// User B fetches and is now behind without the working tree changing
+	results, err := Fetch(localB, FetchOptions{Prune: true})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Remote != "origin" || results[0].Error != "" {
+		t.Fatalf("Unexpected fetch results: %+v", results)
+	}
+
+	status, err := GetStatus(localB)
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if status.Behind != 1 {
+		t.Errorf("Expected to be 1 behind after fetch, got %d", status.Behind)
+	}
+	if _, err := os.Stat(filepath.Join(localB, "fetch.txt")); !os.IsNotExist(err) {
+		t.Error("Expected fetch.txt not to exist in working tree after fetch")
+	}
+
+	// A second fetch has nothing new
+	results, _ = Fetch(localB, FetchOptions{})
+	if !results[0].UpToDate {
+		t.Errorf("Expected second fetch to be up to date, got %+v", results[0])
+	}
+}
+
+// setIdentity configures a committer identity in the repository, which the
+// git command-line tool requires for merges and rebases.
+func setIdentity(t *testing.T, repoPath string) {
+	t.Helper()
+	if _, err := runGit(repoPath, "config", "user.name", "Test"); err != nil {
+		t.Fatalf("Failed to set user.name: %v", err)
+	}
+	if _, err := runGit(repoPath, "config", "user.email", "test@example.com"); err != nil {
+		t.Fatalf("Failed to set user.email: %v", err)
+	}
+}
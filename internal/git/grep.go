@@ -0,0 +1,251 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+)
+
+const (
+	// DefaultGrepLimit is the number of matches Grep returns when no limit is given.
+	DefaultGrepLimit = 100
+	// MaxGrepLimit is the largest number of matches Grep will return.
+	MaxGrepLimit = 1000
+	// MaxGrepContext is the largest number of context lines around a match.
+	MaxGrepContext = 10
+)
+
+// ErrInvalidPattern is returned for empty or malformed search patterns.
+var ErrInvalidPattern = errors.New("invalid pattern")
+
+// GrepOptions configures Grep.
+type GrepOptions struct {
+	Pattern    string   // Text to search for, or a regular expression if Regex is set
+	Regex      bool     // Interpret Pattern as a regular expression in Go's RE2 syntax
+	IgnoreCase bool     // Match case-insensitively
+	Ref        string   // Branch, tag or revision to search; the working tree when empty
+	Paths      []string // Only search files matching these pathspecs, e.g. "*.go" or ":!vendor"
+	Context    int      // Lines of context before and after each match
+	Limit      int      // Maximum number of matches to return
+}
+
+// GrepMatch is a line matching a search.
+type GrepMatch struct {
+	Path    string   `json:"path"`
+	Line    int      `json:"line"`   // Starting at 1
+	Column  int      `json:"column"` // Byte offset of the first match, starting at 1
+	Text    string   `json:"text"`
+	Matches []Range  `json:"matches"` // Every match within Text
+	Before  []string `json:"before,omitempty"`
+	After   []string `json:"after,omitempty"`
+}
+
+// GrepResult holds the matches of a search.
+type GrepResult struct {
+	Commit    string      `json:"commit,omitempty"` // Empty when the working tree was searched
+	Matches   []GrepMatch `json:"matches"`
+	Truncated bool        `json:"truncated"` // More matches exist than were returned
+}
+
+// grepLine is a line printed by git grep, either a match or context.
+type grepLine struct {
+	path   string
+	line   int
+	column int // 0 for context lines
+	text   string
+}
+
+// Grep searches the contents of tracked text files in the working tree or
+// at a revision.
+func Grep(path string, opts GrepOptions) (*GrepResult, error) {
+	return GrepContext(context.Background(), path, opts)
+}
+
+// GrepContext is like Grep but stops git grep when ctx is done.
+func GrepContext(ctx context.Context, path string, opts GrepOptions) (*GrepResult, error) {
+	re, err := grepRegexp(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultGrepLimit
+	}
+	limit = min(limit, MaxGrepLimit)
+	contextLines := min(max(opts.Context, 0), MaxGrepContext)
+
+	result := &GrepResult{Matches: []GrepMatch{}}
+	args := []string{"grep", "--line-number", "--column", "--null", "-I", "--full-name",
+		"--context=" + strconv.Itoa(contextLines)}
+	if opts.IgnoreCase {
+		args = append(args, "--ignore-case")
+	}
+	if opts.Regex {
+		// Perl syntax agrees with the RE2 syntax used for highlighting on
+		// escapes like \d, \w and \s, which POSIX regexes treat as literals
+		args = append(args, "--perl-regexp")
+	} else {
+		args = append(args, "--fixed-strings")
+	}
+	args = append(args, "-e", opts.Pattern)
+
+	if opts.Ref != "" {
+		r, err := git.PlainOpen(path)
+		if err != nil {
+			return nil, err
+		}
+		hash, err := resolveRef(r, opts.Ref)
+		if err != nil {
+			return nil, err
+		}
+		result.Commit = hash.String()
+		args = append(args, result.Commit)
+	}
+	args = append(args, "--")
+	args = append(args, opts.Paths...)
+
+	output, err := runGitContext(ctx, path, "", args...)
+	if err != nil {
+		// git grep exits with status 1 when nothing matches
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return result, nil
+		}
+		return nil, err
+	}
+
+	// Lines are printed in groups of adjacent matches and their context,
+	// separated by "--"
+	var group []grepLine
+	flush := func() {
+		defer func() { group = group[:0] }()
+		for _, gl := range group {
+			if gl.column == 0 {
+				continue
+			}
+			if len(result.Matches) == limit {
+				result.Truncated = true
+				return
+			}
+			m := GrepMatch{Path: gl.path, Line: gl.line, Column: gl.column, Text: gl.text, Matches: []Range{}}
+			for _, loc := range re.FindAllStringIndex(gl.text, -1) {
+				m.Matches = append(m.Matches, Range{Start: loc[0], End: loc[1]})
+			}
+			for _, other := range group {
+				switch {
+				case other.path != gl.path:
+				case other.line >= gl.line-contextLines && other.line < gl.line:
+					m.Before = append(m.Before, other.text)
+				case other.line > gl.line && other.line <= gl.line+contextLines:
+					m.After = append(m.After, other.text)
+				}
+			}
+			result.Matches = append(result.Matches, m)
+		}
+	}
+
+	prefix := ""
+	if result.Commit != "" {
+		prefix = result.Commit + ":"
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(output, "\n"), "\n") {
+		if result.Truncated {
+			break
+		}
+		if line == "--" {
+			flush()
+			continue
+		}
+		gl, ok := parseGrepLine(line, prefix)
+		if !ok {
+			continue
+		}
+		if len(group) > 0 && group[len(group)-1].path != gl.path {
+			flush()
+		}
+		group = append(group, gl)
+	}
+	flush()
+
+	return result, nil
+}
+
+// grepRegexp compiles the pattern used to locate matches within lines. It
+// also rejects Perl features RE2 lacks, such as lookarounds, before git
+// sees them.
+func grepRegexp(opts GrepOptions) (*regexp.Regexp, error) {
+	if opts.Pattern == "" {
+		return nil, fmt.Errorf("%w: empty pattern", ErrInvalidPattern)
+	}
+	expr := opts.Pattern
+	if !opts.Regex {
+		expr = regexp.QuoteMeta(expr)
+	}
+	if opts.IgnoreCase {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPattern, err)
+	}
+	return re, nil
+}
+
+// parseGrepLine parses a NUL-separated line of git grep output: path, line
+// number, column and text for matches, or path, line number and text for
+// context lines.
+func parseGrepLine(line string, prefix string) (grepLine, bool) {
+	parts := strings.SplitN(line, "\x00", 4)
+	if len(parts) < 3 {
+		return grepLine{}, false
+	}
+
+	gl := grepLine{path: strings.TrimPrefix(parts[0], prefix)}
+	var err error
+	if gl.line, err = strconv.Atoi(parts[1]); err != nil {
+		return grepLine{}, false
+	}
+	if len(parts) == 3 {
+		gl.text = parts[2]
+		return gl, true
+	}
+	if gl.column, err = strconv.Atoi(parts[2]); err != nil {
+		return grepLine{}, false
+	}
+	gl.text = parts[3]
+	return gl, true
+}
+
+// FindFiles returns the tracked files whose path contains query, ignoring
+// case. The working tree's index is searched, in path order.
+func FindFiles(path string, query string) ([]string, error) {
+	return FindFilesContext(context.Background(), path, query)
+}
+
+// FindFilesContext is like FindFiles but stops git ls-files when ctx is done.
+func FindFilesContext(ctx context.Context, path string, query string) ([]string, error) {
+	if query == "" {
+		return nil, fmt.Errorf("%w: empty pattern", ErrInvalidPattern)
+	}
+
+	output, err := runGitContext(ctx, path, "", "ls-files", "-z")
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{}
+	query = strings.ToLower(query)
+	for _, file := range strings.Split(output, "\x00") {
+		if file != "" && strings.Contains(strings.ToLower(file), query) {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
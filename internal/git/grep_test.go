@@ -0,0 +1,146 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGrep(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	defer os.RemoveAll(repoPath)
+
+	commitFile(t, repoPath, "config/app.yaml", "name: app\ntimeout: 5\nretries: 3\n", "Add config", "alice")
+	head := commitFile(t, repoPath, "src/main.go", "package main\n\n// Timeout is read from config\nvar timeout = cfg(\"timeout\") + cfg(\"timeout\")\n", "Add main", "alice")
+	commitFile(t, repoPath, "image.bin", "timeout\x00", "Add binary", "alice")
+
+	// The working tree is searched by default, untracked files are not
+	os.WriteFile(filepath.Join(repoPath, "config/app.yaml"), []byte("name: app\ntimeout: 10\n"), 0644)
+	os.WriteFile(filepath.Join(repoPath, "untracked.txt"), []byte("timeout"), 0644)
+
+	result, err := Grep(repoPath, GrepOptions{Pattern: "timeout"})
+	if err != nil {
+		t.Fatalf("Grep failed: %v", err)
+	}
+	if len(result.Matches) != 2 || result.Commit != "" || result.Truncated {
+		t.Fatalf("Expected 2 matches in text files, got %+v", result)
+	}
+	m := result.Matches[0]
+	if m.Path != "config/app.yaml" || m.Line != 2 || m.Column != 1 || m.Text != "timeout: 10" {
+		t.Errorf("Unexpected match: %+v", m)
+	}
+	if m = result.Matches[1]; m.Path != "src/main.go" || m.Line != 4 || len(m.Matches) != 3 {
+		t.Errorf("Expected three matches on the line in main.go, got %+v", m)
+	} else if r := m.Matches[0]; m.Text[r.Start:r.End] != "timeout" || m.Column != r.Start+1 {
+		t.Errorf("Unexpected match range %+v in %q", r, m.Text)
+	}
+
+	// Case-insensitive search with context, limited to Go files
+	result, _ = Grep(repoPath, GrepOptions{Pattern: "TIMEOUT", IgnoreCase: true, Paths: []string{"*.go"}, Context: 1})
+	if len(result.Matches) != 2 {
+		t.Fatalf("Expected 2 case-insensitive matches, got %+v", result.Matches)
+	}
+	if m := result.Matches[0]; m.Line != 3 || len(m.Before) != 1 || m.Before[0] != "" || len(m.After) != 1 {
+		t.Errorf("Unexpected context: %+v", m)
+	}
+
+	// Regular expressions at a revision
+	result, err = Grep(repoPath, GrepOptions{Pattern: "^(timeout|retries): [0-9]+$", Regex: true, Ref: head})
+	if err != nil {
+		t.Fatalf("Grep failed: %v", err)
+	}
+	if result.Commit != head || len(result.Matches) != 2 || result.Matches[1].Text != "retries: 3" {
+		t.Errorf("Expected the committed config lines, got %+v", result)
+	}
+
+	// Fixed strings are not regular expressions
+	if result, _ = Grep(repoPath, GrepOptions{Pattern: "cfg(\""}); len(result.Matches) != 1 {
+		t.Errorf("Expected a fixed-string match, got %+v", result.Matches)
+	}
+
+	result, _ = Grep(repoPath, GrepOptions{Pattern: "timeout", IgnoreCase: true, Limit: 1})
+	if len(result.Matches) != 1 || !result.Truncated {
+		t.Errorf("Expected a truncated result, got %+v", result)
+	}
+
+	if result, err = Grep(repoPath, GrepOptions{Pattern: "no such text"}); err != nil || len(result.Matches) != 0 {
+		t.Errorf("Expected no matches without error, got %+v, %v", result, err)
+	}
+	if _, err := Grep(repoPath, GrepOptions{Pattern: "(", Regex: true}); !errors.Is(err, ErrInvalidPattern) {
+		t.Errorf("Expected ErrInvalidPattern, got %v", err)
+	}
+	if _, err := Grep(repoPath, GrepOptions{Pattern: "x", Ref: "no-such-branch"}); !errors.Is(err, ErrRefNotFound) {
+		t.Errorf("Expected ErrRefNotFound, got %v", err)
+	}
+}
+
+func TestGrepRegexHighlights(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	defer os.RemoveAll(repoPath)
+
+	commitFile(t, repoPath, "notes.txt", "build d1\nport 8080 and 443\n", "Add notes", "alice")
+
+	// \d is a digit class for both git and the highlighting, not a literal d
+	result, err := Grep(repoPath, GrepOptions{Pattern: `\d{3,}`, Regex: true})
+	if err != nil {
+		t.Fatalf("Grep failed: %v", err)
+	}
+	if len(result.Matches) != 1 {
+		t.Fatalf("Expected only the line with numbers, got %+v", result.Matches)
+	}
+	m := result.Matches[0]
+	if m.Line != 2 || m.Column != 6 || len(m.Matches) != 2 {
+		t.Fatalf("Unexpected match: %+v", m)
+	}
+	for i, want := range []string{"8080", "443"} {
+		if r := m.Matches[i]; m.Text[r.Start:r.End] != want {
+			t.Errorf("Expected highlight %q, got %q", want, m.Text[r.Start:r.End])
+		}
+	}
+
+	// Perl features RE2 cannot highlight are refused
+	if _, err := Grep(repoPath, GrepOptions{Pattern: `port(?= )`, Regex: true}); !errors.Is(err, ErrInvalidPattern) {
+		t.Errorf("Expected ErrInvalidPattern for a lookahead, got %v", err)
+	}
+}
+
+func TestSearchContextCanceled(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	defer os.RemoveAll(repoPath)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := GrepContext(ctx, repoPath, GrepOptions{Pattern: "Hello"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled from GrepContext, got %v", err)
+	}
+	if _, err := FindFilesContext(ctx, repoPath, "README"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled from FindFilesContext, got %v", err)
+	}
+	if _, err := LogContext(ctx, repoPath, LogOptions{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled from LogContext, got %v", err)
+	}
+}
+
+func TestFindFiles(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	defer os.RemoveAll(repoPath)
+
+	commitFile(t, repoPath, "docs/Config.md", "x", "Add docs", "alice")
+	commitFile(t, repoPath, "config/app.yaml", "x", "Add config", "alice")
+	os.WriteFile(filepath.Join(repoPath, "config.local"), []byte("x"), 0644)
+
+	files, err := FindFiles(repoPath, "CONFIG")
+	if err != nil {
+		t.Fatalf("FindFiles failed: %v", err)
+	}
+	if len(files) != 2 || files[0] != "config/app.yaml" || files[1] != "docs/Config.md" {
+		t.Errorf("Expected the two tracked config files, got %v", files)
+	}
+
+	if _, err := FindFiles(repoPath, ""); !errors.Is(err, ErrInvalidPattern) {
+		t.Errorf("Expected ErrInvalidPattern, got %v", err)
+	}
+}
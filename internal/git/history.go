@@ -0,0 +1,107 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// historySeparator starts each commit in the output of git log.
+const historySeparator = "\x1e"
+
+// HistoryOptions selects the page of a file's history returned by FileHistory.
+type HistoryOptions struct {
+	Ref   string // Branch, tag or revision to start from (default HEAD)
+	Skip  int    // Number of commits to skip
+	Limit int    // Maximum number of commits to return
+}
+
+// FileRevision is a commit that changed a file, with the change it made.
+// The file's path is the one it had in that commit.
+type FileRevision struct {
+	CommitInfo
+	Change FileChange `json:"change"`
+}
+
+// FileHistoryPage is a single page of a file's history.
+type FileHistoryPage struct {
+	Path      string         `json:"path"`
+	Revisions []FileRevision `json:"revisions"`
+	HasMore   bool           `json:"has_more"`
+}
+
+// FileHistory returns a page of the commits that changed a file, newest
+// first, following the file across renames.
+func FileHistory(path string, file string, opts HistoryOptions) (*FileHistoryPage, error) {
+	r, err := git.PlainOpen(path)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := resolveRef(r, opts.Ref)
+	if err != nil {
+		return nil, err
+	}
+	file = strings.Trim(file, "/")
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultLogLimit
+	}
+	limit = min(limit, MaxLogLimit)
+
+	// Ask for one extra commit to know whether there are more
+	args := []string{"-c", "core.quotePath=false", "log", "--follow", "--find-renames",
+		"--format=" + historySeparator + "%H", "--patch"}
+	args = append(args, diffFlags...)
+	args = append(args, "--skip="+strconv.Itoa(opts.Skip), "--max-count="+strconv.Itoa(limit+1),
+		hash.String(), "--", file)
+	output, err := runGit(path, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &FileHistoryPage{Path: file, Revisions: []FileRevision{}}
+	records := strings.Split(output, historySeparator)[1:]
+	if len(records) == 0 && opts.Skip == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrPathNotFound, file)
+	}
+	if len(records) > limit {
+		records = records[:limit]
+		page.HasMore = true
+	}
+
+	current := file // The file's path in the commit being read
+	for _, record := range records {
+		commitHash, patch, _ := strings.Cut(record, "\n")
+		patch = strings.TrimLeft(patch, "\n")
+
+		c, err := r.CommitObject(plumbing.NewHash(commitHash))
+		if err != nil {
+			return nil, err
+		}
+
+		change := FileChange{Path: current, Status: ChangeModified, Patch: patch}
+		files, err := parseDiff(patch)
+		if err != nil {
+			return nil, err
+		}
+		if len(files) > 0 {
+			f := files[0]
+			change.Path, change.OldPath, change.Status = f.Path, f.OldPath, f.Status
+			change.Additions, change.Deletions, change.Binary = f.Additions, f.Deletions, f.Binary
+		}
+		if change.OldPath != "" {
+			current = change.OldPath
+		} else {
+			current = change.Path
+		}
+
+		page.Revisions = append(page.Revisions, FileRevision{CommitInfo: newCommitInfo(c), Change: change})
+	}
+
+	return page, nil
+}
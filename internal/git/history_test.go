@@ -0,0 +1,66 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestFileHistory(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	defer os.RemoveAll(repoPath)
+
+	added := commitFile(t, repoPath, "old.txt", numberedLines(10, nil), "Add file", "alice")
+	commitFile(t, repoPath, "other.txt", "other", "Unrelated change", "bob")
+	if _, err := runGit(repoPath, "mv", "old.txt", "new.txt"); err != nil {
+		t.Fatal(err)
+	}
+	renamed := commitFile(t, repoPath, "new.txt", numberedLines(10, map[int]string{1: "first"}), "Rename file", "alice")
+	edited := commitFile(t, repoPath, "new.txt", numberedLines(10, map[int]string{1: "first", 10: "last"}), "Edit file", "bob")
+
+	page, err := FileHistory(repoPath, "new.txt", HistoryOptions{})
+	if err != nil {
+		t.Fatalf("FileHistory failed: %v", err)
+	}
+	if len(page.Revisions) != 3 || page.HasMore {
+		t.Fatalf("Expected 3 revisions across the rename, got %+v", page.Revisions)
+	}
+
+	rev := page.Revisions[0]
+	if rev.Hash != edited || rev.Change.Path != "new.txt" || rev.Change.Status != ChangeModified {
+		t.Errorf("Unexpected newest revision: %+v", rev)
+	}
+	if rev.Change.Additions != 1 || rev.Change.Deletions != 1 || !strings.Contains(rev.Change.Patch, "+last") {
+		t.Errorf("Unexpected change: %+v", rev.Change)
+	}
+
+	rev = page.Revisions[1]
+	if rev.Hash != renamed || rev.Change.Status != ChangeRenamed || rev.Change.OldPath != "old.txt" || rev.Change.Path != "new.txt" {
+		t.Errorf("Expected the rename, got %+v", rev.Change)
+	}
+
+	rev = page.Revisions[2]
+	if rev.Hash != added || rev.Change.Status != ChangeAdded || rev.Change.Path != "old.txt" || rev.Change.Additions != 10 {
+		t.Errorf("Expected the file to be added under its old name, got %+v", rev.Change)
+	}
+
+	// Pagination
+	page, _ = FileHistory(repoPath, "new.txt", HistoryOptions{Skip: 1, Limit: 1})
+	if len(page.Revisions) != 1 || page.Revisions[0].Hash != renamed || !page.HasMore {
+		t.Errorf("Expected the rename with more available, got %+v", page)
+	}
+
+	// Starting from an older ref
+	page, _ = FileHistory(repoPath, "old.txt", HistoryOptions{Ref: added})
+	if len(page.Revisions) != 1 {
+		t.Errorf("Expected 1 revision at the first commit, got %d", len(page.Revisions))
+	}
+
+	if _, err := FileHistory(repoPath, "missing.txt", HistoryOptions{}); !errors.Is(err, ErrPathNotFound) {
+		t.Errorf("Expected ErrPathNotFound, got %v", err)
+	}
+	if _, err := FileHistory(repoPath, "new.txt", HistoryOptions{Ref: "no-such-branch"}); !errors.Is(err, ErrRefNotFound) {
+		t.Errorf("Expected ErrRefNotFound, got %v", err)
+	}
+}
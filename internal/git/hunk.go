@@ -0,0 +1,229 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidSelection is returned when a hunk or line selection does not
+// match the diff of the file or selects no changes.
+var ErrInvalidSelection = errors.New("invalid selection")
+
+// LineRange is an inclusive range of line numbers in the text of a diff,
+// starting at 1 with the first line of the diff header.
+type LineRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// HunkSelection selects changes from the unified diff of a single file:
+// whole hunks by their index (starting at 0), and individual added or
+// removed lines by their line number in the diff text.
+type HunkSelection struct {
+	Hunks []int       `json:"hunks"`
+	Lines []LineRange `json:"lines"`
+}
+
+// hunkHeader matches the header line of a hunk, e.g. "@@ -1,4 +1,5 @@ func".
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@(.*)$`)
+
+// patchHunk is a hunk of a single-file unified diff.
+type patchHunk struct {
+	oldStart  int
+	newStart  int
+	section   string   // Text following the header, usually the enclosing function
+	lines     []string // Body lines starting with ' ', '+', '-' or '\'
+	firstLine int      // Line number of the first body line in the diff text
+}
+
+// StageHunks stages the selected changes of a file. The selection refers to
+// the unstaged diff of the file (FileDiff with DiffUnstaged), which equals
+// the diff returned by GetFileDiff while nothing of the file is staged.
+func StageHunks(path string, file string, sel HunkSelection) error {
+	diff, err := FileDiff(path, file, DiffUnstaged)
+	if err != nil {
+		return err
+	}
+	patch, err := selectPatch(diff, sel, false)
+	if err != nil {
+		return err
+	}
+	return applyPatch(path, patch, true, false)
+}
+
+// UnstageHunks removes the selected changes of a file from the index. The
+// selection refers to the staged diff of the file (FileDiff with DiffStaged).
+func UnstageHunks(path string, file string, sel HunkSelection) error {
+	diff, err := FileDiff(path, file, DiffStaged)
+	if err != nil {
+		return err
+	}
+	patch, err := selectPatch(diff, sel, true)
+	if err != nil {
+		return err
+	}
+	return applyPatch(path, patch, true, true)
+}
+
+// applyPatch applies a patch to the index (cached) or the working tree,
+// optionally in reverse.
+func applyPatch(path string, patch string, cached bool, reverse bool) error {
+	args := []string{"apply", "--recount", "--whitespace=nowarn"}
+	if cached {
+		args = append(args, "--cached")
+	}
+	if reverse {
+		args = append(args, "--reverse")
+	}
+	_, err := runGitInput(path, patch, append(args, "-")...)
+	return err
+}
+
+// parsePatch splits a single-file unified diff into its header lines and hunks.
+func parsePatch(diff string) ([]string, []patchHunk, error) {
+	lines := strings.Split(strings.TrimSuffix(diff, "\n"), "\n")
+
+	var (
+		header []string
+		hunks  []patchHunk
+	)
+	for i, line := range lines {
+		if m := hunkHeader.FindStringSubmatch(line); m != nil {
+			oldStart, _ := strconv.Atoi(m[1])
+			newStart, _ := strconv.Atoi(m[3])
+			hunks = append(hunks, patchHunk{
+				oldStart:  oldStart,
+				newStart:  newStart,
+				section:   m[5],
+				firstLine: i + 2,
+			})
+			continue
+		}
+		if len(hunks) == 0 {
+			header = append(header, line)
+			continue
+		}
+		if line == "" {
+			return nil, nil, fmt.Errorf("malformed diff line %d", i+1)
+		}
+		h := &hunks[len(hunks)-1]
+		h.lines = append(h.lines, line)
+	}
+	return header, hunks, nil
+}
+
+// selectPatch builds a patch containing only the selected changes of a
+// diff. Unselected changes are turned into context or left out, depending
+// on whether the patch will be applied forward to the diff's old side or
+// in reverse to its new side.
+func selectPatch(diff string, sel HunkSelection, reverse bool) (string, error) {
+	header, hunks, err := parsePatch(diff)
+	if err != nil {
+		return "", err
+	}
+	if len(hunks) == 0 {
+		return "", fmt.Errorf("%w: the file has no changes that can be selected", ErrInvalidSelection)
+	}
+
+	wholeHunks := make(map[int]bool)
+	for _, i := range sel.Hunks {
+		if i < 0 || i >= len(hunks) {
+			return "", fmt.Errorf("%w: hunk %d does not exist", ErrInvalidSelection, i)
+		}
+		wholeHunks[i] = true
+	}
+	lastLine := hunks[len(hunks)-1].firstLine + len(hunks[len(hunks)-1].lines) - 1
+	selectedLines := make(map[int]bool)
+	for _, lr := range sel.Lines {
+		if lr.Start < 1 || lr.End < lr.Start || lr.End > lastLine {
+			return "", fmt.Errorf("%w: line range %d-%d is outside the diff", ErrInvalidSelection, lr.Start, lr.End)
+		}
+		for n := lr.Start; n <= lr.End; n++ {
+			selectedLines[n] = true
+		}
+	}
+
+	var b strings.Builder
+	for _, line := range header {
+		b.WriteString(line + "\n")
+	}
+
+	delta := 0 // Lines added minus removed by the hunks written so far
+	changed := false
+	for i, h := range hunks {
+		var (
+			body               []string
+			oldCount, newCount int
+			hunkChanged        bool
+			kept               bool // Whether the previous line was kept, for "\ No newline" markers
+		)
+		for j, line := range h.lines {
+			selected := wholeHunks[i] || selectedLines[h.firstLine+j]
+			switch line[0] {
+			case ' ':
+				body = append(body, line)
+				oldCount++
+				newCount++
+				kept = true
+			case '+':
+				switch {
+				case selected:
+					body = append(body, line)
+					newCount++
+					hunkChanged = true
+					kept = true
+				case reverse:
+					// Unselected additions stay on the new side being patched
+					body = append(body, " "+line[1:])
+					oldCount++
+					newCount++
+					kept = true
+				default:
+					kept = false
+				}
+			case '-':
+				switch {
+				case selected:
+					body = append(body, line)
+					oldCount++
+					hunkChanged = true
+					kept = true
+				case !reverse:
+					// Unselected removals stay on the old side being patched
+					body = append(body, " "+line[1:])
+					oldCount++
+					newCount++
+					kept = true
+				default:
+					kept = false
+				}
+			case '\\':
+				if kept {
+					body = append(body, line)
+				}
+			}
+		}
+		if !hunkChanged {
+			continue
+		}
+		changed = true
+
+		oldStart, newStart := h.oldStart, h.oldStart+delta
+		if reverse {
+			oldStart, newStart = h.newStart-delta, h.newStart
+		}
+		fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@%s\n", oldStart, oldCount, newStart, newCount, h.section)
+		for _, line := range body {
+			b.WriteString(line + "\n")
+		}
+		delta += newCount - oldCount
+	}
+
+	if !changed {
+		return "", fmt.Errorf("%w: no added or removed lines selected", ErrInvalidSelection)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,166 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// numberedLines returns n lines "line 1" to "line n", replacing the lines
+// given in edits.
+func numberedLines(n int, edits map[int]string) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		line := fmt.Sprintf("line %d", i)
+		if e, ok := edits[i]; ok {
+			line = e
+		}
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
+
+// diffLine returns the line number of the first line of diff equal to line.
+func diffLine(t *testing.T, diff string, line string) int {
+	t.Helper()
+	for i, l := range strings.Split(diff, "\n") {
+		if l == line {
+			return i + 1
+		}
+	}
+	t.Fatalf("Line %q not found in diff:\n%s", line, diff)
+	return 0
+}
+
+func TestStageHunks(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	defer os.RemoveAll(repoPath)
+
+	commitFile(t, repoPath, "file.txt", numberedLines(20, nil), "Add file", "alice")
+	full := filepath.Join(repoPath, "file.txt")
+	os.WriteFile(full, []byte(numberedLines(20, map[int]string{2: "two", 15: "fifteen"})), 0644)
+
+	diff, err := GetFileDiff(repoPath, "file.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(diff, "\n@@ "); n != 2 {
+		t.Fatalf("Expected 2 hunks, got %d:\n%s", n, diff)
+	}
+
+	// Stage the second hunk only
+	if err := StageHunks(repoPath, "file.txt", HunkSelection{Hunks: []int{1}}); err != nil {
+		t.Fatalf("StageHunks failed: %v", err)
+	}
+	staged, _ := FileDiff(repoPath, "file.txt", DiffStaged)
+	if !strings.Contains(staged, "+fifteen") || strings.Contains(staged, "+two") {
+		t.Errorf("Expected only the second hunk staged:\n%s", staged)
+	}
+	unstaged, _ := FileDiff(repoPath, "file.txt", DiffUnstaged)
+	if !strings.Contains(unstaged, "+two") || strings.Contains(unstaged, "+fifteen") {
+		t.Errorf("Expected the first hunk to remain unstaged:\n%s", unstaged)
+	}
+
+	// Unstage it again
+	if err := UnstageHunks(repoPath, "file.txt", HunkSelection{Hunks: []int{0}}); err != nil {
+		t.Fatalf("UnstageHunks failed: %v", err)
+	}
+	if staged, _ := FileDiff(repoPath, "file.txt", DiffStaged); staged != "" {
+		t.Errorf("Expected nothing staged, got:\n%s", staged)
+	}
+
+	// The working tree is never touched
+	if data, _ := os.ReadFile(full); !strings.Contains(string(data), "two") || !strings.Contains(string(data), "fifteen") {
+		t.Errorf("Expected working tree changes to be kept, got:\n%s", data)
+	}
+
+	if err := StageHunks(repoPath, "file.txt", HunkSelection{Hunks: []int{2}}); !errors.Is(err, ErrInvalidSelection) {
+		t.Errorf("Expected ErrInvalidSelection for missing hunk, got %v", err)
+	}
+	if err := StageHunks(repoPath, "file.txt", HunkSelection{Lines: []LineRange{{Start: 1, End: 2}}}); !errors.Is(err, ErrInvalidSelection) {
+		t.Errorf("Expected ErrInvalidSelection for header lines, got %v", err)
+	}
+	if err := StageHunks(repoPath, "README.md", HunkSelection{Hunks: []int{0}}); !errors.Is(err, ErrInvalidSelection) {
+		t.Errorf("Expected ErrInvalidSelection for unchanged file, got %v", err)
+	}
+}
+
+func TestStageHunksIgnoresDiffConfig(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	defer os.RemoveAll(repoPath)
+
+	commitFile(t, repoPath, "file.txt", numberedLines(20, nil), "Add file", "alice")
+	os.WriteFile(filepath.Join(repoPath, "file.txt"), []byte(numberedLines(20, map[int]string{2: "two", 15: "fifteen"})), 0644)
+
+	// Settings that change what a plain git diff prints
+	for _, kv := range [][2]string{{"color.ui", "always"}, {"diff.noprefix", "true"}, {"diff.external", "false"}} {
+		if _, err := runGit(repoPath, "config", kv[0], kv[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	diff, err := FileDiff(repoPath, "file.txt", DiffUnstaged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(diff, "\x1b[") || !strings.Contains(diff, "--- a/file.txt") {
+		t.Fatalf("Expected a plain diff with prefixes, got:\n%q", diff)
+	}
+
+	if err := StageHunks(repoPath, "file.txt", HunkSelection{Hunks: []int{1}}); err != nil {
+		t.Fatalf("StageHunks failed: %v", err)
+	}
+	staged, _ := FileDiff(repoPath, "file.txt", DiffStaged)
+	if !strings.Contains(staged, "+fifteen") || strings.Contains(staged, "+two") {
+		t.Errorf("Expected only the second hunk staged:\n%s", staged)
+	}
+}
+
+func TestStageLines(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	defer os.RemoveAll(repoPath)
+
+	commitFile(t, repoPath, "file.txt", numberedLines(10, nil), "Add file", "alice")
+	full := filepath.Join(repoPath, "file.txt")
+	os.WriteFile(full, []byte(numberedLines(10, map[int]string{4: "four", 6: "six"})+"eleven\n"), 0644)
+
+	// Stage the replacement of line 4 and the appended line, but not line 6
+	diff, _ := GetFileDiff(repoPath, "file.txt")
+	sel := HunkSelection{Lines: []LineRange{
+		{Start: diffLine(t, diff, "-line 4"), End: diffLine(t, diff, "-line 4")},
+		{Start: diffLine(t, diff, "+four"), End: diffLine(t, diff, "+four")},
+		{Start: diffLine(t, diff, "+eleven"), End: diffLine(t, diff, "+eleven")},
+	}}
+	if err := StageHunks(repoPath, "file.txt", sel); err != nil {
+		t.Fatalf("StageHunks failed: %v", err)
+	}
+
+	index, err := runGit(repoPath, "show", ":file.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := numberedLines(10, map[int]string{4: "four"}) + "eleven\n"; index != want {
+		t.Errorf("Unexpected index content:\n%s\nwant:\n%s", index, want)
+	}
+
+	// Unstage the appended line only
+	staged, _ := FileDiff(repoPath, "file.txt", DiffStaged)
+	line := diffLine(t, staged, "+eleven")
+	if err := UnstageHunks(repoPath, "file.txt", HunkSelection{Lines: []LineRange{{Start: line, End: line}}}); err != nil {
+		t.Fatalf("UnstageHunks failed: %v", err)
+	}
+	index, _ = runGit(repoPath, "show", ":file.txt")
+	if want := numberedLines(10, map[int]string{4: "four"}); index != want {
+		t.Errorf("Unexpected index content after unstaging:\n%s\nwant:\n%s", index, want)
+	}
+
+	// Context lines select no changes
+	diff, _ = FileDiff(repoPath, "file.txt", DiffUnstaged)
+	line = diffLine(t, diff, " line 7")
+	if err := StageHunks(repoPath, "file.txt", HunkSelection{Lines: []LineRange{{Start: line, End: line}}}); !errors.Is(err, ErrInvalidSelection) {
+		t.Errorf("Expected ErrInvalidSelection for context lines, got %v", err)
+	}
+}
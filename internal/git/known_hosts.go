@@ -0,0 +1,199 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/skeema/knownhosts"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+var (
+	// ErrUnknownHostKey is returned when an SSH server's host key is not in
+	// known_hosts. The key is kept pending until accepted with AcceptHostKey.
+	ErrUnknownHostKey = errors.New("unknown SSH host key")
+	// ErrHostKeyMismatch is returned when an SSH server presents a key that
+	// differs from the one recorded in known_hosts.
+	ErrHostKeyMismatch = errors.New("SSH host key mismatch")
+	// ErrHostKeyNotPending is returned when accepting a host key that no
+	// connection has presented.
+	ErrHostKeyNotPending = errors.New("no pending host key")
+	// ErrFingerprintMismatch is returned when accepting a host key with a
+	// fingerprint other than the one the host presented.
+	ErrFingerprintMismatch = errors.New("host key fingerprint does not match")
+)
+
+// KnownHost describes an SSH host key, either recorded in known_hosts or
+// pending acceptance.
+type KnownHost struct {
+	Host        string `json:"host"` // Host pattern as written in known_hosts, e.g. "github.com" or "[git.example.com]:2222"
+	KeyType     string `json:"key_type"`
+	Fingerprint string `json:"fingerprint"` // SHA256 fingerprint, e.g. "SHA256:..."
+}
+
+// HostKeyError reports a host key that failed verification. It wraps
+// ErrUnknownHostKey or ErrHostKeyMismatch.
+type HostKeyError struct {
+	KnownHost
+	err error
+}
+
+func (e *HostKeyError) Error() string {
+	return fmt.Sprintf("%v: %s (%s %s)", e.err, e.Host, e.KeyType, e.Fingerprint)
+}
+
+func (e *HostKeyError) Unwrap() error {
+	return e.err
+}
+
+// pendingHostKeys holds unknown host keys presented by servers, keyed by
+// normalized host, until they are accepted.
+var pendingHostKeys = struct {
+	sync.Mutex
+	keys map[string]gossh.PublicKey
+}{keys: make(map[string]gossh.PublicKey)}
+
+// knownHostsCallback returns a host key callback that only accepts keys
+// listed in known_hosts, along with the host key algorithms to negotiate
+// with addr so that its recorded key type is preferred.
+func knownHostsCallback(addr string) (gossh.HostKeyCallback, []string, error) {
+	path, err := knownHostsPath()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var db *knownhosts.HostKeyDB
+	if _, err := os.Stat(path); err == nil {
+		db, err = knownhosts.NewDB(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read known_hosts: %w", err)
+		}
+	}
+
+	callback := func(hostname string, remote net.Addr, key gossh.PublicKey) error {
+		host := KnownHost{
+			Host:        knownhosts.Normalize(hostname),
+			KeyType:     key.Type(),
+			Fingerprint: gossh.FingerprintSHA256(key),
+		}
+
+		if db != nil {
+			err := db.HostKeyCallback()(hostname, remote, key)
+			switch {
+			case err == nil:
+				return nil
+			case knownhosts.IsHostKeyChanged(err):
+				return &HostKeyError{KnownHost: host, err: ErrHostKeyMismatch}
+			case !knownhosts.IsHostUnknown(err):
+				return err
+			}
+		}
+
+		pendingHostKeys.Lock()
+		pendingHostKeys.keys[host.Host] = key
+		pendingHostKeys.Unlock()
+		return &HostKeyError{KnownHost: host, err: ErrUnknownHostKey}
+	}
+
+	var algorithms []string
+	if db != nil {
+		algorithms = db.HostKeyAlgorithms(addr)
+	}
+	return callback, algorithms, nil
+}
+
+// ListKnownHosts returns the host keys recorded in known_hosts. Hashed
+// host names are returned as written.
+func ListKnownHosts() ([]KnownHost, error) {
+	path, err := knownHostsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return []KnownHost{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	hosts := []KnownHost{}
+	for len(data) > 0 {
+		marker, patterns, key, _, rest, err := gossh.ParseKnownHosts(data)
+		if err != nil {
+			break // io.EOF once only comments and blank lines remain
+		}
+		data = rest
+		if marker != "" {
+			continue // @cert-authority and @revoked lines are not host keys
+		}
+		hosts = append(hosts, KnownHost{
+			Host:        strings.Join(patterns, ","),
+			KeyType:     key.Type(),
+			Fingerprint: gossh.FingerprintSHA256(key),
+		})
+	}
+	return hosts, nil
+}
+
+// PendingHostKeys returns the unknown host keys presented by servers since
+// startup that have not been accepted yet, sorted by host.
+func PendingHostKeys() []KnownHost {
+	pendingHostKeys.Lock()
+	defer pendingHostKeys.Unlock()
+
+	hosts := []KnownHost{}
+	for host, key := range pendingHostKeys.keys {
+		hosts = append(hosts, KnownHost{
+			Host:        host,
+			KeyType:     key.Type(),
+			Fingerprint: gossh.FingerprintSHA256(key),
+		})
+	}
+	sort.Slice(hosts, func(i, j int) bool { return hosts[i].Host < hosts[j].Host })
+	return hosts
+}
+
+// AcceptHostKey appends a pending host key to known_hosts. The fingerprint
+// must match the one the host presented, so that only a key the user has
+// actually reviewed is trusted.
+func AcceptHostKey(host string, fingerprint string) error {
+	pendingHostKeys.Lock()
+	defer pendingHostKeys.Unlock()
+
+	host = knownhosts.Normalize(host)
+	key, ok := pendingHostKeys.keys[host]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrHostKeyNotPending, host)
+	}
+	if gossh.FingerprintSHA256(key) != fingerprint {
+		return fmt.Errorf("%w: %s", ErrFingerprintMismatch, host)
+	}
+
+	path, err := knownHostsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := fmt.Fprintln(f, knownhosts.Line([]string{host}, key)); err != nil {
+		return err
+	}
+
+	delete(pendingHostKeys.keys, host)
+	return nil
+}
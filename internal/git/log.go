@@ -0,0 +1,184 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+const (
+	// DefaultLogLimit is the page size used by Log when no limit is given.
+	DefaultLogLimit = 50
+	// MaxLogLimit is the largest page size Log will return.
+	MaxLogLimit = 500
+)
+
+// ErrRefNotFound is returned when a branch, tag or revision cannot be resolved.
+var ErrRefNotFound = errors.New("reference not found")
+
+// CommitInfo describes a single commit.
+type CommitInfo struct {
+	Hash           string    `json:"hash"`
+	Author         string    `json:"author"`
+	AuthorEmail    string    `json:"author_email"`
+	AuthorDate     time.Time `json:"author_date"`
+	Committer      string    `json:"committer"`
+	CommitterEmail string    `json:"committer_email"`
+	CommitDate     time.Time `json:"commit_date"`
+	Message        string    `json:"message"`
+	Summary        string    `json:"summary"`
+	Parents        []string  `json:"parents"`
+}
+
+// LogOptions filters and paginates the commits returned by Log.
+// Zero values mean "no filter".
+type LogOptions struct {
+	Ref    string     // Branch, tag or revision to start from (default HEAD)
+	Author string     // Case-insensitive substring of author name or email
+	Path   string     // Only commits touching this file or directory
+	Since  *time.Time // Only commits committed at or after this time
+	Until  *time.Time // Only commits committed at or before this time
+	Grep   string     // Case-insensitive substring of the commit message
+	Skip   int        // Number of matching commits to skip
+	Limit  int        // Maximum number of commits to return
+}
+
+// LogPage is a single page of commit history.
+type LogPage struct {
+	Commits []CommitInfo `json:"commits"`
+	HasMore bool         `json:"has_more"`
+}
+
+// Log returns a page of commit history for the repository at the given path,
+// newest first.
+func Log(path string, opts LogOptions) (*LogPage, error) {
+	return LogContext(context.Background(), path, opts)
+}
+
+// LogContext is like Log but stops walking history when ctx is done.
+func LogContext(ctx context.Context, path string, opts LogOptions) (*LogPage, error) {
+	r, err := git.PlainOpen(path)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultLogLimit
+	}
+	if limit > MaxLogLimit {
+		limit = MaxLogLimit
+	}
+
+	page := &LogPage{Commits: []CommitInfo{}}
+
+	from, err := resolveRef(r, opts.Ref)
+	if err != nil {
+		// A repository without commits simply has no history
+		if opts.Ref == "" && errors.Is(err, ErrRefNotFound) {
+			return page, nil
+		}
+		return nil, err
+	}
+
+	logOpts := &git.LogOptions{
+		From:  from,
+		Since: opts.Since,
+		Until: opts.Until,
+	}
+	if p := strings.Trim(opts.Path, "/"); p != "" {
+		logOpts.PathFilter = func(file string) bool {
+			return file == p || strings.HasPrefix(file, p+"/")
+		}
+	}
+
+	iter, err := r.Log(logOpts)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	author := strings.ToLower(opts.Author)
+	grep := strings.ToLower(opts.Grep)
+	skipped := 0
+
+	err = iter.ForEach(func(c *object.Commit) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if author != "" &&
+			!strings.Contains(strings.ToLower(c.Author.Name), author) &&
+			!strings.Contains(strings.ToLower(c.Author.Email), author) {
+			return nil
+		}
+		if grep != "" && !strings.Contains(strings.ToLower(c.Message), grep) {
+			return nil
+		}
+		if skipped < opts.Skip {
+			skipped++
+			return nil
+		}
+		if len(page.Commits) == limit {
+			// One more match exists beyond this page
+			page.HasMore = true
+			return storer.ErrStop
+		}
+		page.Commits = append(page.Commits, newCommitInfo(c))
+		return nil
+	})
+	if err != nil && err != storer.ErrStop {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// newCommitInfo converts a go-git commit object into a CommitInfo.
+func newCommitInfo(c *object.Commit) CommitInfo {
+	parents := make([]string, 0, len(c.ParentHashes))
+	for _, p := range c.ParentHashes {
+		parents = append(parents, p.String())
+	}
+
+	return CommitInfo{
+		Hash:           c.Hash.String(),
+		Author:         c.Author.Name,
+		AuthorEmail:    c.Author.Email,
+		AuthorDate:     c.Author.When,
+		Committer:      c.Committer.Name,
+		CommitterEmail: c.Committer.Email,
+		CommitDate:     c.Committer.When,
+		Message:        c.Message,
+		Summary:        commitSummary(c.Message),
+		Parents:        parents,
+	}
+}
+
+// commitSummary returns the first line of a commit message.
+func commitSummary(msg string) string {
+	summary, _, _ := strings.Cut(strings.TrimSpace(msg), "\n")
+	return strings.TrimSpace(summary)
+}
+
+// resolveRef resolves a branch, tag or revision expression to a commit hash.
+// An empty ref resolves to HEAD.
+func resolveRef(r *git.Repository, ref string) (plumbing.Hash, error) {
+	if ref == "" {
+		ref = "HEAD"
+	}
+	hash, err := r.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		if errors.Is(err, plumbing.ErrReferenceNotFound) || errors.Is(err, plumbing.ErrObjectNotFound) {
+			return plumbing.ZeroHash, fmt.Errorf("%w: %s", ErrRefNotFound, ref)
+		}
+		return plumbing.ZeroHash, err
+	}
+	return *hash, nil
+}
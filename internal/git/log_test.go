@@ -0,0 +1,61 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestLog(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	defer os.RemoveAll(repoPath)
+
+	commitFile(t, repoPath, "docs/a.txt", "a", "Add docs", "alice")
+	commitFile(t, repoPath, "b.txt", "b", "Fix bug in b", "bob")
+	commitFile(t, repoPath, "docs/c.txt", "c", "More docs", "alice")
+
+	page, err := Log(repoPath, LogOptions{})
+	if err != nil {
+		t.Fatalf("Log failed: %v", err)
+	}
+	if len(page.Commits) != 4 {
+		t.Fatalf("Expected 4 commits, got %d", len(page.Commits))
+	}
+	if page.Commits[0].Summary != "More docs" {
+		t.Errorf("Expected newest commit first, got %q", page.Commits[0].Summary)
+	}
+	if len(page.Commits[0].Parents) != 1 || page.Commits[0].Parents[0] != page.Commits[1].Hash {
+		t.Errorf("Expected parent to be previous commit, got %v", page.Commits[0].Parents)
+	}
+
+	// Pagination
+	page, err = Log(repoPath, LogOptions{Skip: 1, Limit: 2})
+	if err != nil {
+		t.Fatalf("Log failed: %v", err)
+	}
+	if len(page.Commits) != 2 || !page.HasMore {
+		t.Errorf("Expected 2 commits with more available, got %d (has_more=%v)", len(page.Commits), page.HasMore)
+	}
+	if page.Commits[0].Summary != "Fix bug in b" {
+		t.Errorf("Expected skip to drop newest commit, got %q", page.Commits[0].Summary)
+	}
+
+	// Filters
+	page, _ = Log(repoPath, LogOptions{Author: "ALICE"})
+	if len(page.Commits) != 2 {
+		t.Errorf("Expected 2 commits by alice, got %d", len(page.Commits))
+	}
+	page, _ = Log(repoPath, LogOptions{Path: "docs"})
+	if len(page.Commits) != 2 {
+		t.Errorf("Expected 2 commits touching docs, got %d", len(page.Commits))
+	}
+	page, _ = Log(repoPath, LogOptions{Grep: "bug"})
+	if len(page.Commits) != 1 {
+		t.Errorf("Expected 1 commit matching 'bug', got %d", len(page.Commits))
+	}
+
+	// Unknown ref
+	if _, err := Log(repoPath, LogOptions{Ref: "no-such-branch"}); !errors.Is(err, ErrRefNotFound) {
+		t.Errorf("Expected ErrRefNotFound, got %v", err)
+	}
+}
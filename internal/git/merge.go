@@ -0,0 +1,253 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// Merge strategies accepted by Merge. The zero value fast-forwards when
+// possible and creates a merge commit otherwise.
+const (
+	MergeFastForwardOnly = "ff-only"
+	MergeNoFastForward   = "no-ff"
+	MergeSquash          = "squash"
+)
+
+// Merge outcomes reported in MergeResult.Status.
+const (
+	MergeUpToDate    = "up_to_date"
+	MergeFastForward = "fast_forward"
+	MergeCommitted   = "merged"
+	MergeSquashed    = "squashed" // Changes staged, not committed
+	MergeConflicted  = "conflict"
+)
+
+// Repository states reported in Status.State.
+const (
+	StateMerging = "merging"
+)
+
+var (
+	// ErrInvalidStrategy is returned for an unknown merge strategy.
+	ErrInvalidStrategy = errors.New("invalid merge strategy")
+	// ErrNotFastForward is returned when a fast-forward only merge is not possible.
+	ErrNotFastForward = errors.New("not possible to fast-forward")
+	// ErrOperationInProgress is returned when a merge or similar operation
+	// has to be finished or aborted first.
+	ErrOperationInProgress = errors.New("another operation is in progress")
+	// ErrLocalChanges is returned when uncommitted changes would be overwritten.
+	ErrLocalChanges = errors.New("local changes would be overwritten")
+)
+
+// MergeOptions configures Merge.
+type MergeOptions struct {
+	Ref      string // Branch, tag or commit to merge into the current branch
+	Strategy string // One of the Merge* strategies; fast-forward when possible if empty
+	Message  string // Merge commit message; git's default when empty
+}
+
+// MergeResult reports the outcome of a merge.
+type MergeResult struct {
+	Status    string     `json:"status"`
+	Commit    string     `json:"commit"` // HEAD after the merge
+	Conflicts []Conflict `json:"conflicts,omitempty"`
+}
+
+// Conflict describes an unmerged path and the blob IDs of its versions. A
+// version is empty when the path does not exist on that side, e.g. when a
+// file was deleted on one branch and modified on the other.
+type Conflict struct {
+	Path   string `json:"path"`
+	Base   string `json:"base,omitempty"`
+	Ours   string `json:"ours,omitempty"`
+	Theirs string `json:"theirs,omitempty"`
+}
+
+// Merge merges a ref into the current branch. It uses the git command-line
+// tool as go-git only supports fast-forward merges. Conflicts are not an
+// error: they are reported in the result and leave the repository in the
+// merging state. A conflicting squash merge leaves the conflicts in the
+// index without a merge in progress: once resolved, the changes are
+// committed like any other, or discarded with AbortOperation.
+func Merge(path string, opts MergeOptions) (*MergeResult, error) {
+	r, err := git.PlainOpen(path)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{"merge", "--no-edit"}
+	switch opts.Strategy {
+	case "":
+	case MergeFastForwardOnly:
+		args = append(args, "--ff-only")
+	case MergeNoFastForward:
+		args = append(args, "--no-ff")
+	case MergeSquash:
+		args = append(args, "--squash")
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrInvalidStrategy, opts.Strategy)
+	}
+	if opts.Message != "" {
+		args = append(args, "-m", opts.Message)
+	}
+
+	if state := repoState(r); state != "" {
+		return nil, fmt.Errorf("%w: %s", ErrOperationInProgress, state)
+	}
+	// Conflicts of an earlier squash merge are not reported by repoState
+	conflicts, err := Conflicts(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(conflicts) > 0 {
+		return nil, fmt.Errorf("%w: %d unmerged file(s)", ErrOperationInProgress, len(conflicts))
+	}
+	if _, err := resolveRef(r, opts.Ref); err != nil {
+		return nil, err
+	}
+
+	before, err := r.Head()
+	if err != nil {
+		return nil, err
+	}
+
+	_, mergeErr := runGit(path, append(args, "--", opts.Ref)...)
+	if mergeErr != nil {
+		conflicts, err := Conflicts(path)
+		if err != nil {
+			return nil, err
+		}
+		if len(conflicts) > 0 {
+			return &MergeResult{Status: MergeConflicted, Commit: before.Hash().String(), Conflicts: conflicts}, nil
+		}
+		return nil, mergeError(mergeErr)
+	}
+
+	after, err := r.Head()
+	if err != nil {
+		return nil, err
+	}
+	result := &MergeResult{Commit: after.Hash().String()}
+
+	switch {
+	case opts.Strategy == MergeSquash:
+		result.Status = MergeSquashed
+		if !hasStagedChanges(r) {
+			result.Status = MergeUpToDate
+		}
+	case after.Hash() == before.Hash():
+		result.Status = MergeUpToDate
+	default:
+		commit, err := r.CommitObject(after.Hash())
+		if err != nil {
+			return nil, err
+		}
+		result.Status = MergeFastForward
+		if commit.NumParents() > 1 {
+			result.Status = MergeCommitted
+		}
+	}
+	return result, nil
+}
+
+// Conflicts returns the unmerged paths of the index with the blob IDs of
+// their base (stage 1), ours (stage 2) and theirs (stage 3) versions.
+func Conflicts(path string) ([]Conflict, error) {
+	output, err := runGit(path, "ls-files", "--unmerged", "-z")
+	if err != nil {
+		return nil, err
+	}
+
+	conflicts := []Conflict{}
+	index := map[string]int{}
+	for _, entry := range strings.Split(output, "\x00") {
+		// Each entry is "<mode> <blob> <stage>\t<path>"
+		meta, file, ok := strings.Cut(entry, "\t")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(meta)
+		if len(fields) != 3 {
+			continue
+		}
+
+		i, seen := index[file]
+		if !seen {
+			i = len(conflicts)
+			index[file] = i
+			conflicts = append(conflicts, Conflict{Path: file})
+		}
+		switch fields[2] {
+		case "1":
+			conflicts[i].Base = fields[1]
+		case "2":
+			conflicts[i].Ours = fields[1]
+		case "3":
+			conflicts[i].Theirs = fields[1]
+		}
+	}
+	return conflicts, nil
+}
+
+// mergeError maps well-known failure messages of git merge to sentinel errors.
+func mergeError(err error) error {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "Not possible to fast-forward"),
+		strings.Contains(msg, "not possible to fast-forward"):
+		return fmt.Errorf("%w: %v", ErrNotFastForward, err)
+	case strings.Contains(msg, "would be overwritten"):
+		return fmt.Errorf("%w: %v", ErrLocalChanges, err)
+	}
+	return err
+}
+
+// repoState returns the operation in progress in the repository, or an
+// empty string if there is none.
+func repoState(r *git.Repository) string {
+	dir := gitDir(r)
+	if dir == "" {
+		return ""
+	}
+	if _, err := os.Stat(filepath.Join(dir, "MERGE_HEAD")); err == nil {
+		return StateMerging
+	}
+	for _, name := range []string{"rebase-merge", "rebase-apply"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return StateRebasing
+		}
+	}
+	return ""
+}
+
+// gitDir returns the path of the repository's .git directory.
+func gitDir(r *git.Repository) string {
+	if s, ok := r.Storer.(*filesystem.Storage); ok {
+		return s.Filesystem().Root()
+	}
+	return ""
+}
+
+// hasStagedChanges reports whether the index differs from HEAD.
+func hasStagedChanges(r *git.Repository) bool {
+	w, err := r.Worktree()
+	if err != nil {
+		return false
+	}
+	status, err := w.Status()
+	if err != nil {
+		return false
+	}
+	for _, fs := range status {
+		if fs.Staging != git.Unmodified && fs.Staging != git.Untracked {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,162 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	defer os.RemoveAll(repoPath)
+	setIdentity(t, repoPath)
+
+	// Fast-forward
+	if err := CreateBranch(repoPath, "feature", "", true); err != nil {
+		t.Fatal(err)
+	}
+	featureHash := commitFile(t, repoPath, "feature.txt", "f", "Add feature", "alice")
+	CheckoutBranch(repoPath, "master")
+
+	result, err := Merge(repoPath, MergeOptions{Ref: "feature", Strategy: MergeFastForwardOnly})
+	if err != nil {
+		t.Fatalf("Fast-forward merge failed: %v", err)
+	}
+	if result.Status != MergeFastForward || result.Commit != featureHash {
+		t.Errorf("Unexpected fast-forward result: %+v", result)
+	}
+
+	result, err = Merge(repoPath, MergeOptions{Ref: "feature"})
+	if err != nil || result.Status != MergeUpToDate {
+		t.Errorf("Expected up to date, got %+v (err %v)", result, err)
+	}
+
+	// Diverged branches cannot be fast-forwarded
+	CheckoutBranch(repoPath, "feature")
+	commitFile(t, repoPath, "feature2.txt", "f2", "More feature", "alice")
+	CheckoutBranch(repoPath, "master")
+	commitFile(t, repoPath, "main.txt", "m", "Main work", "bob")
+
+	if _, err := Merge(repoPath, MergeOptions{Ref: "feature", Strategy: MergeFastForwardOnly}); !errors.Is(err, ErrNotFastForward) {
+		t.Errorf("Expected ErrNotFastForward, got %v", err)
+	}
+
+	result, err = Merge(repoPath, MergeOptions{Ref: "feature", Strategy: MergeNoFastForward, Message: "Merge feature"})
+	if err != nil || result.Status != MergeCommitted {
+		t.Fatalf("Expected merge commit, got %+v (err %v)", result, err)
+	}
+	detail, err := GetCommit(repoPath, result.Commit, 0)
+	if err != nil || len(detail.Parents) != 2 || detail.Summary != "Merge feature" {
+		t.Errorf("Unexpected merge commit: %+v (err %v)", detail, err)
+	}
+
+	if _, err := Merge(repoPath, MergeOptions{Ref: "feature", Strategy: "octopus"}); !errors.Is(err, ErrInvalidStrategy) {
+		t.Errorf("Expected ErrInvalidStrategy, got %v", err)
+	}
+	if _, err := Merge(repoPath, MergeOptions{Ref: "missing"}); !errors.Is(err, ErrRefNotFound) {
+		t.Errorf("Expected ErrRefNotFound, got %v", err)
+	}
+}
+
+func TestMergeSquash(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	defer os.RemoveAll(repoPath)
+	setIdentity(t, repoPath)
+
+	CreateBranch(repoPath, "feature", "", true)
+	commitFile(t, repoPath, "a.txt", "a", "Add a", "alice")
+	commitFile(t, repoPath, "b.txt", "b", "Add b", "alice")
+	CheckoutBranch(repoPath, "master")
+	head := commitFile(t, repoPath, "main.txt", "m", "Main work", "bob")
+
+	result, err := Merge(repoPath, MergeOptions{Ref: "feature", Strategy: MergeSquash})
+	if err != nil {
+		t.Fatalf("Squash merge failed: %v", err)
+	}
+	if result.Status != MergeSquashed || result.Commit != head {
+		t.Errorf("Unexpected squash result: %+v", result)
+	}
+
+	status, _ := GetStatus(repoPath)
+	if status.State != "" || status.Worktree.File("a.txt").Staging != 'A' || status.Worktree.File("b.txt").Staging != 'A' {
+		t.Errorf("Expected squashed changes staged, got %+v", status)
+	}
+}
+
+func TestMergeSquashConflict(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	defer os.RemoveAll(repoPath)
+	setIdentity(t, repoPath)
+
+	commitFile(t, repoPath, "file.txt", "base\n", "Base", "alice")
+	CreateBranch(repoPath, "feature", "", true)
+	commitFile(t, repoPath, "file.txt", "theirs\n", "Theirs", "alice")
+	CheckoutBranch(repoPath, "master")
+	head := commitFile(t, repoPath, "file.txt", "ours\n", "Ours", "bob")
+
+	result, err := Merge(repoPath, MergeOptions{Ref: "feature", Strategy: MergeSquash})
+	if err != nil {
+		t.Fatalf("Squash merge failed: %v", err)
+	}
+	if result.Status != MergeConflicted || result.Commit != head || len(result.Conflicts) != 1 {
+		t.Fatalf("Unexpected squash conflict result: %+v", result)
+	}
+
+	// No merge is in progress, but the conflicts block another merge and
+	// continuing until they are resolved
+	if _, err := Merge(repoPath, MergeOptions{Ref: "feature"}); !errors.Is(err, ErrOperationInProgress) {
+		t.Errorf("Expected ErrOperationInProgress, got %v", err)
+	}
+	if err := ContinueOperation(repoPath); !errors.Is(err, ErrUnresolvedConflicts) {
+		t.Errorf("Expected ErrUnresolvedConflicts, got %v", err)
+	}
+
+	if err := AbortOperation(repoPath); err != nil {
+		t.Fatalf("AbortOperation failed: %v", err)
+	}
+	status, _ := GetStatus(repoPath)
+	if status.State != "" || !status.Clean {
+		t.Errorf("Expected clean repository after abort, got %+v", status)
+	}
+	content, _ := os.ReadFile(filepath.Join(repoPath, "file.txt"))
+	if string(content) != "ours\n" {
+		t.Errorf("Expected our version after abort, got %q", content)
+	}
+	if _, err := os.Stat(filepath.Join(repoPath, ".git", "SQUASH_MSG")); !os.IsNotExist(err) {
+		t.Errorf("Expected squash message to be removed, got %v", err)
+	}
+}
+
+func TestMergeConflict(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	defer os.RemoveAll(repoPath)
+	setIdentity(t, repoPath)
+
+	commitFile(t, repoPath, "file.txt", "base\n", "Base", "alice")
+	CreateBranch(repoPath, "feature", "", true)
+	commitFile(t, repoPath, "file.txt", "theirs\n", "Theirs", "alice")
+	CheckoutBranch(repoPath, "master")
+	head := commitFile(t, repoPath, "file.txt", "ours\n", "Ours", "bob")
+
+	result, err := Merge(repoPath, MergeOptions{Ref: "feature"})
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if result.Status != MergeConflicted || result.Commit != head || len(result.Conflicts) != 1 {
+		t.Fatalf("Unexpected conflict result: %+v", result)
+	}
+	c := result.Conflicts[0]
+	if c.Path != "file.txt" || c.Base == "" || c.Ours == "" || c.Theirs == "" || c.Ours == c.Theirs {
+		t.Errorf("Unexpected conflict: %+v", c)
+	}
+
+	status, err := GetStatus(repoPath)
+	if err != nil || status.State != StateMerging {
+		t.Errorf("Expected merging state, got %+v (err %v)", status, err)
+	}
+
+	if _, err := Merge(repoPath, MergeOptions{Ref: "feature"}); !errors.Is(err, ErrOperationInProgress) {
+		t.Errorf("Expected ErrOperationInProgress, got %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package git
+
+import (
+	"bytes"
+	"strings"
+
+	fdiff "github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// File change statuses reported in FileChange.Status.
+const (
+	ChangeAdded    = "added"
+	ChangeDeleted  = "deleted"
+	ChangeModified = "modified"
+	ChangeRenamed  = "renamed"
+)
+
+// FileChange describes how a single file changed between two trees.
+type FileChange struct {
+	Path      string `json:"path"`
+	OldPath   string `json:"old_path,omitempty"`
+	Status    string `json:"status"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+	Binary    bool   `json:"binary"`
+	Patch     string `json:"patch,omitempty"`
+}
+
+// singleFilePatch adapts one FilePatch so it can be encoded on its own.
+type singleFilePatch struct {
+	fp fdiff.FilePatch
+}
+
+func (p singleFilePatch) FilePatches() []fdiff.FilePatch { return []fdiff.FilePatch{p.fp} }
+func (p singleFilePatch) Message() string                { return "" }
+
+// fileChangesFromPatch converts a go-git patch into per-file changes with
+// line counts. Unified patch text is included when withPatch is true.
+func fileChangesFromPatch(p *object.Patch, withPatch bool) ([]FileChange, error) {
+	changes := []FileChange{}
+	for _, fp := range p.FilePatches() {
+		fc, err := newFileChange(fp, withPatch)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, fc)
+	}
+	return changes, nil
+}
+
+// newFileChange builds a FileChange from a single file patch.
+func newFileChange(fp fdiff.FilePatch, withPatch bool) (FileChange, error) {
+	var fc FileChange
+
+	from, to := fp.Files()
+	switch {
+	case from == nil:
+		fc.Path = to.Path()
+		fc.Status = ChangeAdded
+	case to == nil:
+		fc.Path = from.Path()
+		fc.Status = ChangeDeleted
+	case from.Path() != to.Path():
+		fc.Path = to.Path()
+		fc.OldPath = from.Path()
+		fc.Status = ChangeRenamed
+	default:
+		fc.Path = to.Path()
+		fc.Status = ChangeModified
+	}
+
+	fc.Binary = fp.IsBinary()
+	for _, chunk := range fp.Chunks() {
+		n := countLines(chunk.Content())
+		switch chunk.Type() {
+		case fdiff.Add:
+			fc.Additions += n
+		case fdiff.Delete:
+			fc.Deletions += n
+		}
+	}
+
+	if withPatch {
+		var buf bytes.Buffer
+		if err := fdiff.NewUnifiedEncoder(&buf, fdiff.DefaultContextLines).Encode(singleFilePatch{fp}); err != nil {
+			return fc, err
+		}
+		fc.Patch = buf.String()
+	}
+
+	return fc, nil
+}
+
+// countLines counts the lines in a chunk of text, including a final
+// line without a trailing newline.
+func countLines(s string) int {
+	if s == "" {
+		return 0
+	}
+	n := strings.Count(s, "\n")
+	if !strings.HasSuffix(s, "\n") {
+		n++
+	}
+	return n
+}
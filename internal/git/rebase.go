@@ -0,0 +1,154 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// StateRebasing is reported in Status.State while a rebase is stopped.
+const StateRebasing = "rebasing"
+
+// Rebase outcomes reported in RebaseResult.Status.
+const (
+	RebaseUpToDate   = "up_to_date"
+	RebaseDone       = "rebased"
+	RebaseConflicted = "conflict"
+)
+
+// ErrRebaseConflict is returned by a rebasing Pull, or by continuing a
+// rebase, that stopped on conflicts, which must be resolved before
+// continuing the rebase.
+var ErrRebaseConflict = errors.New("rebase stopped on conflicts")
+
+// RebaseOptions configures Rebase.
+type RebaseOptions struct {
+	Onto      string // Branch, tag or commit to replay the current branch onto
+	Autostash bool   // Stash local changes before and reapply them after the rebase
+}
+
+// RebaseResult reports the outcome of a rebase step.
+type RebaseResult struct {
+	Status    string     `json:"status"`
+	Commit    string     `json:"commit"` // HEAD after the step
+	Conflicts []Conflict `json:"conflicts,omitempty"`
+}
+
+// Rebase replays the commits of the current branch onto another ref. It
+// uses the git command-line tool as go-git does not support rebasing. When
+// a commit does not apply cleanly the rebase stops in the rebasing state
+// and the conflicts are reported in the result.
+func Rebase(path string, opts RebaseOptions) (*RebaseResult, error) {
+	r, err := git.PlainOpen(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if state := repoState(r); state != "" {
+		return nil, fmt.Errorf("%w: %s", ErrOperationInProgress, state)
+	}
+	if _, err := resolveRef(r, opts.Onto); err != nil {
+		return nil, err
+	}
+
+	before, err := r.Head()
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{"rebase"}
+	if opts.Autostash {
+		args = append(args, "--autostash")
+	}
+	result, err := runRebase(r, path, append(args, opts.Onto))
+	if err == nil && result.Status == RebaseDone && result.Commit == before.Hash().String() {
+		result.Status = RebaseUpToDate
+	}
+	return result, err
+}
+
+// RebaseContinue continues a stopped rebase after its conflicts have been
+// resolved and staged.
+func RebaseContinue(path string) (*RebaseResult, error) {
+	return rebaseStep(path, "--continue")
+}
+
+// RebaseSkip skips the commit a rebase stopped on and continues with the next.
+func RebaseSkip(path string) (*RebaseResult, error) {
+	return rebaseStep(path, "--skip")
+}
+
+// RebaseAbort aborts a stopped rebase, restoring the branch to where it was.
+func RebaseAbort(path string) error {
+	r, err := git.PlainOpen(path)
+	if err != nil {
+		return err
+	}
+	if repoState(r) != StateRebasing {
+		return ErrNoOperation
+	}
+
+	_, err = runGit(path, "rebase", "--abort")
+	return err
+}
+
+// rebaseStep runs "git rebase <flag>" on a stopped rebase.
+func rebaseStep(path string, flag string) (*RebaseResult, error) {
+	r, err := git.PlainOpen(path)
+	if err != nil {
+		return nil, err
+	}
+	if repoState(r) != StateRebasing {
+		return nil, ErrNoOperation
+	}
+
+	if flag == "--continue" {
+		conflicts, err := Conflicts(path)
+		if err != nil {
+			return nil, err
+		}
+		if len(conflicts) > 0 {
+			return nil, fmt.Errorf("%w: %d file(s)", ErrUnresolvedConflicts, len(conflicts))
+		}
+	}
+	return runRebase(r, path, []string{"rebase", flag})
+}
+
+// runRebase runs a rebase command and reports where it ended.
+func runRebase(r *git.Repository, path string, args []string) (*RebaseResult, error) {
+	_, rebaseErr := runGit(path, args...)
+
+	after, err := r.Head()
+	if err != nil {
+		return nil, err
+	}
+	result := &RebaseResult{Commit: after.Hash().String()}
+
+	if repoState(r) == StateRebasing {
+		conflicts, err := Conflicts(path)
+		if err != nil {
+			return nil, err
+		}
+		result.Status = RebaseConflicted
+		result.Conflicts = conflicts
+		return result, nil
+	}
+	if rebaseErr != nil {
+		return nil, rebaseError(rebaseErr)
+	}
+
+	result.Status = RebaseDone
+	return result, nil
+}
+
+// rebaseError maps well-known failure messages of git rebase to sentinel errors.
+func rebaseError(err error) error {
+	msg := err.Error()
+	if strings.Contains(msg, "unstaged changes") || strings.Contains(msg, "uncommitted changes") ||
+		strings.Contains(msg, "would be overwritten") {
+		return fmt.Errorf("%w: %v", ErrLocalChanges, err)
+	}
+	return err
+}
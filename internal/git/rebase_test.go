@@ -0,0 +1,190 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+)
+
+func TestRebase(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	defer os.RemoveAll(repoPath)
+	setIdentity(t, repoPath)
+
+	CreateBranch(repoPath, "feature", "", true)
+	commitFile(t, repoPath, "feature.txt", "f", "Add feature", "alice")
+	CheckoutBranch(repoPath, "master")
+	mainHash := commitFile(t, repoPath, "main.txt", "m", "Main work", "bob")
+	CheckoutBranch(repoPath, "feature")
+
+	result, err := Rebase(repoPath, RebaseOptions{Onto: "master"})
+	if err != nil {
+		t.Fatalf("Rebase failed: %v", err)
+	}
+	if result.Status != RebaseDone {
+		t.Errorf("Expected rebased, got %+v", result)
+	}
+	detail, err := GetCommit(repoPath, result.Commit, 0)
+	if err != nil || len(detail.Parents) != 1 || detail.Parents[0] != mainHash {
+		t.Errorf("Expected feature commit on top of master, got %+v (err %v)", detail, err)
+	}
+
+	result, err = Rebase(repoPath, RebaseOptions{Onto: "master"})
+	if err != nil || result.Status != RebaseUpToDate {
+		t.Errorf("Expected up to date, got %+v (err %v)", result, err)
+	}
+
+	// Local changes are refused unless stashed around the rebase
+	CheckoutBranch(repoPath, "master")
+	commitFile(t, repoPath, "main2.txt", "m2", "More main work", "bob")
+	CheckoutBranch(repoPath, "feature")
+	os.WriteFile(filepath.Join(repoPath, "feature.txt"), []byte("dirty"), 0644)
+
+	if _, err := Rebase(repoPath, RebaseOptions{Onto: "master"}); !errors.Is(err, ErrLocalChanges) {
+		t.Errorf("Expected ErrLocalChanges, got %v", err)
+	}
+	result, err = Rebase(repoPath, RebaseOptions{Onto: "master", Autostash: true})
+	if err != nil || result.Status != RebaseDone {
+		t.Fatalf("Autostash rebase failed: %+v (err %v)", result, err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(repoPath, "feature.txt")); string(data) != "dirty" {
+		t.Errorf("Expected local change to be restored, got %q", data)
+	}
+
+	if _, err := Rebase(repoPath, RebaseOptions{Onto: "missing"}); !errors.Is(err, ErrRefNotFound) {
+		t.Errorf("Expected ErrRefNotFound, got %v", err)
+	}
+	if _, err := RebaseContinue(repoPath); !errors.Is(err, ErrNoOperation) {
+		t.Errorf("Expected ErrNoOperation, got %v", err)
+	}
+}
+
+func TestRebaseConflict(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	defer os.RemoveAll(repoPath)
+	setIdentity(t, repoPath)
+
+	CreateBranch(repoPath, "feature", "", true)
+	commitFile(t, repoPath, "README.md", "feature", "Feature edit", "alice")
+	commitFile(t, repoPath, "other.txt", "o", "Add other", "alice")
+	CheckoutBranch(repoPath, "master")
+	mainHash := commitFile(t, repoPath, "README.md", "main", "Main edit", "bob")
+	CheckoutBranch(repoPath, "feature")
+
+	result, err := Rebase(repoPath, RebaseOptions{Onto: "master"})
+	if err != nil {
+		t.Fatalf("Rebase failed: %v", err)
+	}
+	if result.Status != RebaseConflicted || len(result.Conflicts) != 1 || result.Conflicts[0].Path != "README.md" {
+		t.Fatalf("Expected conflict on README.md, got %+v", result)
+	}
+
+	status, _ := GetStatus(repoPath)
+	if status.State != StateRebasing {
+		t.Errorf("Expected rebasing state, got %q", status.State)
+	}
+	if _, err := Rebase(repoPath, RebaseOptions{Onto: "master"}); !errors.Is(err, ErrOperationInProgress) {
+		t.Errorf("Expected ErrOperationInProgress, got %v", err)
+	}
+	if _, err := RebaseContinue(repoPath); !errors.Is(err, ErrUnresolvedConflicts) {
+		t.Errorf("Expected ErrUnresolvedConflicts, got %v", err)
+	}
+
+	// Skipping the conflicting commit replays the rest
+	result, err = RebaseSkip(repoPath)
+	if err != nil || result.Status != RebaseDone {
+		t.Fatalf("Skip failed: %+v (err %v)", result, err)
+	}
+	detail, _ := GetCommit(repoPath, result.Commit, 0)
+	if detail.Summary != "Add other" || detail.Parents[0] != mainHash {
+		t.Errorf("Unexpected commit after skip: %+v", detail)
+	}
+
+	// A resolved conflict is continued through the generic operation
+	commitFile(t, repoPath, "README.md", "feature again", "Feature edit again", "alice")
+	featureHead := commitFile(t, repoPath, "README.md", "feature final", "Feature final edit", "alice")
+	if result, _ := Rebase(repoPath, RebaseOptions{Onto: "master"}); result.Status != RebaseUpToDate {
+		t.Fatalf("Expected up to date, got %+v", result)
+	}
+	CheckoutBranch(repoPath, "master")
+	commitFile(t, repoPath, "README.md", "main again", "Main edit again", "bob")
+	CheckoutBranch(repoPath, "feature")
+
+	if result, _ := Rebase(repoPath, RebaseOptions{Onto: "master"}); result.Status != RebaseConflicted {
+		t.Fatalf("Expected conflict, got %+v", result)
+	}
+	WriteResolution(repoPath, "README.md", []byte("resolved"))
+	MarkResolved(repoPath, "README.md")
+	// The next commit conflicts again, so the rebase is not finished
+	if err := ContinueOperation(repoPath); !errors.Is(err, ErrRebaseConflict) {
+		t.Fatalf("Expected ErrRebaseConflict, got %v", err)
+	}
+
+	// Aborting restores the branch
+	status, _ = GetStatus(repoPath)
+	if status.State != StateRebasing {
+		t.Fatalf("Expected rebase to stop on the next commit, got %+v", status)
+	}
+	if err := AbortOperation(repoPath); err != nil {
+		t.Fatalf("AbortOperation failed: %v", err)
+	}
+	r, _ := git.PlainOpen(repoPath)
+	head, _ := r.Head()
+	if head.Hash().String() != featureHead || head.Name().Short() != "feature" {
+		t.Errorf("Expected feature to be restored to %s, got %s", featureHead, head)
+	}
+	if err := RebaseAbort(repoPath); !errors.Is(err, ErrNoOperation) {
+		t.Errorf("Expected ErrNoOperation, got %v", err)
+	}
+}
+
+func TestPullRebase(t *testing.T) {
+	localA, remote := setupTestRepoWithRemote(t)
+	defer os.RemoveAll(localA)
+	defer os.RemoveAll(remote)
+
+	localB, err := os.MkdirTemp("", "gitwapp-local-b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(localB)
+	if _, err := git.PlainClone(localB, false, &git.CloneOptions{URL: remote}); err != nil {
+		t.Fatal(err)
+	}
+	setIdentity(t, localB)
+
+	// Both sides commit; B rebases its commit onto A's instead of merging
+	remoteHash := commitFile(t, localA, "a.txt", "a", "A work", "alice")
+	rA, _ := git.PlainOpen(localA)
+	if err := rA.Push(&git.PushOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, localB, "b.txt", "b", "B work", "bob")
+
+	if err := Pull(localB, PullOptions{Rebase: true}); err != nil {
+		t.Fatalf("Pull with rebase failed: %v", err)
+	}
+	detail, err := GetCommit(localB, "HEAD", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if detail.Summary != "B work" || len(detail.Parents) != 1 || detail.Parents[0] != remoteHash {
+		t.Errorf("Expected B work rebased onto %s, got %+v", remoteHash, detail)
+	}
+
+	// Conflicting upstream changes stop the rebase
+	commitFile(t, localA, "README.md", "from A", "A edit", "alice")
+	rA.Push(&git.PushOptions{})
+	commitFile(t, localB, "README.md", "from B", "B edit", "bob")
+
+	if err := Pull(localB, PullOptions{Rebase: true}); !errors.Is(err, ErrRebaseConflict) {
+		t.Fatalf("Expected ErrRebaseConflict, got %v", err)
+	}
+	status, _ := GetStatus(localB)
+	if status.State != StateRebasing {
+		t.Errorf("Expected rebasing state, got %q", status.State)
+	}
+}
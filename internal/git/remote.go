@@ -0,0 +1,142 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+var (
+	// ErrRemoteNotFound is returned when a named remote does not exist.
+	ErrRemoteNotFound = errors.New("remote not found")
+	// ErrRemoteExists is returned when adding a remote whose name is taken.
+	ErrRemoteExists = errors.New("remote already exists")
+	// ErrInvalidRemote is returned for an empty or malformed remote name or URL.
+	ErrInvalidRemote = errors.New("invalid remote")
+)
+
+// Remote describes a configured remote.
+type Remote struct {
+	Name string   `json:"name"`
+	URLs []string `json:"urls"`
+}
+
+// ListRemotes returns the configured remotes sorted by name.
+func ListRemotes(path string) ([]Remote, error) {
+	r, err := git.PlainOpen(path)
+	if err != nil {
+		return nil, err
+	}
+
+	remotes, err := r.Remotes()
+	if err != nil {
+		return nil, err
+	}
+
+	result := []Remote{}
+	for _, remote := range remotes {
+		cfg := remote.Config()
+		result = append(result, Remote{Name: cfg.Name, URLs: cfg.URLs})
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+// AddRemote adds a new remote with the default fetch refspec.
+func AddRemote(path string, name string, url string) error {
+	r, err := git.PlainOpen(path)
+	if err != nil {
+		return err
+	}
+
+	if name == "" || url == "" {
+		return fmt.Errorf("%w: name and URL are required", ErrInvalidRemote)
+	}
+	if _, err := r.Remote(name); err == nil {
+		return fmt.Errorf("%w: %s", ErrRemoteExists, name)
+	}
+
+	_, err = r.CreateRemote(&config.RemoteConfig{
+		Name: name,
+		URLs: []string{url},
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidRemote, err)
+	}
+	return nil
+}
+
+// SetRemoteURL replaces the URL of an existing remote.
+func SetRemoteURL(path string, name string, url string) error {
+	r, err := git.PlainOpen(path)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := r.Config()
+	if err != nil {
+		return err
+	}
+
+	remote, ok := cfg.Remotes[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrRemoteNotFound, name)
+	}
+	if url == "" {
+		return fmt.Errorf("%w: URL is required", ErrInvalidRemote)
+	}
+	remote.URLs = []string{url}
+
+	return r.SetConfig(cfg)
+}
+
+// RemoveRemote deletes a remote. It uses the git command-line tool so that
+// remote-tracking refs and branch upstream settings are cleaned up as well.
+func RemoveRemote(path string, name string) error {
+	r, err := git.PlainOpen(path)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.Remote(name); err != nil {
+		return fmt.Errorf("%w: %s", ErrRemoteNotFound, name)
+	}
+
+	_, err = runGit(path, "remote", "remove", name)
+	return err
+}
+
+// upstreamFor returns the remote name and remote branch reference that the
+// given local branch tracks. When the branch has no upstream configured it
+// falls back to the branch of the same name on the default remote.
+func upstreamFor(r *git.Repository, branch string) (string, plumbing.ReferenceName, error) {
+	cfg, err := r.Config()
+	if err != nil {
+		return "", "", err
+	}
+
+	if bc, ok := cfg.Branches[branch]; ok && bc.Remote != "" && bc.Remote != "." && bc.Merge != "" {
+		return bc.Remote, bc.Merge, nil
+	}
+	return git.DefaultRemoteName, plumbing.NewBranchReferenceName(branch), nil
+}
+
+// trackingRef returns the local remote-tracking reference of a branch's
+// configured upstream, or false when no upstream is configured.
+func trackingRef(r *git.Repository, branch string) (plumbing.ReferenceName, bool) {
+	cfg, err := r.Config()
+	if err != nil {
+		return "", false
+	}
+
+	bc, ok := cfg.Branches[branch]
+	if !ok || bc.Remote == "" || bc.Merge == "" {
+		return "", false
+	}
+	return upstreamRefName(bc.Remote, bc.Merge), true
+}
@@ -0,0 +1,90 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func TestRemoteManagement(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	defer os.RemoveAll(repoPath)
+
+	if err := AddRemote(repoPath, "upstream", "https://example.com/upstream.git"); err != nil {
+		t.Fatalf("AddRemote failed: %v", err)
+	}
+	if err := AddRemote(repoPath, "upstream", "https://example.com/other.git"); !errors.Is(err, ErrRemoteExists) {
+		t.Errorf("Expected ErrRemoteExists, got %v", err)
+	}
+	if err := AddRemote(repoPath, "fork", ""); !errors.Is(err, ErrInvalidRemote) {
+		t.Errorf("Expected ErrInvalidRemote, got %v", err)
+	}
+
+	if err := SetRemoteURL(repoPath, "upstream", "https://example.com/moved.git"); err != nil {
+		t.Fatalf("SetRemoteURL failed: %v", err)
+	}
+	remotes, err := ListRemotes(repoPath)
+	if err != nil {
+		t.Fatalf("ListRemotes failed: %v", err)
+	}
+	if len(remotes) != 1 || remotes[0].URLs[0] != "https://example.com/moved.git" {
+		t.Errorf("Unexpected remotes: %+v", remotes)
+	}
+
+	if err := RemoveRemote(repoPath, "upstream"); err != nil {
+		t.Fatalf("RemoveRemote failed: %v", err)
+	}
+	if err := RemoveRemote(repoPath, "upstream"); !errors.Is(err, ErrRemoteNotFound) {
+		t.Errorf("Expected ErrRemoteNotFound, got %v", err)
+	}
+}
+
+func TestPushToNamedRemote(t *testing.T) {
+	local, remote := setupTestRepoWithRemote(t)
+	defer os.RemoveAll(local)
+	defer os.RemoveAll(remote)
+
+	forkDir, err := os.MkdirTemp("", "gitwapp-fork-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(forkDir)
+	if _, err := git.PlainInit(forkDir, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddRemote(local, "fork", forkDir); err != nil {
+		t.Fatalf("AddRemote failed: %v", err)
+	}
+
+	hash := commitFile(t, local, "fork.txt", "fork", "Fork commit", "alice")
+
+	if err := Push(local, PushOptions{Remote: "fork"}); err != nil {
+		t.Fatalf("Push to fork failed: %v", err)
+	}
+	rFork, _ := git.PlainOpen(forkDir)
+	ref, err := rFork.Reference(plumbing.NewBranchReferenceName("master"), true)
+	if err != nil || ref.Hash().String() != hash {
+		t.Errorf("Expected fork master at %s, got %v (err %v)", hash, ref, err)
+	}
+
+	// The upstream (origin) has not received the commit
+	status, err := GetStatus(local)
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if status.Upstream != "origin/master" || status.Ahead != 1 {
+		t.Errorf("Expected 1 ahead of origin/master, got %+v", status)
+	}
+
+	if err := Push(local, PushOptions{Remote: "missing"}); !errors.Is(err, ErrRemoteNotFound) {
+		t.Errorf("Expected ErrRemoteNotFound, got %v", err)
+	}
+
+	// Pushing again when up to date is not an error
+	if err := Push(local, PushOptions{Remote: "fork"}); err != nil {
+		t.Errorf("Expected up-to-date push to succeed, got %v", err)
+	}
+}
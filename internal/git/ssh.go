@@ -0,0 +1,152 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// ErrPassphraseRequired is returned when the only usable SSH key is
+// encrypted and no passphrase was supplied.
+var ErrPassphraseRequired = errors.New("SSH key passphrase required")
+
+// SSHConfig configures how SSH remotes are authenticated and verified.
+type SSHConfig struct {
+	KeyPaths       []string // Private key files tried in order; ~/.ssh/id_{ed25519,rsa,ecdsa} when empty
+	KnownHostsPath string   // known_hosts file used for host verification; ~/.ssh/known_hosts when empty
+}
+
+var (
+	sshConfigMu sync.RWMutex
+	sshConfig   SSHConfig
+)
+
+// ConfigureSSH sets the SSH configuration used by all remote operations.
+func ConfigureSSH(cfg SSHConfig) {
+	sshConfigMu.Lock()
+	defer sshConfigMu.Unlock()
+	sshConfig = cfg
+}
+
+// sshKeyPaths returns the configured private key paths or the defaults.
+func sshKeyPaths() []string {
+	sshConfigMu.RLock()
+	defer sshConfigMu.RUnlock()
+
+	if len(sshConfig.KeyPaths) > 0 {
+		return sshConfig.KeyPaths
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	return []string{
+		filepath.Join(homeDir, ".ssh", "id_ed25519"),
+		filepath.Join(homeDir, ".ssh", "id_rsa"),
+		filepath.Join(homeDir, ".ssh", "id_ecdsa"),
+	}
+}
+
+// knownHostsPath returns the configured known_hosts path or the default.
+func knownHostsPath() (string, error) {
+	sshConfigMu.RLock()
+	defer sshConfigMu.RUnlock()
+
+	if sshConfig.KnownHostsPath != "" {
+		return sshConfig.KnownHostsPath, nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".ssh", "known_hosts"), nil
+}
+
+// getSSHAuth returns SSH authentication for an endpoint. An explicit key in
+// creds is used as is; otherwise the ssh-agent is tried first, then the
+// configured key files, decrypting them with creds.Passphrase if given.
+// The server's host key is always checked against known_hosts.
+func getSSHAuth(ep *transport.Endpoint, creds *Credentials) (transport.AuthMethod, error) {
+	user := ep.User
+	if user == "" {
+		user = "git"
+	}
+
+	port := ep.Port
+	if port == 0 {
+		port = 22
+	}
+	callback, algorithms, err := knownHostsCallback(net.JoinHostPort(ep.Host, strconv.Itoa(port)))
+	if err != nil {
+		return nil, err
+	}
+	helper := ssh.HostKeyCallbackHelper{HostKeyCallback: callback, HostKeyAlgorithms: algorithms}
+
+	passphrase := ""
+	if creds != nil {
+		passphrase = creds.Passphrase
+		if len(creds.SSHKey) > 0 {
+			auth, err := newPublicKeys(user, creds.SSHKey, creds.Passphrase)
+			if err != nil {
+				return nil, fmt.Errorf("invalid SSH key: %w", err)
+			}
+			auth.HostKeyCallbackHelper = helper
+			return auth, nil
+		}
+	}
+
+	// Try ssh-agent first (most common and secure), unless a passphrase
+	// was supplied for one of the key files
+	if passphrase == "" {
+		if auth, err := ssh.NewSSHAgentAuth(user); err == nil {
+			auth.HostKeyCallbackHelper = helper
+			return auth, nil
+		}
+	}
+
+	var keyErr error
+	for _, keyPath := range sshKeyPaths() {
+		data, err := os.ReadFile(keyPath)
+		if err != nil {
+			continue
+		}
+
+		auth, err := newPublicKeys(user, data, passphrase)
+		if err == nil {
+			auth.HostKeyCallbackHelper = helper
+			return auth, nil
+		}
+
+		// Remember why an existing key was unusable, in case none works
+		if keyErr == nil {
+			keyErr = fmt.Errorf("%s: %w", keyPath, err)
+		}
+	}
+
+	if keyErr != nil {
+		return nil, keyErr
+	}
+	return nil, fmt.Errorf("no SSH authentication method available")
+}
+
+// newPublicKeys parses a PEM-encoded private key into an auth method. An
+// encrypted key without a passphrase yields ErrPassphraseRequired.
+func newPublicKeys(user string, pemBytes []byte, passphrase string) (*ssh.PublicKeys, error) {
+	if passphrase == "" {
+		var missing *gossh.PassphraseMissingError
+		if _, err := gossh.ParsePrivateKey(pemBytes); errors.As(err, &missing) {
+			return nil, ErrPassphraseRequired
+		}
+	}
+	return ssh.NewPublicKeys(user, pemBytes, passphrase)
+}
@@ -0,0 +1,168 @@
+package git
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// writeSSHKey writes a new ed25519 private key to path, encrypted with
+// passphrase if it is not empty.
+func writeSSHKey(t *testing.T, path string, passphrase string) gossh.Signer {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var block *pem.Block
+	if passphrase != "" {
+		block, err = gossh.MarshalPrivateKeyWithPassphrase(priv, "", []byte(passphrase))
+	} else {
+		block, err = gossh.MarshalPrivateKey(priv, "")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatal(err)
+	}
+	signer, err := gossh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer
+}
+
+// startSSHServer starts an SSH server that completes the handshake for any
+// client key and then refuses all sessions. It returns the server address.
+func startSSHServer(t *testing.T, hostKey gossh.Signer) string {
+	cfg := &gossh.ServerConfig{
+		PublicKeyCallback: func(gossh.ConnMetadata, gossh.PublicKey) (*gossh.Permissions, error) {
+			return nil, nil
+		},
+	}
+	cfg.AddHostKey(hostKey)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, chans, reqs, err := gossh.NewServerConn(conn, cfg)
+				if err != nil {
+					return
+				}
+				go gossh.DiscardRequests(reqs)
+				for ch := range chans {
+					ch.Reject(gossh.Prohibited, "no sessions")
+				}
+			}()
+		}
+	}()
+
+	return ln.Addr().String()
+}
+
+func TestGetSSHAuthPassphrase(t *testing.T) {
+	t.Setenv("SSH_AUTH_SOCK", "")
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, "id_ed25519")
+	writeSSHKey(t, keyPath, "open sesame")
+
+	ConfigureSSH(SSHConfig{KeyPaths: []string{keyPath}, KnownHostsPath: filepath.Join(dir, "known_hosts")})
+	defer ConfigureSSH(SSHConfig{})
+
+	ep, err := transport.NewEndpoint("git@example.com:org/repo.git")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := getSSHAuth(ep, nil); !errors.Is(err, ErrPassphraseRequired) {
+		t.Errorf("Expected ErrPassphraseRequired, got %v", err)
+	}
+	if _, err := getSSHAuth(ep, &Credentials{Passphrase: "wrong"}); err == nil {
+		t.Error("Expected error for wrong passphrase")
+	}
+	if _, err := getSSHAuth(ep, &Credentials{Passphrase: "open sesame"}); err != nil {
+		t.Errorf("getSSHAuth with passphrase failed: %v", err)
+	}
+}
+
+func TestKnownHostsVerification(t *testing.T) {
+	t.Setenv("SSH_AUTH_SOCK", "")
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, "id_ed25519")
+	writeSSHKey(t, keyPath, "")
+	hostKey := writeSSHKey(t, filepath.Join(dir, "host_key"), "")
+	knownHosts := filepath.Join(dir, "known_hosts")
+
+	ConfigureSSH(SSHConfig{KeyPaths: []string{keyPath}, KnownHostsPath: knownHosts})
+	defer ConfigureSSH(SSHConfig{})
+
+	addr := startSSHServer(t, hostKey)
+	repoPath := setupTestRepo(t)
+	defer os.RemoveAll(repoPath)
+	if err := AddRemote(repoPath, "origin", "ssh://git@"+addr+"/repo.git"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Unknown hosts are refused and their key is kept pending
+	err := Push(repoPath, PushOptions{})
+	var hostErr *HostKeyError
+	if !errors.Is(err, ErrUnknownHostKey) || !errors.As(err, &hostErr) {
+		t.Fatalf("Expected unknown host key error, got %v", err)
+	}
+	fingerprint := gossh.FingerprintSHA256(hostKey.PublicKey())
+	if hostErr.Fingerprint != fingerprint {
+		t.Errorf("Expected fingerprint %s, got %s", fingerprint, hostErr.Fingerprint)
+	}
+
+	pending := PendingHostKeys()
+	if len(pending) != 1 || pending[0].Fingerprint != fingerprint {
+		t.Fatalf("Unexpected pending host keys: %+v", pending)
+	}
+
+	if err := AcceptHostKey(pending[0].Host, "SHA256:bogus"); !errors.Is(err, ErrFingerprintMismatch) {
+		t.Errorf("Expected ErrFingerprintMismatch, got %v", err)
+	}
+	if err := AcceptHostKey(pending[0].Host, fingerprint); err != nil {
+		t.Fatalf("AcceptHostKey failed: %v", err)
+	}
+	if len(PendingHostKeys()) != 0 {
+		t.Error("Accepted key still pending")
+	}
+
+	known, err := ListKnownHosts()
+	if err != nil || len(known) != 1 || known[0].Fingerprint != fingerprint {
+		t.Fatalf("Unexpected known hosts: %+v (err %v)", known, err)
+	}
+
+	// The host is now trusted; the push gets past verification
+	err = Push(repoPath, PushOptions{})
+	if err == nil || errors.Is(err, ErrUnknownHostKey) || errors.Is(err, ErrHostKeyMismatch) {
+		t.Errorf("Expected a non host key error after accepting, got %v", err)
+	}
+
+	// A different key for the same host is a mismatch
+	otherKey := writeSSHKey(t, filepath.Join(dir, "other_host_key"), "")
+	os.WriteFile(knownHosts, []byte(known[0].Host+" "+string(gossh.MarshalAuthorizedKey(otherKey.PublicKey()))), 0600)
+	if err := Push(repoPath, PushOptions{}); !errors.Is(err, ErrHostKeyMismatch) {
+		t.Errorf("Expected ErrHostKeyMismatch, got %v", err)
+	}
+}
@@ -0,0 +1,250 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+var (
+	// ErrStashNotFound is returned when a stash index does not exist.
+	ErrStashNotFound = errors.New("stash not found")
+	// ErrNothingToStash is returned when saving a stash without local changes.
+	ErrNothingToStash = errors.New("no local changes to stash")
+)
+
+// Stash is an entry of the stash list. Index 0 is the most recent stash.
+type Stash struct {
+	Index   int       `json:"index"`
+	Hash    string    `json:"hash"`
+	Branch  string    `json:"branch"` // Branch the stash was saved on
+	Message string    `json:"message"`
+	Date    time.Time `json:"date"`
+}
+
+// StashDetail is a stash together with the changes it holds.
+type StashDetail struct {
+	Stash
+	Files []FileChange `json:"files"`
+}
+
+// StashOptions configures SaveStash.
+type StashOptions struct {
+	Message          string // Optional description; git's "WIP on <branch>" when empty
+	IncludeUntracked bool   // Also stash untracked files
+	KeepIndex        bool   // Leave staged changes in place
+}
+
+// StashApplyResult reports the outcome of applying a stash.
+type StashApplyResult struct {
+	Dropped   bool       `json:"dropped"` // Whether the stash was removed from the list
+	Conflicts []Conflict `json:"conflicts,omitempty"`
+}
+
+// ListStashes returns the stash list, most recent first. Stashes are read
+// with the git command-line tool as go-git does not support reflogs.
+func ListStashes(path string) ([]Stash, error) {
+	if _, err := git.PlainOpen(path); err != nil {
+		return nil, err
+	}
+
+	output, err := runGit(path, "stash", "list", "-z", "--format=%H%x1f%ct%x1f%gs")
+	if err != nil {
+		return nil, err
+	}
+
+	stashes := []Stash{}
+	for _, entry := range strings.Split(output, "\x00") {
+		fields := strings.SplitN(entry, "\x1f", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		secs, _ := strconv.ParseInt(fields[1], 10, 64)
+		branch, message := parseStashSubject(fields[2])
+		stashes = append(stashes, Stash{
+			Index:   len(stashes),
+			Hash:    fields[0],
+			Branch:  branch,
+			Message: message,
+			Date:    time.Unix(secs, 0),
+		})
+	}
+	return stashes, nil
+}
+
+// SaveStash stashes the local changes and returns the new stash.
+func SaveStash(path string, opts StashOptions) (*Stash, error) {
+	if _, err := git.PlainOpen(path); err != nil {
+		return nil, err
+	}
+
+	args := []string{"stash", "push"}
+	if opts.IncludeUntracked {
+		args = append(args, "--include-untracked")
+	}
+	if opts.KeepIndex {
+		args = append(args, "--keep-index")
+	}
+	if opts.Message != "" {
+		args = append(args, "--message", opts.Message)
+	}
+
+	output, err := runGit(path, args...)
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(output, "No local changes to save") {
+		return nil, ErrNothingToStash
+	}
+
+	return findStash(path, 0)
+}
+
+// GetStash returns a stash with its changes relative to the commit it was
+// saved on. Stashed untracked files are reported as added.
+func GetStash(path string, index int) (*StashDetail, error) {
+	stash, err := findStash(path, index)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := git.PlainOpen(path)
+	if err != nil {
+		return nil, err
+	}
+	c, err := r.CommitObject(plumbing.NewHash(stash.Hash))
+	if err != nil {
+		return nil, err
+	}
+
+	// The stash commit's first parent is HEAD when saved; its tree holds the
+	// working tree changes. A third parent holds the untracked files.
+	base, err := c.Parent(0)
+	if err != nil {
+		return nil, err
+	}
+	detail := &StashDetail{Stash: *stash}
+	if detail.Files, err = treeChanges(base, c); err != nil {
+		return nil, err
+	}
+
+	if c.NumParents() > 2 {
+		untracked, err := c.Parent(2)
+		if err != nil {
+			return nil, err
+		}
+		files, err := treeChanges(nil, untracked)
+		if err != nil {
+			return nil, err
+		}
+		detail.Files = append(detail.Files, files...)
+	}
+	return detail, nil
+}
+
+// ApplyStash applies a stash to the working tree, removing it from the list
+// when pop is true. A stash that conflicts with the current commit leaves
+// conflicted files behind and is kept even when popped.
+func ApplyStash(path string, index int, pop bool) (*StashApplyResult, error) {
+	if _, err := findStash(path, index); err != nil {
+		return nil, err
+	}
+	if conflicts, err := Conflicts(path); err != nil {
+		return nil, err
+	} else if len(conflicts) > 0 {
+		return nil, fmt.Errorf("%w: %d file(s)", ErrUnresolvedConflicts, len(conflicts))
+	}
+
+	action := "apply"
+	if pop {
+		action = "pop"
+	}
+	_, applyErr := runGit(path, "stash", action, stashRef(index))
+
+	conflicts, err := Conflicts(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(conflicts) > 0 {
+		return &StashApplyResult{Conflicts: conflicts}, nil
+	}
+	if applyErr != nil {
+		if strings.Contains(applyErr.Error(), "would be overwritten") {
+			return nil, fmt.Errorf("%w: %v", ErrLocalChanges, applyErr)
+		}
+		return nil, applyErr
+	}
+	return &StashApplyResult{Dropped: pop}, nil
+}
+
+// DropStash removes a stash from the list.
+func DropStash(path string, index int) error {
+	if _, err := findStash(path, index); err != nil {
+		return err
+	}
+	_, err := runGit(path, "stash", "drop", stashRef(index))
+	return err
+}
+
+// findStash returns the stash with the given index.
+func findStash(path string, index int) (*Stash, error) {
+	stashes, err := ListStashes(path)
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || index >= len(stashes) {
+		return nil, fmt.Errorf("%w: %s", ErrStashNotFound, stashRef(index))
+	}
+	return &stashes[index], nil
+}
+
+// stashRef returns the revision naming a stash index.
+func stashRef(index int) string {
+	return fmt.Sprintf("stash@{%d}", index)
+}
+
+// parseStashSubject splits a stash reflog subject such as "WIP on main:
+// abc1234 Fix" or "On main: message" into the branch and the message.
+func parseStashSubject(subject string) (string, string) {
+	rest, ok := strings.CutPrefix(subject, "WIP on ")
+	if !ok {
+		rest, ok = strings.CutPrefix(subject, "On ")
+	}
+	if !ok {
+		return "", subject
+	}
+	branch, message, ok := strings.Cut(rest, ": ")
+	if !ok {
+		return "", subject
+	}
+	return branch, message
+}
+
+// treeChanges returns the file changes between the trees of two commits. A
+// nil from commit compares against an empty tree.
+func treeChanges(from, to *object.Commit) ([]FileChange, error) {
+	var fromTree *object.Tree
+	if from != nil {
+		t, err := from.Tree()
+		if err != nil {
+			return nil, err
+		}
+		fromTree = t
+	}
+	toTree, err := to.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	patch, err := fromTree.Patch(toTree)
+	if err != nil {
+		return nil, err
+	}
+	return fileChangesFromPatch(patch, true)
+}
@@ -0,0 +1,129 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+)
+
+func TestStash(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	defer os.RemoveAll(repoPath)
+	setIdentity(t, repoPath)
+
+	if _, err := SaveStash(repoPath, StashOptions{}); !errors.Is(err, ErrNothingToStash) {
+		t.Errorf("Expected ErrNothingToStash, got %v", err)
+	}
+
+	// Save a modification and an untracked file
+	os.WriteFile(filepath.Join(repoPath, "README.md"), []byte("Hello, stash"), 0644)
+	os.WriteFile(filepath.Join(repoPath, "new.txt"), []byte("new"), 0644)
+
+	stash, err := SaveStash(repoPath, StashOptions{Message: "parked", IncludeUntracked: true})
+	if err != nil {
+		t.Fatalf("SaveStash failed: %v", err)
+	}
+	if stash.Index != 0 || stash.Branch != "master" || stash.Message != "parked" {
+		t.Errorf("Unexpected stash: %+v", stash)
+	}
+	if status, _ := GetStatus(repoPath); !status.Clean {
+		t.Errorf("Expected clean working tree after stash, got %+v", status.Worktree)
+	}
+
+	detail, err := GetStash(repoPath, 0)
+	if err != nil {
+		t.Fatalf("GetStash failed: %v", err)
+	}
+	changes := map[string]string{}
+	for _, f := range detail.Files {
+		changes[f.Path] = f.Status
+	}
+	if changes["README.md"] != ChangeModified || changes["new.txt"] != ChangeAdded || len(changes) != 2 {
+		t.Errorf("Unexpected stash changes: %+v", detail.Files)
+	}
+
+	// A second stash without a message is described by git
+	os.WriteFile(filepath.Join(repoPath, "README.md"), []byte("Second"), 0644)
+	if _, err := SaveStash(repoPath, StashOptions{}); err != nil {
+		t.Fatalf("SaveStash failed: %v", err)
+	}
+	stashes, err := ListStashes(repoPath)
+	if err != nil {
+		t.Fatalf("ListStashes failed: %v", err)
+	}
+	if len(stashes) != 2 || stashes[1].Message != "parked" || stashes[0].Index != 0 {
+		t.Fatalf("Unexpected stash list: %+v", stashes)
+	}
+
+	if err := DropStash(repoPath, 0); err != nil {
+		t.Fatalf("DropStash failed: %v", err)
+	}
+
+	// Apply keeps the stash, pop removes it
+	result, err := ApplyStash(repoPath, 0, false)
+	if err != nil || result.Dropped || len(result.Conflicts) != 0 {
+		t.Fatalf("ApplyStash failed: %+v (err %v)", result, err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(repoPath, "new.txt")); string(data) != "new" {
+		t.Errorf("Expected untracked file to be restored, got %q", data)
+	}
+	runGit(repoPath, "checkout", "--", "README.md")
+	os.Remove(filepath.Join(repoPath, "new.txt"))
+
+	result, err = ApplyStash(repoPath, 0, true)
+	if err != nil || !result.Dropped {
+		t.Fatalf("Pop failed: %+v (err %v)", result, err)
+	}
+	if stashes, _ := ListStashes(repoPath); len(stashes) != 0 {
+		t.Errorf("Expected empty stash list after pop, got %+v", stashes)
+	}
+
+	if _, err := GetStash(repoPath, 0); !errors.Is(err, ErrStashNotFound) {
+		t.Errorf("Expected ErrStashNotFound, got %v", err)
+	}
+	if err := DropStash(repoPath, 3); !errors.Is(err, ErrStashNotFound) {
+		t.Errorf("Expected ErrStashNotFound, got %v", err)
+	}
+}
+
+func TestStashKeepIndexAndConflict(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	defer os.RemoveAll(repoPath)
+	setIdentity(t, repoPath)
+
+	os.WriteFile(filepath.Join(repoPath, "staged.txt"), []byte("staged"), 0644)
+	runGit(repoPath, "add", "staged.txt")
+	os.WriteFile(filepath.Join(repoPath, "README.md"), []byte("stashed"), 0644)
+
+	if _, err := SaveStash(repoPath, StashOptions{KeepIndex: true}); err != nil {
+		t.Fatalf("SaveStash failed: %v", err)
+	}
+	status, _ := GetStatus(repoPath)
+	if s := status.Worktree.File("staged.txt"); s.Staging != git.Added {
+		t.Errorf("Expected staged file to be kept, got %+v", status.Worktree)
+	}
+	if _, changed := status.Worktree["README.md"]; changed {
+		t.Errorf("Expected README.md change to be stashed, got %+v", status.Worktree)
+	}
+
+	// The stashed change conflicts with a new commit
+	runGit(repoPath, "reset", "--hard")
+	commitFile(t, repoPath, "README.md", "committed", "Change README", "alice")
+
+	result, err := ApplyStash(repoPath, 0, true)
+	if err != nil {
+		t.Fatalf("ApplyStash failed: %v", err)
+	}
+	if result.Dropped || len(result.Conflicts) != 1 || result.Conflicts[0].Path != "README.md" {
+		t.Errorf("Expected kept stash with conflict on README.md, got %+v", result)
+	}
+	if stashes, _ := ListStashes(repoPath); len(stashes) != 1 {
+		t.Errorf("Expected conflicting stash to be kept, got %+v", stashes)
+	}
+	if _, err := ApplyStash(repoPath, 0, false); !errors.Is(err, ErrUnresolvedConflicts) {
+		t.Errorf("Expected ErrUnresolvedConflicts, got %v", err)
+	}
+}
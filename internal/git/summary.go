@@ -0,0 +1,134 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// Summary is a compact overview of a repository's state, as shown on the
+// dashboard.
+type Summary struct {
+	Branch     string      `json:"branch"`
+	Clean      bool        `json:"clean"`
+	Upstream   string      `json:"upstream,omitempty"`
+	Ahead      int         `json:"ahead"`
+	Behind     int         `json:"behind"`
+	Staged     int         `json:"staged"`
+	Unstaged   int         `json:"unstaged"`
+	Untracked  int         `json:"untracked"`
+	LastCommit *CommitInfo `json:"last_commit,omitempty"` // Nil before the first commit
+}
+
+// GetSummary returns the branch, tracking state, change counts and last
+// commit of the repository at the given path.
+func GetSummary(path string) (*Summary, error) {
+	return GetSummaryContext(context.Background(), path)
+}
+
+// GetSummaryContext is like GetSummary but stops git status when ctx is
+// done, so a slow worktree cannot hold up the caller.
+func GetSummaryContext(ctx context.Context, path string) (*Summary, error) {
+	r, err := git.PlainOpen(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Optional locks are skipped so that polling never competes with
+	// other git commands for the index lock
+	output, err := runGitContext(ctx, path, "", "--no-optional-locks", "status",
+		"--porcelain=v2", "--branch", "--untracked-files=all", "-z")
+	if err != nil {
+		return nil, err
+	}
+	summary, err := parseStatusSummary(output)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	head, err := r.Head()
+	if err != nil {
+		// A repository without commits has no last commit
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			return summary, nil
+		}
+		return nil, err
+	}
+	commit, err := r.CommitObject(head.Hash())
+	if err != nil {
+		return nil, err
+	}
+	info := newCommitInfo(commit)
+	summary.LastCommit = &info
+
+	return summary, nil
+}
+
+// parseStatusSummary reads the branch headers and counts the entries of
+// NUL-separated git status --porcelain=v2 --branch output.
+func parseStatusSummary(output string) (*Summary, error) {
+	summary := &Summary{}
+	entries := strings.Split(output, "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if entry == "" {
+			continue
+		}
+
+		switch entry[0] {
+		case '#':
+			fields := strings.Fields(entry)
+			if len(fields) < 3 {
+				continue
+			}
+			switch fields[1] {
+			case "branch.head":
+				summary.Branch = fields[2]
+				if summary.Branch == "(detached)" {
+					summary.Branch = "HEAD"
+				}
+			case "branch.upstream":
+				summary.Upstream = fields[2]
+			case "branch.ab":
+				if len(fields) < 4 {
+					return nil, fmt.Errorf("unexpected status header: %q", entry)
+				}
+				var err error
+				if summary.Ahead, err = strconv.Atoi(strings.TrimPrefix(fields[2], "+")); err != nil {
+					return nil, err
+				}
+				if summary.Behind, err = strconv.Atoi(strings.TrimPrefix(fields[3], "-")); err != nil {
+					return nil, err
+				}
+			}
+		case '1', '2', 'u':
+			// "<type> <XY> ...", where X is the index and Y the worktree
+			// status and "." means unmodified
+			if len(entry) < 4 {
+				return nil, fmt.Errorf("unexpected status entry: %q", entry)
+			}
+			if entry[2] != '.' {
+				summary.Staged++
+			}
+			if entry[3] != '.' {
+				summary.Unstaged++
+			}
+			if entry[0] == '2' {
+				// Renames and copies are followed by the original path
+				i++
+			}
+		case '?':
+			summary.Untracked++
+		}
+	}
+	summary.Clean = summary.Staged == 0 && summary.Unstaged == 0 && summary.Untracked == 0
+	return summary, nil
+}
@@ -0,0 +1,89 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetSummary(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	defer os.RemoveAll(repoPath)
+
+	commitFile(t, repoPath, "a.txt", "a", "Add a\n\nWith a body", "alice")
+	commitFile(t, repoPath, "b.txt", "b", "Add b", "alice")
+
+	// One staged, one unstaged and one untracked change
+	os.WriteFile(filepath.Join(repoPath, "a.txt"), []byte("a2"), 0644)
+	StageFile(repoPath, "a.txt")
+	os.WriteFile(filepath.Join(repoPath, "b.txt"), []byte("b2"), 0644)
+	os.WriteFile(filepath.Join(repoPath, "new.txt"), []byte("new"), 0644)
+
+	summary, err := GetSummary(repoPath)
+	if err != nil {
+		t.Fatalf("GetSummary failed: %v", err)
+	}
+	if summary.Clean || summary.Staged != 1 || summary.Unstaged != 1 || summary.Untracked != 1 {
+		t.Errorf("Unexpected counts: %+v", summary)
+	}
+	if summary.Branch != "master" {
+		t.Errorf("Expected branch master, got %s", summary.Branch)
+	}
+	if summary.LastCommit == nil || summary.LastCommit.Summary != "Add b" {
+		t.Errorf("Unexpected last commit: %+v", summary.LastCommit)
+	}
+
+	if _, err := GetSummary(filepath.Join(repoPath, "missing")); err == nil {
+		t.Error("Expected error for missing repository")
+	}
+}
+
+func TestGetSummaryEmptyRepo(t *testing.T) {
+	repoPath := t.TempDir()
+	if _, err := runGit(repoPath, "init", "-b", "main"); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(repoPath, "new.txt"), []byte("new"), 0644)
+
+	summary, err := GetSummary(repoPath)
+	if err != nil {
+		t.Fatalf("GetSummary failed: %v", err)
+	}
+	if summary.Branch != "main" || summary.Untracked != 1 || summary.Clean {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+	if summary.LastCommit != nil {
+		t.Errorf("Expected no last commit, got %+v", summary.LastCommit)
+	}
+}
+
+func TestGetSummaryContextCanceled(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	defer os.RemoveAll(repoPath)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := GetSummaryContext(ctx, repoPath); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestParseStatusSummary(t *testing.T) {
+	output := "# branch.oid abc\x00# branch.head (detached)\x00# branch.upstream origin/main\x00# branch.ab +2 -3\x00" +
+		"1 M. N... 100644 100644 100644 a a a.txt\x00" +
+		"2 R. N... 100644 100644 100644 a a R100 new.txt\x00old.txt\x00" +
+		"u UU N... 100644 100644 100644 100644 a a a c.txt\x00" +
+		"? d.txt\x00"
+
+	summary, err := parseStatusSummary(output)
+	if err != nil {
+		t.Fatalf("parseStatusSummary failed: %v", err)
+	}
+	want := Summary{Branch: "HEAD", Upstream: "origin/main", Ahead: 2, Behind: 3, Staged: 3, Unstaged: 1, Untracked: 1}
+	if *summary != want {
+		t.Errorf("Expected %+v, got %+v", want, *summary)
+	}
+}
@@ -0,0 +1,209 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+var (
+	// ErrTagNotFound is returned when a tag does not exist.
+	ErrTagNotFound = errors.New("tag not found")
+	// ErrTagExists is returned when creating a tag that already exists.
+	ErrTagExists = errors.New("tag already exists")
+	// ErrInvalidTagName is returned for names git would reject.
+	ErrInvalidTagName = errors.New("invalid tag name")
+)
+
+// Tag describes a lightweight or annotated tag.
+type Tag struct {
+	Name        string    `json:"name"`
+	Hash        string    `json:"hash"` // Tagged commit
+	Summary     string    `json:"summary"`
+	Annotated   bool      `json:"annotated"`
+	Signed      bool      `json:"signed"`
+	Message     string    `json:"message,omitempty"`
+	Tagger      string    `json:"tagger,omitempty"`
+	TaggerEmail string    `json:"tagger_email,omitempty"`
+	Date        time.Time `json:"date"` // Tagging date, or the commit date of lightweight tags
+}
+
+// TagOptions configures CreateTag.
+type TagOptions struct {
+	Name    string
+	Target  string // Branch, tag or commit to tag (default HEAD)
+	Message string // Creates an annotated tag when set
+	Sign    bool   // Creates a GPG-signed annotated tag with the configured signing key
+}
+
+// ListTags returns all tags sorted by name.
+func ListTags(path string) ([]Tag, error) {
+	r, err := git.PlainOpen(path)
+	if err != nil {
+		return nil, err
+	}
+
+	iter, err := r.Tags()
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	tags := []Tag{}
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		tags = append(tags, newTag(r, ref))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	return tags, nil
+}
+
+// CreateTag creates a tag on a commit. Tags without a message are
+// lightweight. It uses the git command-line tool so that annotated tags
+// pick up the configured tagger identity and signing key.
+func CreateTag(path string, opts TagOptions) (*Tag, error) {
+	r, err := git.PlainOpen(path)
+	if err != nil {
+		return nil, err
+	}
+
+	refName, err := tagRefName(opts.Name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.Reference(refName, false); err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrTagExists, opts.Name)
+	}
+
+	hash, err := resolveRef(r, opts.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{"tag"}
+	switch {
+	case opts.Sign:
+		args = append(args, "--sign", "--message", opts.Message)
+	case opts.Message != "":
+		args = append(args, "--annotate", "--message", opts.Message)
+	}
+	if _, err := runGit(path, append(args, opts.Name, hash.String())...); err != nil {
+		return nil, err
+	}
+
+	ref, err := r.Reference(refName, false)
+	if err != nil {
+		return nil, err
+	}
+	tag := newTag(r, ref)
+	return &tag, nil
+}
+
+// DeleteTag deletes a local tag.
+func DeleteTag(path string, name string) error {
+	r, err := git.PlainOpen(path)
+	if err != nil {
+		return err
+	}
+
+	refName, err := tagRefName(name)
+	if err != nil {
+		return err
+	}
+	if _, err := r.Reference(refName, false); err != nil {
+		return fmt.Errorf("%w: %s", ErrTagNotFound, name)
+	}
+	return r.DeleteTag(name)
+}
+
+// PushTag pushes a single tag to a remote repository. Without opts.Remote
+// it pushes to the remote of the current branch's upstream, or "origin".
+// Being already up to date is not an error.
+func PushTag(path string, name string, opts PushOptions) error {
+	r, err := git.PlainOpen(path)
+	if err != nil {
+		return err
+	}
+
+	refName, err := tagRefName(name)
+	if err != nil {
+		return err
+	}
+	if _, err := r.Reference(refName, false); err != nil {
+		return fmt.Errorf("%w: %s", ErrTagNotFound, name)
+	}
+
+	remote := opts.Remote
+	if remote == "" {
+		remote = git.DefaultRemoteName
+		if head, err := r.Head(); err == nil && head.Name().IsBranch() {
+			if remote, _, err = upstreamFor(r, head.Name().Short()); err != nil {
+				return err
+			}
+		}
+	}
+	if _, err := r.Remote(remote); err != nil {
+		return fmt.Errorf("%w: %s", ErrRemoteNotFound, remote)
+	}
+
+	auth, cred, err := getAuth(path, remote, opts.Auth)
+	if err != nil {
+		return fmt.Errorf("failed to get auth: %w", err)
+	}
+
+	err = r.Push(&git.PushOptions{
+		RemoteName: remote,
+		Auth:       auth,
+		RefSpecs:   []config.RefSpec{config.RefSpec(refName + ":" + refName)},
+	})
+	settleCredential(cred, err)
+	if err == git.NoErrAlreadyUpToDate {
+		return nil
+	}
+	return err
+}
+
+// newTag describes a tag reference, peeling annotated tags to their commit.
+func newTag(r *git.Repository, ref *plumbing.Reference) Tag {
+	t := Tag{Name: ref.Name().Short(), Hash: ref.Hash().String()}
+
+	if obj, err := r.TagObject(ref.Hash()); err == nil {
+		t.Annotated = true
+		t.Signed = obj.PGPSignature != ""
+		t.Message = strings.TrimSpace(obj.Message)
+		t.Tagger = obj.Tagger.Name
+		t.TaggerEmail = obj.Tagger.Email
+		t.Date = obj.Tagger.When
+		t.Hash = obj.Target.String()
+		if c, err := obj.Commit(); err == nil {
+			t.Hash = c.Hash.String()
+			t.Summary = commitSummary(c.Message)
+		}
+		return t
+	}
+
+	if c, err := r.CommitObject(ref.Hash()); err == nil {
+		t.Summary = commitSummary(c.Message)
+		t.Date = c.Committer.When
+	}
+	return t
+}
+
+// tagRefName validates a short tag name and returns its full reference name.
+func tagRefName(name string) (plumbing.ReferenceName, error) {
+	refName := plumbing.NewTagReferenceName(name)
+	if name == "" || strings.HasPrefix(name, "-") || refName.Validate() != nil {
+		return "", fmt.Errorf("%w: %q", ErrInvalidTagName, name)
+	}
+	return refName, nil
+}
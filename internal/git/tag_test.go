@@ -0,0 +1,101 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func TestTags(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	defer os.RemoveAll(repoPath)
+	setIdentity(t, repoPath)
+
+	first := commitFile(t, repoPath, "a.txt", "a", "First release", "alice")
+	second := commitFile(t, repoPath, "b.txt", "b", "Second release", "alice")
+
+	light, err := CreateTag(repoPath, TagOptions{Name: "v1.0", Target: first})
+	if err != nil {
+		t.Fatalf("CreateTag failed: %v", err)
+	}
+	if light.Annotated || light.Hash != first || light.Summary != "First release" {
+		t.Errorf("Unexpected lightweight tag: %+v", light)
+	}
+
+	annotated, err := CreateTag(repoPath, TagOptions{Name: "v2.0", Message: "Release 2.0"})
+	if err != nil {
+		t.Fatalf("CreateTag failed: %v", err)
+	}
+	if !annotated.Annotated || annotated.Signed || annotated.Hash != second ||
+		annotated.Message != "Release 2.0" || annotated.Tagger != "Test" {
+		t.Errorf("Unexpected annotated tag: %+v", annotated)
+	}
+
+	tags, err := ListTags(repoPath)
+	if err != nil {
+		t.Fatalf("ListTags failed: %v", err)
+	}
+	if len(tags) != 2 || tags[0].Name != "v1.0" || tags[1].Name != "v2.0" || !tags[1].Annotated {
+		t.Errorf("Unexpected tags: %+v", tags)
+	}
+
+	if _, err := CreateTag(repoPath, TagOptions{Name: "v1.0"}); !errors.Is(err, ErrTagExists) {
+		t.Errorf("Expected ErrTagExists, got %v", err)
+	}
+	if _, err := CreateTag(repoPath, TagOptions{Name: "bad..name"}); !errors.Is(err, ErrInvalidTagName) {
+		t.Errorf("Expected ErrInvalidTagName, got %v", err)
+	}
+	if _, err := CreateTag(repoPath, TagOptions{Name: "v3.0", Target: "missing"}); !errors.Is(err, ErrRefNotFound) {
+		t.Errorf("Expected ErrRefNotFound, got %v", err)
+	}
+
+	if err := DeleteTag(repoPath, "v1.0"); err != nil {
+		t.Fatalf("DeleteTag failed: %v", err)
+	}
+	if err := DeleteTag(repoPath, "v1.0"); !errors.Is(err, ErrTagNotFound) {
+		t.Errorf("Expected ErrTagNotFound, got %v", err)
+	}
+}
+
+func TestPushTags(t *testing.T) {
+	local, remote := setupTestRepoWithRemote(t)
+	defer os.RemoveAll(local)
+	defer os.RemoveAll(remote)
+	setIdentity(t, local)
+
+	if _, err := CreateTag(local, TagOptions{Name: "v1.0", Message: "Release"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CreateTag(local, TagOptions{Name: "v1.1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := PushTag(local, "v1.0", PushOptions{}); err != nil {
+		t.Fatalf("PushTag failed: %v", err)
+	}
+	rRemote, _ := git.PlainOpen(remote)
+	if _, err := rRemote.Reference(plumbing.NewTagReferenceName("v1.0"), false); err != nil {
+		t.Errorf("Expected v1.0 on remote: %v", err)
+	}
+	if _, err := rRemote.Reference(plumbing.NewTagReferenceName("v1.1"), false); err == nil {
+		t.Error("Expected v1.1 not to be pushed yet")
+	}
+
+	if err := PushTag(local, "v9.9", PushOptions{}); !errors.Is(err, ErrTagNotFound) {
+		t.Errorf("Expected ErrTagNotFound, got %v", err)
+	}
+	if err := PushTag(local, "v1.0", PushOptions{Remote: "upstream"}); !errors.Is(err, ErrRemoteNotFound) {
+		t.Errorf("Expected ErrRemoteNotFound, got %v", err)
+	}
+
+	// A branch push can carry all tags
+	if err := Push(local, PushOptions{Tags: true}); err != nil {
+		t.Fatalf("Push with tags failed: %v", err)
+	}
+	if _, err := rRemote.Reference(plumbing.NewTagReferenceName("v1.1"), false); err != nil {
+		t.Errorf("Expected v1.1 on remote after pushing tags: %v", err)
+	}
+}
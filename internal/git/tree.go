@@ -0,0 +1,295 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+const (
+	// MaxBlobSize is the largest file GetBlob returns.
+	MaxBlobSize = 10 << 20
+	// maxLastCommitWalk bounds the number of commits searched for the last
+	// commits of tree entries.
+	maxLastCommitWalk = 10000
+)
+
+// Tree entry types reported in TreeEntry.Type.
+const (
+	EntryBlob      = "blob"
+	EntryTree      = "tree"
+	EntrySubmodule = "commit"
+)
+
+var (
+	// ErrPathNotFound is returned when a path does not exist at a revision.
+	ErrPathNotFound = errors.New("path not found")
+	// ErrNotDirectory is returned when a tree is requested for a file.
+	ErrNotDirectory = errors.New("not a directory")
+	// ErrNotFile is returned when a blob is requested for a directory.
+	ErrNotFile = errors.New("not a file")
+	// ErrBlobTooLarge is returned for files larger than MaxBlobSize.
+	ErrBlobTooLarge = errors.New("file too large")
+)
+
+// TreeEntry is a file, directory or submodule within a tree.
+type TreeEntry struct {
+	Name       string      `json:"name"`
+	Path       string      `json:"path"`
+	Mode       string      `json:"mode"` // Octal, e.g. "100644"
+	Type       string      `json:"type"`
+	Hash       string      `json:"hash"`
+	Size       int64       `json:"size,omitempty"` // Blobs only
+	LastCommit *CommitInfo `json:"last_commit,omitempty"`
+}
+
+// Tree is the listing of a directory at a revision.
+type Tree struct {
+	Commit  string      `json:"commit"`
+	Path    string      `json:"path"`
+	Entries []TreeEntry `json:"entries"`
+}
+
+// Blob is the content of a file at a revision.
+type Blob struct {
+	Commit  string `json:"commit"`
+	Path    string `json:"path"`
+	Mode    string `json:"mode"`
+	Hash    string `json:"hash"`
+	Size    int64  `json:"size"`
+	Binary  bool   `json:"binary"`
+	Content []byte `json:"-"`
+}
+
+// GetTree lists the directory at dir (the root when empty) as of the given
+// branch, tag or revision (HEAD when empty). Directories come first. With
+// lastCommits each entry carries the last commit that changed it, unless the
+// entry is older than the commits searched; finding them walks history, so
+// it is only done on request.
+func GetTree(path string, ref string, dir string, lastCommits bool) (*Tree, error) {
+	r, err := git.PlainOpen(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := resolveCommit(r, ref)
+	if err != nil {
+		return nil, err
+	}
+	dir = strings.Trim(dir, "/")
+
+	root, err := c.Tree()
+	if err != nil {
+		return nil, err
+	}
+	tree := root
+	if dir != "" {
+		entry, err := root.FindEntry(dir)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrPathNotFound, dir)
+		}
+		if entry.Mode != filemode.Dir {
+			return nil, fmt.Errorf("%w: %s", ErrNotDirectory, dir)
+		}
+		if tree, err = root.Tree(dir); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &Tree{Commit: c.Hash.String(), Path: dir, Entries: []TreeEntry{}}
+	for _, e := range tree.Entries {
+		entry := TreeEntry{
+			Name: e.Name,
+			Path: strings.TrimPrefix(dir+"/"+e.Name, "/"),
+			Mode: fmt.Sprintf("%06o", uint32(e.Mode)),
+			Hash: e.Hash.String(),
+		}
+		switch e.Mode {
+		case filemode.Dir:
+			entry.Type = EntryTree
+		case filemode.Submodule:
+			entry.Type = EntrySubmodule
+		default:
+			entry.Type = EntryBlob
+			if entry.Size, err = r.Storer.EncodedObjectSize(e.Hash); err != nil {
+				return nil, err
+			}
+		}
+		result.Entries = append(result.Entries, entry)
+	}
+	sort.SliceStable(result.Entries, func(i, j int) bool {
+		a, b := result.Entries[i], result.Entries[j]
+		if (a.Type == EntryTree) != (b.Type == EntryTree) {
+			return a.Type == EntryTree
+		}
+		return a.Name < b.Name
+	})
+
+	if lastCommits {
+		if err := setLastCommits(c, dir, result.Entries); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// setLastCommits finds the commit that last changed each entry of dir by
+// walking history newest first: an entry was changed by the first commit
+// whose version of it differs from the versions in all of its parents.
+func setLastCommits(head *object.Commit, dir string, entries []TreeEntry) error {
+	pending := make(map[string]*TreeEntry, len(entries))
+	for i := range entries {
+		pending[entries[i].Name] = &entries[i]
+	}
+
+	cache := make(map[plumbing.Hash]map[string]plumbing.Hash)
+	dirEntries := func(c *object.Commit) (map[string]plumbing.Hash, error) {
+		if m, ok := cache[c.Hash]; ok {
+			return m, nil
+		}
+		m := make(map[string]plumbing.Hash)
+		tree, err := c.Tree()
+		if err != nil {
+			return nil, err
+		}
+		if dir != "" {
+			if tree, err = tree.Tree(dir); err != nil {
+				// The directory does not exist in this commit
+				cache[c.Hash] = m
+				return m, nil
+			}
+		}
+		for _, e := range tree.Entries {
+			m[e.Name] = e.Hash
+		}
+		cache[c.Hash] = m
+		return m, nil
+	}
+
+	walked := 0
+	iter := object.NewCommitIterCTime(head, nil, nil)
+	defer iter.Close()
+	err := iter.ForEach(func(c *object.Commit) error {
+		if len(pending) == 0 || walked >= maxLastCommitWalk {
+			return storer.ErrStop
+		}
+		walked++
+
+		current, err := dirEntries(c)
+		if err != nil {
+			return err
+		}
+		var parents []map[string]plumbing.Hash
+		err = c.Parents().ForEach(func(p *object.Commit) error {
+			m, err := dirEntries(p)
+			parents = append(parents, m)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		for name, entry := range pending {
+			hash, ok := current[name]
+			if !ok {
+				continue
+			}
+			changed := true
+			for _, p := range parents {
+				if p[name] == hash {
+					changed = false
+					break
+				}
+			}
+			if changed {
+				info := newCommitInfo(c)
+				entry.LastCommit = &info
+				delete(pending, name)
+			}
+		}
+		return nil
+	})
+	return err
+}
+
+// GetBlob returns the file at file as of the given branch, tag or revision
+// (HEAD when empty). Files larger than MaxBlobSize are refused.
+func GetBlob(path string, ref string, file string) (*Blob, error) {
+	r, err := git.PlainOpen(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := resolveCommit(r, ref)
+	if err != nil {
+		return nil, err
+	}
+	file = strings.Trim(file, "/")
+
+	entry, err := findFile(c, file)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := r.BlobObject(entry.Hash)
+	if err != nil {
+		return nil, err
+	}
+	if obj.Size > MaxBlobSize {
+		return nil, fmt.Errorf("%w: %s is %d bytes, the limit is %d", ErrBlobTooLarge, file, obj.Size, MaxBlobSize)
+	}
+
+	reader, err := obj.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Blob{
+		Commit:  c.Hash.String(),
+		Path:    file,
+		Mode:    fmt.Sprintf("%06o", uint32(entry.Mode)),
+		Hash:    entry.Hash.String(),
+		Size:    obj.Size,
+		Binary:  isBinary(content),
+		Content: content,
+	}, nil
+}
+
+// findFile returns the tree entry of a file in a commit.
+func findFile(c *object.Commit, file string) (*object.TreeEntry, error) {
+	root, err := c.Tree()
+	if err != nil {
+		return nil, err
+	}
+	entry, err := root.FindEntry(file)
+	if err != nil || file == "" {
+		return nil, fmt.Errorf("%w: %s", ErrPathNotFound, file)
+	}
+	if entry.Mode == filemode.Dir || entry.Mode == filemode.Submodule {
+		return nil, fmt.Errorf("%w: %s", ErrNotFile, file)
+	}
+	return entry, nil
+}
+
+// resolveCommit resolves a branch, tag or revision (HEAD when empty) to
+// its commit.
+func resolveCommit(r *git.Repository, ref string) (*object.Commit, error) {
+	hash, err := resolveRef(r, ref)
+	if err != nil {
+		return nil, err
+	}
+	return r.CommitObject(hash)
+}
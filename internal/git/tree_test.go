@@ -0,0 +1,108 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGetTree(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	defer os.RemoveAll(repoPath)
+
+	first := commitFile(t, repoPath, "src/main.go", "package main\n", "Add main", "alice")
+	commitFile(t, repoPath, "src/util.go", "package main\n", "Add util", "bob")
+	last := commitFile(t, repoPath, "README.md", "Hello again", "Update readme", "carol")
+
+	tree, err := GetTree(repoPath, "", "", true)
+	if err != nil {
+		t.Fatalf("GetTree failed: %v", err)
+	}
+	if tree.Commit != last || len(tree.Entries) != 2 {
+		t.Fatalf("Unexpected tree: %+v", tree)
+	}
+	src, readme := tree.Entries[0], tree.Entries[1]
+	if src.Name != "src" || src.Type != EntryTree || src.Mode != "040000" {
+		t.Errorf("Expected src directory first, got %+v", src)
+	}
+	if src.LastCommit == nil || src.LastCommit.Summary != "Add util" {
+		t.Errorf("Expected src to be last changed by 'Add util', got %+v", src.LastCommit)
+	}
+	if readme.Type != EntryBlob || readme.Mode != "100644" || readme.Size != int64(len("Hello again")) {
+		t.Errorf("Unexpected README entry: %+v", readme)
+	}
+	if readme.LastCommit == nil || readme.LastCommit.Hash != last {
+		t.Errorf("Expected README to be last changed by the latest commit, got %+v", readme.LastCommit)
+	}
+
+	// A subdirectory at an older revision
+	tree, err = GetTree(repoPath, first, "src/", true)
+	if err != nil {
+		t.Fatalf("GetTree failed: %v", err)
+	}
+	if tree.Path != "src" || len(tree.Entries) != 1 || tree.Entries[0].Path != "src/main.go" {
+		t.Errorf("Expected only src/main.go at the first commit, got %+v", tree.Entries)
+	}
+	if tree.Entries[0].LastCommit == nil || tree.Entries[0].LastCommit.Hash != first {
+		t.Errorf("Unexpected last commit: %+v", tree.Entries[0].LastCommit)
+	}
+
+	// Last commits are only looked up on request
+	if tree, _ = GetTree(repoPath, "", "", false); tree.Entries[0].LastCommit != nil {
+		t.Errorf("Expected no last commits, got %+v", tree.Entries[0].LastCommit)
+	}
+
+	if _, err := GetTree(repoPath, "", "missing", false); !errors.Is(err, ErrPathNotFound) {
+		t.Errorf("Expected ErrPathNotFound, got %v", err)
+	}
+	if _, err := GetTree(repoPath, "", "README.md", false); !errors.Is(err, ErrNotDirectory) {
+		t.Errorf("Expected ErrNotDirectory, got %v", err)
+	}
+	if _, err := GetTree(repoPath, "no-such-branch", "", false); !errors.Is(err, ErrRefNotFound) {
+		t.Errorf("Expected ErrRefNotFound, got %v", err)
+	}
+}
+
+func TestGetBlob(t *testing.T) {
+	repoPath := setupTestRepo(t)
+	defer os.RemoveAll(repoPath)
+
+	old := commitFile(t, repoPath, "notes.txt", "version 1\n", "Add notes", "alice")
+	commitFile(t, repoPath, "notes.txt", "version 2\n", "Update notes", "alice")
+	commitFile(t, repoPath, "data.bin", "\x89PNG\r\n\x1a\n\x00\x00", "Add binary", "alice")
+
+	// The working tree does not matter
+	os.WriteFile(filepath.Join(repoPath, "notes.txt"), []byte("uncommitted\n"), 0644)
+
+	blob, err := GetBlob(repoPath, "", "notes.txt")
+	if err != nil {
+		t.Fatalf("GetBlob failed: %v", err)
+	}
+	if string(blob.Content) != "version 2\n" || blob.Binary || blob.Size != 10 {
+		t.Errorf("Unexpected blob: %+v", blob)
+	}
+
+	blob, _ = GetBlob(repoPath, old, "notes.txt")
+	if string(blob.Content) != "version 1\n" {
+		t.Errorf("Expected the old version, got %q", blob.Content)
+	}
+
+	if blob, _ = GetBlob(repoPath, "", "data.bin"); !blob.Binary {
+		t.Error("Expected data.bin to be binary")
+	}
+
+	commitFile(t, repoPath, "big.txt", strings.Repeat("x", MaxBlobSize+1), "Add big file", "alice")
+	if _, err := GetBlob(repoPath, "", "big.txt"); !errors.Is(err, ErrBlobTooLarge) {
+		t.Errorf("Expected ErrBlobTooLarge, got %v", err)
+	}
+
+	commitFile(t, repoPath, "dir/file.txt", "x", "Add dir", "alice")
+	if _, err := GetBlob(repoPath, "", "dir"); !errors.Is(err, ErrNotFile) {
+		t.Errorf("Expected ErrNotFile, got %v", err)
+	}
+	if _, err := GetBlob(repoPath, "", "missing.txt"); !errors.Is(err, ErrPathNotFound) {
+		t.Errorf("Expected ErrPathNotFound, got %v", err)
+	}
+}
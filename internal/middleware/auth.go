@@ -49,6 +49,13 @@ func GenerateToken(userID, username string) (string, error) {
 func JWTMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
+		if authHeader == "" && r.Header.Get("Accept") == "text/event-stream" {
+			// EventSource cannot set headers, so event streams may pass the
+			// token as a query parameter instead
+			if token := r.URL.Query().Get("access_token"); token != "" {
+				authHeader = "Bearer " + token
+			}
+		}
 		if authHeader == "" {
 			http.Error(w, "Authorization header required", http.StatusUnauthorized)
 			return
@@ -0,0 +1,306 @@
+// Package watcher watches the working trees and git directories of tracked
+// repositories and reports, debounced, which repositories have changed.
+package watcher
+
+import (
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+)
+
+// DefaultDelay is the debounce delay used by the server: a burst of file
+// events in one repository results in a single change notification once
+// the repository has been quiet for this long.
+const DefaultDelay = 300 * time.Millisecond
+
+// repo is a watched repository.
+type repo struct {
+	path    string
+	ignore  gitignore.Matcher
+	pending *time.Timer // Debounce timer, nil when no change is pending
+	gen     int         // Incremented for each new timer to detect stale ones
+}
+
+// Watcher watches repositories for changes. Changes are delivered to every
+// subscriber as repository IDs.
+type Watcher struct {
+	fsw   *fsnotify.Watcher
+	delay time.Duration
+
+	mu          sync.Mutex
+	repos       map[string]*repo
+	subscribers map[chan string]struct{}
+	closed      bool
+}
+
+// New creates a Watcher that reports a repository once no further events
+// have arrived for delay.
+func New(delay time.Duration) (*Watcher, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Watcher{
+		fsw:         fsw,
+		delay:       delay,
+		repos:       make(map[string]*repo),
+		subscribers: make(map[chan string]struct{}),
+	}
+	go w.run()
+	return w, nil
+}
+
+// Watch starts watching the repository at path under the given ID. The
+// working tree is watched except for ignored directories, and inside .git
+// only HEAD, the index and refs, which change on checkout, staging and
+// commits.
+func (w *Watcher) Watch(id string, path string) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if old, ok := w.repos[id]; ok {
+		w.removeLocked(old)
+	}
+
+	rp := &repo{path: path, ignore: loadIgnore(path)}
+	w.repos[id] = rp
+	return w.addTree(rp, path)
+}
+
+// Unwatch stops watching the repository with the given ID.
+func (w *Watcher) Unwatch(id string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if rp, ok := w.repos[id]; ok {
+		w.removeLocked(rp)
+		delete(w.repos, id)
+	}
+}
+
+// Subscribe returns a channel receiving the ID of each changed repository,
+// and a function that cancels the subscription. Notifications are dropped
+// for subscribers that do not keep up. The channel is closed when the
+// watcher is closed.
+func (w *Watcher) Subscribe() (<-chan string, func()) {
+	ch := make(chan string, 16)
+
+	w.mu.Lock()
+	if w.closed {
+		close(ch)
+	} else {
+		w.subscribers[ch] = struct{}{}
+	}
+	w.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			w.mu.Lock()
+			delete(w.subscribers, ch)
+			w.mu.Unlock()
+		})
+	}
+}
+
+// Close stops watching all repositories and closes all subscriptions.
+func (w *Watcher) Close() error {
+	w.mu.Lock()
+	w.closed = true
+	for _, rp := range w.repos {
+		if rp.pending != nil {
+			rp.pending.Stop()
+		}
+	}
+	for ch := range w.subscribers {
+		close(ch)
+		delete(w.subscribers, ch)
+	}
+	w.mu.Unlock()
+
+	return w.fsw.Close()
+}
+
+// run dispatches file system events until the watcher is closed.
+func (w *Watcher) run() {
+	for {
+		select {
+		case event, ok := <-w.fsw.Events:
+			if !ok {
+				return
+			}
+			w.handleEvent(event)
+		case err, ok := <-w.fsw.Errors:
+			if !ok {
+				return
+			}
+			slog.Warn("File watcher error", "error", err)
+		}
+	}
+}
+
+// handleEvent maps a file event to its repository and schedules a
+// notification for it.
+func (w *Watcher) handleEvent(event fsnotify.Event) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	id, rp, rel := w.lookup(event.Name)
+	if rp == nil {
+		return
+	}
+
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	if parts[0] != ".git" {
+		if filepath.Base(rel) == ".gitignore" {
+			rp.ignore = loadIgnore(rp.path)
+		}
+
+		// Start watching directories created in the working tree
+		if event.Has(fsnotify.Create) {
+			if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+				w.addTree(rp, event.Name)
+			}
+		}
+
+		if rp.ignore.Match(parts, false) {
+			return
+		}
+	} else if event.Has(fsnotify.Create) && len(parts) > 2 && parts[1] == "refs" {
+		// New ref namespaces, e.g. refs/remotes/upstream after adding a remote
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			w.addTree(rp, event.Name)
+		}
+	}
+
+	w.schedule(id, rp)
+}
+
+// schedule (re)starts the debounce timer of a repository. The caller must
+// hold w.mu.
+func (w *Watcher) schedule(id string, rp *repo) {
+	if rp.pending != nil && rp.pending.Stop() {
+		rp.pending.Reset(w.delay)
+		return
+	}
+
+	// The previous timer, if any, has fired and its notify may be waiting
+	// for w.mu; a new generation makes that call a no-op
+	rp.gen++
+	gen := rp.gen
+	rp.pending = time.AfterFunc(w.delay, func() { w.notify(id, rp, gen) })
+}
+
+// notify delivers a debounced change of a repository to all subscribers,
+// unless the timer of generation gen has been superseded.
+func (w *Watcher) notify(id string, rp *repo, gen int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if rp.gen != gen {
+		return
+	}
+	rp.pending = nil
+	if w.closed || w.repos[id] != rp {
+		return
+	}
+
+	for ch := range w.subscribers {
+		select {
+		case ch <- id:
+		default:
+		}
+	}
+}
+
+// lookup returns the repository containing the given file and the file's
+// path relative to the repository root. The caller must hold w.mu.
+func (w *Watcher) lookup(name string) (string, *repo, string) {
+	var (
+		bestID   string
+		bestRepo *repo
+		bestRel  string
+	)
+	for id, rp := range w.repos {
+		rel, err := filepath.Rel(rp.path, name)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		// Prefer the innermost repository when repositories are nested
+		if bestRepo == nil || len(rp.path) > len(bestRepo.path) {
+			bestID, bestRepo, bestRel = id, rp, rel
+		}
+	}
+	return bestID, bestRepo, bestRel
+}
+
+// addTree adds watches for dir and the directories below it that belong to
+// the repository. The caller must hold w.mu.
+func (w *Watcher) addTree(rp *repo, dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			return nil // Vanished or unreadable below the root, skip it
+		}
+		if !d.IsDir() {
+			return nil
+		}
+
+		rel, _ := filepath.Rel(rp.path, path)
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		if rel != "." {
+			if parts[0] == ".git" {
+				// Inside .git only refs matter; HEAD and index live in .git itself
+				if len(parts) > 1 && parts[1] != "refs" {
+					return filepath.SkipDir
+				}
+			} else if d.Name() == ".git" || rp.ignore.Match(parts, true) {
+				// Nested repositories are watched on their own, if tracked
+				return filepath.SkipDir
+			}
+		}
+
+		if err := w.fsw.Add(path); err != nil {
+			slog.Warn("Failed to watch directory", "path", path, "error", err)
+		}
+		return nil
+	})
+}
+
+// removeLocked removes all watches of a repository. The caller must hold w.mu.
+func (w *Watcher) removeLocked(rp *repo) {
+	if rp.pending != nil {
+		rp.pending.Stop()
+		rp.pending = nil
+	}
+	for _, path := range w.fsw.WatchList() {
+		if path == rp.path || strings.HasPrefix(path, rp.path+string(filepath.Separator)) {
+			w.fsw.Remove(path)
+		}
+	}
+}
+
+// loadIgnore reads the .gitignore patterns of a working tree.
+func loadIgnore(path string) gitignore.Matcher {
+	patterns, err := gitignore.ReadPatterns(osfs.New(path), nil)
+	if err != nil {
+		slog.Warn("Failed to read ignore patterns", "path", path, "error", err)
+	}
+	return gitignore.NewMatcher(patterns)
+}
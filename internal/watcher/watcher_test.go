@@ -0,0 +1,124 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-git/go-git/v5"
+)
+
+// expectChange waits for a notification for the given repository.
+func expectChange(t *testing.T, changes <-chan string, id string) {
+	t.Helper()
+	select {
+	case got := <-changes:
+		if got != id {
+			t.Errorf("Expected change for %s, got %s", id, got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("No change reported for %s", id)
+	}
+}
+
+// expectQuiet checks that no notification arrives for a while.
+func expectQuiet(t *testing.T, changes <-chan string) {
+	t.Helper()
+	select {
+	case got := <-changes:
+		t.Errorf("Unexpected change for %s", got)
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	repoPath := t.TempDir()
+	if _, err := git.PlainInit(repoPath, false); err != nil {
+		t.Fatalf("Failed to init repo: %v", err)
+	}
+	os.WriteFile(filepath.Join(repoPath, ".gitignore"), []byte("build/\n"), 0644)
+	os.Mkdir(filepath.Join(repoPath, "build"), 0755)
+
+	w, err := New(50 * time.Millisecond)
+	if err != nil {
+		t.Fatalf("Failed to create watcher: %v", err)
+	}
+	defer w.Close()
+
+	if err := w.Watch("1", repoPath); err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	changes, cancel := w.Subscribe()
+	defer cancel()
+
+	// A burst of writes is reported once
+	for i := 0; i < 5; i++ {
+		os.WriteFile(filepath.Join(repoPath, "file.txt"), []byte{byte(i)}, 0644)
+	}
+	expectChange(t, changes, "1")
+	expectQuiet(t, changes)
+
+	// Ignored directories are not watched
+	os.WriteFile(filepath.Join(repoPath, "build", "out.bin"), []byte("x"), 0644)
+	expectQuiet(t, changes)
+
+	// New directories are picked up
+	os.Mkdir(filepath.Join(repoPath, "src"), 0755)
+	expectChange(t, changes, "1")
+	os.WriteFile(filepath.Join(repoPath, "src", "main.go"), []byte("package main"), 0644)
+	expectChange(t, changes, "1")
+
+	// Changes inside .git, e.g. a new branch, are reported
+	os.WriteFile(filepath.Join(repoPath, ".git", "refs", "heads", "feature"), []byte("0000000000000000000000000000000000000000\n"), 0644)
+	expectChange(t, changes, "1")
+
+	w.Unwatch("1")
+	os.WriteFile(filepath.Join(repoPath, "file.txt"), []byte("after"), 0644)
+	expectQuiet(t, changes)
+}
+
+func TestWatcherDebounceBoundary(t *testing.T) {
+	repoPath := t.TempDir()
+	if _, err := git.PlainInit(repoPath, false); err != nil {
+		t.Fatalf("Failed to init repo: %v", err)
+	}
+
+	w, err := New(50 * time.Millisecond)
+	if err != nil {
+		t.Fatalf("Failed to create watcher: %v", err)
+	}
+	defer w.Close()
+
+	if err := w.Watch("1", repoPath); err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	changes, cancel := w.Subscribe()
+	defer cancel()
+
+	// Let the timer fire while its notification waits for the lock, then
+	// report another event before the notification gets to run
+	w.handleEvent(fsnotify.Event{Name: filepath.Join(repoPath, "file.txt"), Op: fsnotify.Write})
+	w.mu.Lock()
+	time.Sleep(150 * time.Millisecond)
+	w.schedule("1", w.repos["1"])
+	w.mu.Unlock()
+
+	expectChange(t, changes, "1")
+	expectQuiet(t, changes)
+}
+
+func TestWatcherClose(t *testing.T) {
+	w, err := New(50 * time.Millisecond)
+	if err != nil {
+		t.Fatalf("Failed to create watcher: %v", err)
+	}
+	changes, cancel := w.Subscribe()
+	defer cancel()
+
+	w.Close()
+	if _, ok := <-changes; ok {
+		t.Error("Expected subscription to be closed")
+	}
+}
@@ -8,12 +8,42 @@ type User struct {
 	ID           string `json:"id"`
 	Username     string `json:"username"`
 	PasswordHash string `json:"password_hash"`
+	Name         string `json:"name,omitempty"`  // Commit author name, the username when empty
+	Email        string `json:"email,omitempty"` // Commit author email
 }
 
 type Repository struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	Path      string    `json:"path"`
-	CreatedAt time.Time `json:"created_at"`
-	UserID    string    `json:"user_id"`
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	Path         string    `json:"path"`
+	CreatedAt    time.Time `json:"created_at"`
+	UserID       string    `json:"user_id"`
+	CredentialID string    `json:"credential_id,omitempty"` // Vault credential used for remote operations
+}
+
+// RepoToken is an HTTPS credential stored for a single repository. The
+// token is encrypted at rest by the config store.
+type RepoToken struct {
+	RepoID   string `json:"repo_id"`
+	Username string `json:"username"`
+	Token    string `json:"token"`
+}
+
+// Credential types stored in the credential vault.
+const (
+	CredentialSSH   = "ssh"
+	CredentialHTTPS = "https"
+)
+
+// Credential is an entry in the server-side credential vault. Secret holds
+// an HTTPS token or a PEM-encoded SSH private key; Secret and Passphrase are
+// encrypted at rest by the config store.
+type Credential struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Type       string    `json:"type"`
+	Username   string    `json:"username,omitempty"`
+	Secret     string    `json:"secret"`
+	Passphrase string    `json:"passphrase,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
 }
@@ -146,6 +146,21 @@ func setupGitRepo(t *testing.T) (string, string, func()) {
 		}
 	}
 
+	// Commits by users without an email use the server's git identity
+	cfg, err := r.Config()
+	if err != nil {
+		os.RemoveAll(remoteDir)
+		os.RemoveAll(localDir)
+		t.Fatal(err)
+	}
+	cfg.User.Name = "Server"
+	cfg.User.Email = "server@test.com"
+	if err := r.SetConfig(cfg); err != nil {
+		os.RemoveAll(remoteDir)
+		os.RemoveAll(localDir)
+		t.Fatal(err)
+	}
+
 	w, _ := r.Worktree()
 	os.WriteFile(filepath.Join(localDir, "README.md"), []byte("# Test"), 0644)
 	w.Add("README.md")